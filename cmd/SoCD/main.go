@@ -1,38 +1,86 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"log/slog"
 	"os"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/lmittmann/tint"
 	"github.com/matjam/sword/internal/assets"
+	"github.com/matjam/sword/internal/camera"
 	"github.com/matjam/sword/internal/ecs"
-	"github.com/matjam/sword/internal/ecs/component"
 	"github.com/matjam/sword/internal/ecs/entity"
 	"github.com/matjam/sword/internal/ecs/system"
+	"github.com/matjam/sword/internal/savegame"
 	"github.com/matjam/sword/internal/tilemap"
 	"github.com/matjam/sword/internal/tilemap/text"
+	"github.com/matjam/sword/internal/tileset"
 	"github.com/mattn/go-colorable"
 
 	_ "image/png"
 	_ "net/http/pprof"
 )
 
+// screenWidth and screenHeight are the size of the game's screen, in pixels.
+const (
+	screenWidth  = 1280
+	screenHeight = 768
+)
+
+var (
+	recordPath = flag.String("record", "", "save every action the player takes to this file")
+	replayPath = flag.String("replay", "", "replay the actions saved in this file by -record")
+	savePath   = flag.String("save", "sword.sav", "where F5 saves the game")
+	loadPath   = flag.String("load", "", "load a game saved with F5")
+	debugPaths = flag.Bool("debug-paths", false, "draw paths and distance maps over the map, F4 toggles them")
+)
+
 type Game struct {
 	tm         *tilemap.Grid
 	tmRenderer tilemap.Renderer
 	world      *ecs.World
+	player     ecs.EntityID
+
+	// commands is every action the player has taken, to be saved if
+	// -record was given.
+	commands *system.CommandLog
+
+	// renderers are the ways we can draw the tilemap, F2 switches between
+	// them.
+	renderers []tilemap.Renderer
+	renderer  int
 }
 
 func (g *Game) Update() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF2) {
+		g.renderer = (g.renderer + 1) % len(g.renderers)
+		g.tmRenderer = g.renderers[g.renderer]
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyF5) {
+		g.save()
+	}
+
 	g.world.Update(time.Second / 60)
 
 	return nil
 }
 
+// save saves the game to the -save file.
+func (g *Game) save() {
+	err := savegame.Save(*savePath, &savegame.Game{World: g.world, Tilemap: g.tm, Player: g.player})
+	if err != nil {
+		slog.Error("failed to save the game", "path", *savePath, "err", err)
+		return
+	}
+
+	slog.Info("saved the game", "path", *savePath)
+}
+
 func (g *Game) Draw(screen *ebiten.Image) {
 	// g.tmRenderer.Draw(screen, 28, 26,
 	// 	tilemap.Rectangle{
@@ -48,8 +96,8 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	screen.DrawImage(ebiten.NewImageFromImage(assets.GetImage("square")), op)
 }
 
-func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	return 1280, 768
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return screenWidth, screenHeight
 }
 
 func ConfigureLogger() {
@@ -63,26 +111,94 @@ func ConfigureLogger() {
 
 }
 
-func ConfigureWorld() *ecs.World {
+// ConfigureWorld creates the world and its systems, and the player. If replay
+// is not nil, the world is seeded from it and the player's actions are read
+// from it instead of the keyboard. If load is not empty, the world, the player
+// and the tilemap are loaded from that save game instead. The returned log
+// records every action the player takes.
+func ConfigureWorld(tm *tilemap.Grid, replay *system.CommandLog, load string) (*ecs.World, ecs.EntityID, *system.CommandLog) {
 	world := ecs.NewWorld()
 
-	inputSystem := &system.Input{}
+	seed := time.Now().UnixNano()
+	if replay != nil {
+		seed = replay.Seed
+	}
+	world.SetSeed(seed)
+	slog.Info("seeded world", "seed", seed)
+	world.SetResource(tm)
+
+	commands := &system.CommandLog{Seed: seed}
+	inputSystem := &system.Input{Tilemap: tm, Record: commands, Replay: replay}
+	injurySystem := &system.Injury{}
+	scentSystem := &system.Scent{Tilemap: tm}
+	encumbranceSystem := &system.Encumbrance{}
+	experienceSystem := &system.Experience{}
+	sightSystem := &system.Sight{Tilemap: tm}
 
-	world.AddSystem(inputSystem)
-	world.AddSystem(&system.Movement{})
-	world.AddSystem(&system.Renderer{GridSize: assets.GetFontSize("square")})
+	cellWidth, cellHeight := assets.GetFontCellSize("square")
+	cam := camera.New(cellWidth, cellHeight, 1)
+	world.SetResource(cam)
+	cameraSystem := &system.Camera{ScreenWidth: screenWidth, ScreenHeight: screenHeight}
 
-	player := world.AddEntity(&entity.Player{})
-	playerLocation := ecs.GetComponent[*component.Location](world, player)
-	playerLocation.X = 7
-	playerLocation.Y = 7
+	err := world.AddSystems(
+		inputSystem,
+		encumbranceSystem,
+		&system.Movement{},
+		injurySystem,
+		&system.Corpses{},
+		experienceSystem,
+		&system.Reaper{},
+		&system.Lighting{Tilemap: tm},
+		scentSystem,
+		sightSystem,
+		cameraSystem,
+		&system.Cooldowns{},
+		&system.Animation{},
+		&system.Renderer{Camera: cam},
+		&system.HealthBars{Camera: cam},
+	)
+	if err != nil {
+		log.Panic("failed to add systems: ", err)
+	}
+
+	var player ecs.EntityID
+	if load != "" {
+		world.RegisterEntities(entity.All()...)
+
+		game := &savegame.Game{World: world, Tilemap: tm}
+		if err := savegame.Load(load, game); err != nil {
+			log.Panic("failed to load the game: ", err)
+		}
+		player = game.Player
+		commands.Seed = world.Seed()
+		slog.Info("loaded the game", "path", load, "turn", world.Turn())
+	} else {
+		player = world.AddEntity(&entity.Player{})
+		world.MoveEntity(player, 7, 7)
+	}
 
 	inputSystem.Player = player
+	injurySystem.Player = player
+	scentSystem.Player = player
+	encumbranceSystem.Player = player
+	experienceSystem.Player = player
+	sightSystem.Player = player
+	cameraSystem.Player = player
+	if err := world.AddSystem(&system.DebugOverlay{Player: player}); err != nil {
+		log.Panic("failed to add the debug overlay: ", err)
+	}
+
+	if *debugPaths {
+		if err := world.AddSystem(&system.PathOverlay{Camera: cam, Enabled: true}); err != nil {
+			log.Panic("failed to add the path overlay: ", err)
+		}
+	}
 
-	return world
+	return world, player, commands
 }
 
 func main() {
+	flag.Parse()
 	ConfigureLogger()
 
 	// go func() {
@@ -100,24 +216,45 @@ func main() {
 	slog.Info("creating tilemap ...")
 	game.tm = tilemap.NewGrid(600, 400)
 
+	var replay *system.CommandLog
+	if *replayPath != "" {
+		var err error
+		replay, err = system.LoadCommandLog(*replayPath)
+		if err != nil {
+			log.Panic("failed to load replay: ", err)
+		}
+	}
+
 	slog.Info("creating world ...")
-	game.world = ConfigureWorld()
+	game.world, game.player, game.commands = ConfigureWorld(game.tm, replay, *loadPath)
 
-	// lets clear out a room
+	// lets clear out a room, unless we've loaded the map
 
-	for y := 5; y < 35; y++ {
-		for x := 5; x < 60; x++ {
-			game.tm.SetTile(x, y, &tilemap.Tile{
-				Type: tilemap.TileTypeFloor,
-			})
+	if *loadPath == "" {
+		for y := 5; y < 35; y++ {
+			for x := 5; x < 60; x++ {
+				game.tm.SetTile(x, y, &tilemap.Tile{
+					Type: tilemap.TileTypeFloor,
+				})
+			}
 		}
 	}
 
-	game.tmRenderer = text.NewRenderer(game.tm, "square")
+	game.renderers = []tilemap.Renderer{
+		text.NewCachedRenderer(game.tm, "square", nil),
+		tileset.NewRenderer(game.tm, assets.GetTileset("rogue_environment"), 2),
+	}
+	game.tmRenderer = game.renderers[0]
 
-	ebiten.SetWindowSize(1280, 768)
+	ebiten.SetWindowSize(screenWidth, screenHeight)
 	ebiten.SetWindowTitle("Hello, World!")
 	if err := ebiten.RunGame(game); err != nil {
 		log.Panic("failed to run game: ", err)
 	}
+
+	if *recordPath != "" {
+		if err := game.commands.Save(*recordPath); err != nil {
+			slog.Error("failed to save the recording", "path", *recordPath, "err", err)
+		}
+	}
 }
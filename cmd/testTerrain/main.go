@@ -1,16 +1,19 @@
 package main
 
 import (
-	"image"
+	"fmt"
 	"log"
 	"log/slog"
 	"os"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/lmittmann/tint"
 	"github.com/matjam/sword/internal/assets"
+	"github.com/matjam/sword/internal/camera"
+	"github.com/matjam/sword/internal/grid"
 	"github.com/matjam/sword/internal/mapgen"
 	"github.com/matjam/sword/internal/terrain"
 	"github.com/matjam/sword/internal/tileset"
@@ -29,11 +32,13 @@ type Game struct {
 	Terrain *terrain.Terrain
 	Tileset *tileset.Tileset
 
+	// themes is the theme of every tile, while the themes are turned on
+	themes *grid.Grid[terrain.ThemeID]
+
+	Camera *camera.Camera
+
 	mouseX int
 	mouseY int
-
-	viewportX int
-	viewportY int
 }
 
 func ConfigureLogger() {
@@ -52,11 +57,27 @@ func main() {
 	assets.StartAssetManager("assets.json")
 
 	game := &Game{
-		mg: mapgen.NewMapGenerator(1920/16-1, 1080/16, time.Now().UnixNano(), 1000),
+		mg: mapgen.NewMapGenerator(1920/16-1, 1080/16, time.Now().UnixNano(), mapgen.AutoRoomAttempts),
 	}
 
 	game.Tileset = assets.GetTileset("rogue_environment")
 
+	// the tileset only has the one look, so the second theme just swaps the
+	// room and corridor floors around to show where the wings are.
+	game.Tileset.AddTheme(1, tileset.Theme{
+		Fixtures: map[string]string{
+			"floor_dots":      "floor_checker_1",
+			"floor_checker_1": "floor_dots",
+		},
+	})
+
+	tileWidth, tileHeight := game.Tileset.TileSize()
+	game.Camera = camera.New(tileWidth, tileHeight, 3)
+
+	game.mg.OnPhaseChange = func(oldPhase, newPhase mapgen.GenerationPhase) {
+		slog.Info("map generation phase changed", "from", oldPhase, "to", newPhase)
+	}
+
 	ebiten.SetWindowSize(1920, 1080)
 	ebiten.SetWindowTitle("display the map!")
 	if err := ebiten.RunGame(game); err != nil {
@@ -77,13 +98,9 @@ func (g *Game) Update() error {
 	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
 		x, y := ebiten.CursorPosition()
 
-		// get the distance between the mouse and the last mouse position
-		dx := g.mouseX - x
-		dy := g.mouseY - y
-
-		// scroll the viewport by the distance
-		g.viewportX += dx
-		g.viewportY += dy
+		// drag the map along with the mouse
+		g.Camera.Move(g.mouseX-x, g.mouseY-y)
+		g.mouseX, g.mouseY = x, y
 	}
 
 	g.pressedKeys = inpututil.AppendPressedKeys(g.pressedKeys[:0])
@@ -102,6 +119,22 @@ func (g *Game) Update() error {
 		if inpututil.IsKeyJustPressed(ebiten.KeyF1) {
 			g.renderDebug = !g.renderDebug
 		}
+	case ebiten.KeyF2:
+		if inpututil.IsKeyJustPressed(ebiten.KeyF2) {
+			g.Tileset.Shadows = !g.Tileset.Shadows
+		}
+	case ebiten.KeyF3:
+		if inpututil.IsKeyJustPressed(ebiten.KeyF3) && g.mapgenDone {
+			if g.themes == nil {
+				g.themes = g.mg.AssignThemes(2)
+			} else {
+				g.themes = nil
+			}
+		}
+	case ebiten.KeyF4:
+		if inpututil.IsKeyJustPressed(ebiten.KeyF4) {
+			g.Tileset.MergeFloors = !g.Tileset.MergeFloors
+		}
 	}
 
 	return nil
@@ -111,7 +144,17 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	if g.renderDebug {
 		g.mg.DrawDebug(screen)
 	} else {
-		g.Tileset.Render(g.mg.Terrain(), screen, g.viewportX, g.viewportY, image.Rectangle{Min: image.Point{X: 0, Y: 0}, Max: image.Point{X: 640, Y: 360}}, 3)
+		bounds := screen.Bounds()
+		x, y := g.Camera.TileToScreen(0, 0)
+		viewport := g.Camera.Viewport(bounds.Dx(), bounds.Dy())
+		if g.themes != nil {
+			g.Tileset.RenderThemed(g.mg.Terrain(), g.themes, nil, screen, x, y, viewport, float64(g.Camera.Scale))
+		} else {
+			g.Tileset.Render(g.mg.Terrain(), nil, screen, x, y, viewport, float64(g.Camera.Scale))
+		}
+
+		tx, ty := g.Camera.ScreenToTile(ebiten.CursorPosition())
+		ebitenutil.DebugPrint(screen, fmt.Sprintf("tile: %d,%d", tx, ty))
 	}
 }
 
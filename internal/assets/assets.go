@@ -16,6 +16,7 @@ import (
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
 	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
 )
 
 const dpi = 72
@@ -71,9 +72,18 @@ func StartAssetManager(configPath string) {
 	for name, tilesetConfig := range assetConfig.Tilesets {
 		atlas := m.loadImage(tilesetConfig.Path, name)
 
+		tileWidth, tileHeight := tilesetConfig.TileWidth, tilesetConfig.TileHeight
+		if tileWidth == 0 {
+			tileWidth = tilesetConfig.TileSize
+		}
+		if tileHeight == 0 {
+			tileHeight = tilesetConfig.TileSize
+		}
+
 		m.tileSet[name] = tileset.Load(name,
 			atlas,
-			tilesetConfig.TileSize,
+			tileWidth,
+			tileHeight,
 			tilesetConfig.Columns,
 			tilesetConfig.Rows,
 			tilesetConfig.Autotiles,
@@ -204,6 +214,24 @@ func (am *AssetManager) GetFontSize(name string) int {
 	return am.fontSizes[name]
 }
 
+// GetFontCellSize returns the size of a single character cell in the named
+// font. Fonts are expected to be monospaced, so the width is the advance of
+// any glyph, and the height is the font's line height. These are often not
+// the same, so don't assume the cells are square.
+func (am *AssetManager) GetFontCellSize(name string) (width, height int) {
+	face := am.fonts[name]
+	if face == nil {
+		return 0, 0
+	}
+
+	advance, ok := face.GlyphAdvance('M')
+	if !ok {
+		advance = fixed.I(am.fontSizes[name])
+	}
+
+	return advance.Ceil(), face.Metrics().Height.Ceil()
+}
+
 func GetFont(name string) font.Face {
 	return globalAssetManager.GetFont(name)
 }
@@ -212,6 +240,10 @@ func GetFontSize(name string) int {
 	return globalAssetManager.GetFontSize(name)
 }
 
+func GetFontCellSize(name string) (width, height int) {
+	return globalAssetManager.GetFontCellSize(name)
+}
+
 func GetImage(name string) image.Image {
 	return globalAssetManager.GetImage(name)
 }
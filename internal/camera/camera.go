@@ -0,0 +1,117 @@
+package camera
+
+// package camera converts between screen pixels and map tiles, for a view of
+// the map that can be scrolled around and scaled up.
+
+import "image"
+
+// Mode is what moves the camera.
+type Mode int
+
+const (
+	// ModeFollow keeps the camera centered on the player. It is the default.
+	ModeFollow Mode = iota
+
+	// ModeFree leaves the camera where it is, so it can be panned around the
+	// map without moving the player.
+	ModeFree
+)
+
+// Camera is a view onto a map of tiles. X and Y are how far the view has been
+// scrolled, in screen pixels, so that the top left corner of tile 0,0 is
+// drawn at -X,-Y. Each tile is drawn TileWidth x TileHeight pixels in size,
+// multiplied by Scale.
+type Camera struct {
+	X int
+	Y int
+
+	// TileWidth and TileHeight are the size of a tile in pixels, before it
+	// is scaled. A size of 0 or less is treated as 1, so that a zero Camera
+	// can still convert positions rather than dividing by zero.
+	TileWidth  int
+	TileHeight int
+
+	// Scale is how many screen pixels each pixel of a tile takes up. A Scale
+	// of 0 is treated as 1.
+	Scale int
+
+	// Mode is whether the camera follows the player or is moved freely. The
+	// camera doesn't move itself; this is for whatever does, such as the
+	// Camera system.
+	Mode Mode
+}
+
+// New returns a camera at the top left of the map, for tiles of the given
+// size drawn at the given scale.
+func New(tileWidth, tileHeight, scale int) *Camera {
+	return &Camera{
+		TileWidth:  tileWidth,
+		TileHeight: tileHeight,
+		Scale:      scale,
+	}
+}
+
+// Move scrolls the camera by the given number of screen pixels.
+func (c *Camera) Move(dx, dy int) {
+	c.X += dx
+	c.Y += dy
+}
+
+// CenterOn scrolls the camera so that the given tile is in the middle of a
+// screen of the given size.
+func (c *Camera) CenterOn(tx, ty, screenWidth, screenHeight int) {
+	width, height := c.CellSize()
+	c.X = tx*width + width/2 - screenWidth/2
+	c.Y = ty*height + height/2 - screenHeight/2
+}
+
+// Clamp scrolls the camera back inside a map of the given size in tiles, so
+// that a screen of the given size doesn't show anything past its edges. If
+// the map is smaller than the screen, it is drawn in the top left corner.
+func (c *Camera) Clamp(mapWidth, mapHeight, screenWidth, screenHeight int) {
+	width, height := c.CellSize()
+	c.X = max(min(c.X, mapWidth*width-screenWidth), 0)
+	c.Y = max(min(c.Y, mapHeight*height-screenHeight), 0)
+}
+
+// CellSize returns the size of a tile on the screen, in pixels, once it has
+// been scaled.
+func (c *Camera) CellSize() (width, height int) {
+	scale := max(c.Scale, 1)
+	return max(c.TileWidth, 1) * scale, max(c.TileHeight, 1) * scale
+}
+
+// ScreenToTile returns the tile under the given screen position, such as the
+// position of the mouse. Positions above or to the left of the map give
+// negative tiles, so check them against the size of the map before use.
+func (c *Camera) ScreenToTile(px, py int) (tx, ty int) {
+	width, height := c.CellSize()
+	return floorDiv(px+c.X, width), floorDiv(py+c.Y, height)
+}
+
+// TileToScreen returns the screen position of the top left corner of the
+// given tile.
+func (c *Camera) TileToScreen(tx, ty int) (px, py int) {
+	width, height := c.CellSize()
+	return tx*width - c.X, ty*height - c.Y
+}
+
+// Viewport returns the rectangle of tiles, in tile coordinates, that can be
+// seen on a screen of the given size. Tiles that are only partly on the
+// screen are included.
+func (c *Camera) Viewport(screenWidth, screenHeight int) image.Rectangle {
+	minX, minY := c.ScreenToTile(0, 0)
+	maxX, maxY := c.ScreenToTile(screenWidth-1, screenHeight-1)
+	return image.Rect(minX, minY, maxX+1, maxY+1)
+}
+
+// floorDiv divides a by b, rounding towards negative infinity rather than
+// towards zero, so that the pixels just left of a tile aren't counted as
+// being in it.
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,107 @@
+package camera_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/matjam/sword/internal/camera"
+)
+
+func TestScreenToTile(t *testing.T) {
+	tests := []struct {
+		name     string
+		camera   camera.Camera
+		px, py   int
+		tx, ty   int
+		expected [2]int
+	}{
+		{"origin", camera.Camera{TileWidth: 16, TileHeight: 16, Scale: 1}, 0, 0, 0, 0, [2]int{0, 0}},
+		{"inside a tile", camera.Camera{TileWidth: 16, TileHeight: 16, Scale: 1}, 35, 17, 2, 1, [2]int{32, 16}},
+		{"scaled", camera.Camera{TileWidth: 16, TileHeight: 16, Scale: 3}, 100, 47, 2, 0, [2]int{96, 0}},
+		{"no scale", camera.Camera{TileWidth: 8, TileHeight: 12}, 9, 25, 1, 2, [2]int{8, 24}},
+		{"non-square", camera.Camera{TileWidth: 8, TileHeight: 16, Scale: 2}, 40, 40, 2, 1, [2]int{32, 32}},
+		{"scrolled", camera.Camera{X: 20, Y: 5, TileWidth: 16, TileHeight: 16, Scale: 1}, 0, 0, 1, 0, [2]int{-4, -5}},
+		{"scrolled and scaled", camera.Camera{X: 50, Y: 100, TileWidth: 16, TileHeight: 16, Scale: 2}, 20, 10, 2, 3, [2]int{14, -4}},
+		{"left of the map", camera.Camera{X: -20, TileWidth: 16, TileHeight: 16, Scale: 1}, 10, 0, -1, 0, [2]int{4, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, ty := tt.camera.ScreenToTile(tt.px, tt.py)
+			if tx != tt.tx || ty != tt.ty {
+				t.Errorf("expected ScreenToTile(%d, %d) to be %d,%d, got %d,%d", tt.px, tt.py, tt.tx, tt.ty, tx, ty)
+			}
+
+			px, py := tt.camera.TileToScreen(tx, ty)
+			if px != tt.expected[0] || py != tt.expected[1] {
+				t.Errorf("expected TileToScreen(%d, %d) to be %v, got %d,%d", tx, ty, tt.expected, px, py)
+			}
+
+			// the corner of the tile is always on the tile
+			if cx, cy := tt.camera.ScreenToTile(px, py); cx != tx || cy != ty {
+				t.Errorf("expected the corner of tile %d,%d to be on it, got %d,%d", tx, ty, cx, cy)
+			}
+		})
+	}
+}
+
+func TestViewport(t *testing.T) {
+	c := camera.New(16, 16, 2)
+	c.Move(40, 0)
+
+	expected := image.Rect(1, 0, 12, 5)
+	if viewport := c.Viewport(320, 160); viewport != expected {
+		t.Errorf("expected viewport %v, got %v", expected, viewport)
+	}
+}
+
+func TestZeroCamera(t *testing.T) {
+	// a camera without a tile size treats each tile as a single pixel
+	// rather than dividing by zero
+	var c camera.Camera
+	c.Move(3, 4)
+
+	if x, y := c.ScreenToTile(10, 10); x != 13 || y != 14 {
+		t.Errorf("expected tile 13,14, got %d,%d", x, y)
+	}
+	if x, y := c.TileToScreen(13, 14); x != 10 || y != 10 {
+		t.Errorf("expected the tile at 10,10, got %d,%d", x, y)
+	}
+	if width, height := c.CellSize(); width != 1 || height != 1 {
+		t.Errorf("expected a 1x1 cell, got %dx%d", width, height)
+	}
+}
+func TestCenterOnAndClamp(t *testing.T) {
+	c := camera.New(16, 16, 2)
+
+	// tile 20,10 is 32x32 on the screen, so its middle is at 656,336
+	c.CenterOn(20, 10, 640, 480)
+	if c.X != 336 || c.Y != 96 {
+		t.Errorf("expected the camera at 336,96, got %d,%d", c.X, c.Y)
+	}
+	if tx, ty := c.ScreenToTile(320, 240); tx != 20 || ty != 10 {
+		t.Errorf("expected tile 20,10 in the middle of the screen, got %d,%d", tx, ty)
+	}
+
+	tests := []struct {
+		name       string
+		x, y       int
+		mapW, mapH int
+		expected   [2]int
+	}{
+		{"inside", 336, 96, 40, 30, [2]int{336, 96}},
+		{"past the top left", -50, -10, 40, 30, [2]int{0, 0}},
+		{"past the bottom right", 1000, 1000, 40, 30, [2]int{640, 480}},
+		{"map smaller than the screen", 100, 100, 10, 10, [2]int{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.X, c.Y = tt.x, tt.y
+			c.Clamp(tt.mapW, tt.mapH, 640, 480)
+			if c.X != tt.expected[0] || c.Y != tt.expected[1] {
+				t.Errorf("expected %v, got %d,%d", tt.expected, c.X, c.Y)
+			}
+		})
+	}
+}
@@ -20,12 +20,16 @@ type FontConfig struct {
 }
 
 type TilesetConfig struct {
-	Path      string            `json:"path"`
-	TileSize  int               `json:"tile_size"`
-	Columns   int               `json:"columns"`
-	Rows      int               `json:"rows"`
-	Autotiles [][2]int          `json:"autotiles"`
-	Fixtures  map[string][2]int `json:"fixtures"`
+	Path     string `json:"path"`
+	TileSize int    `json:"tile_size"`
+	// TileWidth and TileHeight are used for tilesets with tiles that aren't
+	// square. If they're zero, TileSize is used instead.
+	TileWidth  int               `json:"tile_width"`
+	TileHeight int               `json:"tile_height"`
+	Columns    int               `json:"columns"`
+	Rows       int               `json:"rows"`
+	Autotiles  [][2]int          `json:"autotiles"`
+	Fixtures   map[string][2]int `json:"fixtures"`
 }
 
 type Config struct {
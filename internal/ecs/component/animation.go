@@ -0,0 +1,118 @@
+package component
+
+import (
+	"image"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/matjam/sword/internal/ecs"
+)
+
+// DefaultFrameDuration is how long each frame of an Animation is shown if
+// FrameDuration isn't set.
+const DefaultFrameDuration = 100 * time.Millisecond
+
+// Animation is a sequence of frames that the Renderer draws in place of the
+// sprite in the entity's Render component, such as for an attack or an idle
+// animation. The entity still needs a Render, which decides the layer it is
+// drawn on. The Animation system moves the animation on every update.
+//
+// The frames are images on the GPU, so they aren't saved; an entity loaded
+// from a save game gets the frames its New gives it, and starts the animation
+// from the beginning.
+type Animation struct {
+	// Frames are the images to draw, in order. SliceFrames cuts them out of
+	// a sprite sheet.
+	Frames []*ebiten.Image `json:"-"`
+
+	// FrameDuration is how long each frame is shown for. If it is zero,
+	// DefaultFrameDuration is used.
+	FrameDuration time.Duration
+
+	// Loop makes the animation start again from the first frame once it
+	// has finished. Otherwise it stays on the last frame.
+	Loop bool
+
+	current  int
+	elapsed  time.Duration
+	finished bool
+}
+
+func (*Animation) ComponentName() ecs.ComponentName {
+	return "animation"
+}
+
+// Frame returns the frame to draw now, or nil if there are no frames.
+func (a *Animation) Frame() *ebiten.Image {
+	if len(a.Frames) == 0 {
+		return nil
+	}
+	return a.Frames[min(a.current, len(a.Frames)-1)]
+}
+
+// Advance moves the animation on by delta. It returns true on the call where
+// an animation that doesn't loop finishes, which is once its last frame has
+// been shown for a whole FrameDuration, and false every other time.
+func (a *Animation) Advance(delta time.Duration) bool {
+	if a.finished || len(a.Frames) == 0 {
+		return false
+	}
+
+	frameDuration := a.FrameDuration
+	if frameDuration <= 0 {
+		frameDuration = DefaultFrameDuration
+	}
+
+	a.elapsed += delta
+	for a.elapsed >= frameDuration {
+		a.elapsed -= frameDuration
+
+		if a.current < len(a.Frames)-1 {
+			a.current++
+			continue
+		}
+
+		if a.Loop {
+			a.current = 0
+			continue
+		}
+
+		a.elapsed = 0
+		a.finished = true
+		return true
+	}
+
+	return false
+}
+
+// Finished returns true if the animation doesn't loop and has finished.
+func (a *Animation) Finished() bool {
+	return a.finished
+}
+
+// Reset starts the animation again from the first frame.
+func (a *Animation) Reset() {
+	a.current = 0
+	a.elapsed = 0
+	a.finished = false
+}
+
+// SliceFrames cuts a sprite sheet up into frames of the given size, row by
+// row from the top left, for use as the Frames of an Animation. Any space
+// left over on the right or at the bottom of the sheet that isn't big enough
+// for a whole frame is ignored. The frames are sub-images, so they share the
+// sheet's pixels rather than copying them.
+func SliceFrames(sheet *ebiten.Image, frameWidth, frameHeight int) []*ebiten.Image {
+	if frameWidth <= 0 || frameHeight <= 0 {
+		return nil
+	}
+
+	bounds := sheet.Bounds()
+	frames := make([]*ebiten.Image, 0)
+	for y := bounds.Min.Y; y+frameHeight <= bounds.Max.Y; y += frameHeight {
+		for x := bounds.Min.X; x+frameWidth <= bounds.Max.X; x += frameWidth {
+			frames = append(frames, sheet.SubImage(image.Rect(x, y, x+frameWidth, y+frameHeight)).(*ebiten.Image))
+		}
+	}
+	return frames
+}
@@ -0,0 +1,85 @@
+package component_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/matjam/sword/internal/ecs/component"
+)
+
+func TestAnimation(t *testing.T) {
+	frames := component.SliceFrames(ebiten.NewImage(30, 10), 10, 10)
+
+	tests := []struct {
+		name     string
+		loop     bool
+		advance  []time.Duration
+		expected []int
+		finished []bool
+	}{
+		{
+			name:     "once",
+			advance:  []time.Duration{5, 5, 10, 10, 10},
+			expected: []int{0, 1, 2, 2, 2},
+			finished: []bool{false, false, false, true, false},
+		},
+		{
+			name:     "loop",
+			loop:     true,
+			advance:  []time.Duration{10, 10, 10, 25},
+			expected: []int{1, 2, 0, 2},
+			finished: []bool{false, false, false, false},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			animation := &component.Animation{Frames: frames, FrameDuration: 10, Loop: test.loop}
+
+			if animation.Frame() != frames[0] {
+				t.Fatal("expected the animation to start on the first frame")
+			}
+
+			for i, delta := range test.advance {
+				if finished := animation.Advance(delta); finished != test.finished[i] {
+					t.Errorf("step %d: expected finished to be %v, got %v", i, test.finished[i], finished)
+				}
+				if animation.Frame() != frames[test.expected[i]] {
+					t.Errorf("step %d: expected frame %d", i, test.expected[i])
+				}
+			}
+
+			if animation.Finished() == test.loop {
+				t.Errorf("expected Finished to be %v", !test.loop)
+			}
+
+			animation.Reset()
+			if animation.Frame() != frames[0] || animation.Finished() {
+				t.Error("expected Reset to start the animation again")
+			}
+		})
+	}
+
+	if (&component.Animation{}).Advance(time.Second) {
+		t.Error("expected an animation without frames to never finish")
+	}
+}
+
+func TestSliceFrames(t *testing.T) {
+	// the leftover 5 pixels on each side aren't enough for a frame
+	sheet := ebiten.NewImage(35, 25)
+	frames := component.SliceFrames(sheet, 10, 10)
+
+	if len(frames) != 6 {
+		t.Fatalf("expected 6 frames, got %d", len(frames))
+	}
+
+	if bounds := frames[4].Bounds(); bounds.Min.X != 10 || bounds.Min.Y != 10 || bounds.Dx() != 10 || bounds.Dy() != 10 {
+		t.Errorf("expected the fifth frame to be the middle of the second row, got %v", bounds)
+	}
+
+	if frames := component.SliceFrames(sheet, 0, 10); frames != nil {
+		t.Errorf("expected no frames for a frame size of zero, got %d", len(frames))
+	}
+}
@@ -0,0 +1,13 @@
+package component
+
+import "github.com/matjam/sword/internal/ecs"
+
+// Blocker marks an entity that takes up the whole tile it is standing on, such
+// as the player or a mob. Nothing else can move onto that tile; trying to
+// bumps into the entity instead. Entities without it, such as corpses, can be
+// walked over.
+type Blocker struct{}
+
+func (*Blocker) ComponentName() ecs.ComponentName {
+	return "blocker"
+}
@@ -0,0 +1,46 @@
+package component
+
+import "github.com/matjam/sword/internal/ecs"
+
+// Cooldowns tracks how many turns an entity has to wait before it can use each
+// of its abilities again, such as a dash or a spell. Remaining is keyed by the
+// name of the ability; abilities that aren't in it are ready to use. The
+// Cooldowns system counts them all down by one every turn.
+type Cooldowns struct {
+	Remaining map[string]int
+}
+
+func (*Cooldowns) ComponentName() ecs.ComponentName {
+	return "cooldowns"
+}
+
+// Ready returns true if the named ability can be used.
+func (c *Cooldowns) Ready(name string) bool {
+	return c.Remaining[name] <= 0
+}
+
+// Trigger starts the named ability's cooldown, so that it won't be ready for
+// the given number of turns.
+func (c *Cooldowns) Trigger(name string, turns int) {
+	if turns <= 0 {
+		delete(c.Remaining, name)
+		return
+	}
+
+	if c.Remaining == nil {
+		c.Remaining = make(map[string]int)
+	}
+	c.Remaining[name] = turns
+}
+
+// Tick counts every cooldown down by one turn, and forgets the abilities that
+// are ready again.
+func (c *Cooldowns) Tick() {
+	for name, turns := range c.Remaining {
+		if turns <= 1 {
+			delete(c.Remaining, name)
+		} else {
+			c.Remaining[name] = turns - 1
+		}
+	}
+}
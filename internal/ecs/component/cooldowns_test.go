@@ -0,0 +1,36 @@
+package component_test
+
+import (
+	"testing"
+
+	"github.com/matjam/sword/internal/ecs/component"
+)
+
+func TestCooldowns(t *testing.T) {
+	var cooldowns component.Cooldowns
+
+	if !cooldowns.Ready("dash") {
+		t.Error("expected an ability that was never used to be ready")
+	}
+
+	cooldowns.Trigger("dash", 2)
+	if cooldowns.Ready("dash") {
+		t.Error("expected the dash not to be ready once it was used")
+	}
+	if !cooldowns.Ready("fireball") {
+		t.Error("expected the other abilities to still be ready")
+	}
+
+	cooldowns.Tick()
+	if cooldowns.Ready("dash") {
+		t.Error("expected the dash not to be ready after one turn")
+	}
+
+	cooldowns.Tick()
+	if !cooldowns.Ready("dash") {
+		t.Error("expected the dash to be ready after two turns")
+	}
+	if len(cooldowns.Remaining) != 0 {
+		t.Errorf("expected the finished cooldown to be forgotten, got %v", cooldowns.Remaining)
+	}
+}
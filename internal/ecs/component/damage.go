@@ -5,6 +5,10 @@ import "github.com/matjam/sword/internal/ecs"
 type DamageRecord struct {
 	Amount int
 	Source string
+
+	// Attacker is the entity that dealt the damage, or zero if it wasn't an
+	// entity, such as a trap.
+	Attacker ecs.EntityID
 }
 
 // Damage records incoming damage and is applied by the injury system.
@@ -22,7 +26,14 @@ func (d *Damage) RecordDamage(amount int, source string) {
 		d.Records = make([]DamageRecord, 0)
 	}
 
-	d.Records = append(d.Records, DamageRecord{amount, source})
+	d.Records = append(d.Records, DamageRecord{Amount: amount, Source: source})
+}
+
+// RecordAttack records damage dealt to the entity by another entity, so that
+// the attacker can be credited if the entity dies.
+func (d *Damage) RecordAttack(amount int, source string, attacker ecs.EntityID) {
+	d.RecordDamage(amount, source)
+	d.Records[len(d.Records)-1].Attacker = attacker
 }
 
 // ClearDamage clears the damage records.
@@ -0,0 +1,22 @@
+package component
+
+import "github.com/matjam/sword/internal/ecs"
+
+// Dead marks an entity that has just died. The Injury system adds it when an
+// entity's health runs out, the systems that care about deaths, such as
+// Corpses and Experience, react to it, and the Reaper removes the entity at
+// the end of the frame. That way every system gets to see the death before
+// the entity is gone.
+type Dead struct {
+	// Cause is the source of the damage that killed the entity, such as
+	// "trap" or the description of whatever hit it.
+	Cause string
+
+	// Killer is the entity that dealt the killing blow, or zero if it wasn't
+	// dealt by an entity, such as a trap.
+	Killer ecs.EntityID
+}
+
+func (*Dead) ComponentName() ecs.ComponentName {
+	return "dead"
+}
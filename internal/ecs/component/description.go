@@ -0,0 +1,15 @@
+package component
+
+import "github.com/matjam/sword/internal/ecs"
+
+// Description is what the player is told when they look at an entity. Short
+// is a few words that fit in a sentence, such as "a goblin", and Long is any
+// more detail, written as whole sentences.
+type Description struct {
+	Short string
+	Long  string
+}
+
+func (*Description) ComponentName() ecs.ComponentName {
+	return "description"
+}
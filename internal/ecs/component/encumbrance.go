@@ -0,0 +1,65 @@
+package component
+
+import "github.com/matjam/sword/internal/ecs"
+
+// DefaultCarryCapacity is how much weight an entity can carry without being
+// burdened, if its Encumbrance doesn't set a Capacity.
+const DefaultCarryCapacity = 100
+
+// EncumbranceLevel is how weighed down an entity is by what it's carrying.
+type EncumbranceLevel int
+
+const (
+	Unburdened EncumbranceLevel = iota
+	Burdened
+	Overloaded
+)
+
+// String returns the name of the level.
+func (l EncumbranceLevel) String() string {
+	switch l {
+	case Unburdened:
+		return "unburdened"
+	case Burdened:
+		return "burdened"
+	case Overloaded:
+		return "overloaded"
+	}
+	return "unknown"
+}
+
+// Encumbrance tracks how weighed down an entity is by the items in its
+// Inventory. An entity carrying more than its Capacity is Burdened, and can
+// only move every other turn. One carrying more than half as much again is
+// Overloaded, and can't move at all until it drops something. The Encumbrance
+// system keeps Level up to date as the inventory changes.
+type Encumbrance struct {
+	// Capacity is how much weight the entity can carry without being
+	// burdened. If it is zero, DefaultCarryCapacity is used.
+	Capacity int
+
+	// Level is how encumbered the entity was the last time its inventory
+	// was weighed.
+	Level EncumbranceLevel
+}
+
+func (*Encumbrance) ComponentName() ecs.ComponentName {
+	return "encumbrance"
+}
+
+// LevelFor returns how encumbered the entity is when carrying the given
+// weight.
+func (e *Encumbrance) LevelFor(weight int) EncumbranceLevel {
+	capacity := e.Capacity
+	if capacity == 0 {
+		capacity = DefaultCarryCapacity
+	}
+
+	switch {
+	case weight*2 > capacity*3:
+		return Overloaded
+	case weight > capacity:
+		return Burdened
+	}
+	return Unburdened
+}
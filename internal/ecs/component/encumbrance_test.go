@@ -0,0 +1,34 @@
+package component_test
+
+import (
+	"testing"
+
+	"github.com/matjam/sword/internal/ecs/component"
+)
+
+func TestEncumbrance_LevelFor(t *testing.T) {
+	tests := []struct {
+		capacity int
+		weight   int
+		expected component.EncumbranceLevel
+	}{
+		{capacity: 10, weight: 0, expected: component.Unburdened},
+		{capacity: 10, weight: 10, expected: component.Unburdened},
+		{capacity: 10, weight: 11, expected: component.Burdened},
+		{capacity: 10, weight: 15, expected: component.Burdened},
+		{capacity: 10, weight: 16, expected: component.Overloaded},
+		// an odd capacity rounds the overloaded threshold down
+		{capacity: 11, weight: 16, expected: component.Burdened},
+		{capacity: 11, weight: 17, expected: component.Overloaded},
+		// no capacity means the default
+		{capacity: 0, weight: component.DefaultCarryCapacity, expected: component.Unburdened},
+		{capacity: 0, weight: component.DefaultCarryCapacity + 1, expected: component.Burdened},
+	}
+
+	for _, test := range tests {
+		encumbrance := &component.Encumbrance{Capacity: test.capacity}
+		if level := encumbrance.LevelFor(test.weight); level != test.expected {
+			t.Errorf("capacity %d, weight %d: expected %v, got %v", test.capacity, test.weight, test.expected, level)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package component
+
+import "github.com/matjam/sword/internal/ecs"
+
+// Experience is the experience an entity has earned by killing things. See
+// the Experience system.
+type Experience struct {
+	Points int
+}
+
+func (*Experience) ComponentName() ecs.ComponentName {
+	return "experience"
+}
+
+// Reward is what killing an entity is worth to whoever kills it.
+type Reward struct {
+	// Experience is the number of experience points the killer earns.
+	Experience int
+}
+
+func (*Reward) ComponentName() ecs.ComponentName {
+	return "reward"
+}
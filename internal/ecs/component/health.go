@@ -1,9 +1,15 @@
 package component
 
-import "github.com/matjam/sword/internal/ecs"
+import (
+	"log/slog"
+
+	"github.com/matjam/sword/internal/ecs"
+)
 
 // Health is the health of an entity.
 type Health struct {
+	// Max is the most health the entity can have. It should be at least 1;
+	// anything less is treated as 1 when healing.
 	Max     int
 	Current int
 }
@@ -12,6 +18,11 @@ func (*Health) ComponentName() ecs.ComponentName {
 	return "health"
 }
 
+// IsDead returns true if the entity has no health left.
+func (h *Health) IsDead() bool {
+	return h.Current <= 0
+}
+
 // Damage deals damage to the entity and returns the current health.
 func (h *Health) Damage(d int) int {
 	h.Current -= d
@@ -21,11 +32,48 @@ func (h *Health) Damage(d int) int {
 	return h.Current
 }
 
-// Heal heals the entity and returns the current health.
+// Ratio returns how healthy the entity is, from 0.0 when it has no health
+// left to 1.0 when it is at full health.
+func (h *Health) Ratio() float64 {
+	if h.Max <= 0 {
+		return 0
+	}
+	return min(max(float64(h.Current)/float64(h.Max), 0), 1)
+}
+
+// Heal heals the entity and returns the current health. Healing never takes
+// the entity past Max, or past 1 if Max hasn't been set properly.
 func (h *Health) Heal(d int) int {
 	h.Current += d
-	if h.Current > h.Max {
-		h.Current = h.Max
+	if h.Current > h.maxHealth() {
+		h.Current = h.maxHealth()
 	}
 	return h.Current
 }
+
+// SetMax changes the entity's Max health, and scales its current health to
+// match, so an entity at half health is still at half health afterwards. A
+// living entity is always left with at least 1 health, and a dead one stays
+// dead. A max of less than 1 is logged and treated as 1.
+func (h *Health) SetMax(n int) {
+	if n <= 0 {
+		slog.Warn("health max must be at least 1", "max", n)
+		n = 1
+	}
+
+	switch {
+	case h.IsDead():
+		h.Current = 0
+	case h.Max > 0:
+		// round to the nearest point of health
+		h.Current = max((h.Current*n+h.Max/2)/h.Max, 1)
+	}
+
+	h.Max = n
+	h.Current = min(h.Current, n)
+}
+
+// maxHealth returns Max, or 1 if Max is less than that.
+func (h *Health) maxHealth() int {
+	return max(h.Max, 1)
+}
@@ -0,0 +1,11 @@
+package component
+
+import "github.com/matjam/sword/internal/ecs"
+
+// HealthBar marks an entity as one that should have a health bar drawn over
+// it when it's hurt. It has no data; entities without it never show a bar.
+type HealthBar struct{}
+
+func (*HealthBar) ComponentName() ecs.ComponentName {
+	return "health_bar"
+}
@@ -0,0 +1,73 @@
+package component_test
+
+import (
+	"testing"
+
+	"github.com/matjam/sword/internal/ecs/component"
+)
+
+func TestHealth_Ratio(t *testing.T) {
+	tests := []struct {
+		name     string
+		health   component.Health
+		expected float64
+	}{
+		{"full", component.Health{Current: 100, Max: 100}, 1},
+		{"half", component.Health{Current: 25, Max: 50}, 0.5},
+		{"dead", component.Health{Current: 0, Max: 100}, 0},
+		{"overhealed", component.Health{Current: 120, Max: 100}, 1},
+		{"no max", component.Health{Current: 10}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ratio := tt.health.Ratio(); ratio != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, ratio)
+			}
+		})
+	}
+}
+
+func TestHealth_DamageAndHeal(t *testing.T) {
+	h := component.Health{Current: 5, Max: 10}
+
+	if h.Damage(8) != 0 || !h.IsDead() {
+		t.Errorf("expected damage past zero to leave 0 health and be dead, got %d", h.Current)
+	}
+	if h.Heal(20) != 10 || h.IsDead() {
+		t.Errorf("expected healing to stop at max, got %d", h.Current)
+	}
+
+	// a misconfigured max is treated as 1
+	h = component.Health{Current: 0, Max: 0}
+	if h.Heal(5) != 1 {
+		t.Errorf("expected healing with no max to stop at 1, got %d", h.Current)
+	}
+}
+
+func TestHealth_SetMax(t *testing.T) {
+	tests := []struct {
+		name            string
+		health          component.Health
+		max             int
+		expectedCurrent int
+		expectedMax     int
+	}{
+		{"grow", component.Health{Current: 5, Max: 10}, 20, 10, 20},
+		{"shrink", component.Health{Current: 10, Max: 10}, 4, 4, 4},
+		{"rounds", component.Health{Current: 2, Max: 3}, 10, 7, 10},
+		{"stays alive", component.Health{Current: 1, Max: 100}, 10, 1, 10},
+		{"stays dead", component.Health{Current: 0, Max: 10}, 20, 0, 20},
+		{"from no max", component.Health{Current: 3}, 10, 3, 10},
+		{"zero", component.Health{Current: 5, Max: 10}, 0, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.health.SetMax(tt.max)
+			if tt.health.Current != tt.expectedCurrent || tt.health.Max != tt.expectedMax {
+				t.Errorf("expected %d/%d, got %d/%d", tt.expectedCurrent, tt.expectedMax, tt.health.Current, tt.health.Max)
+			}
+		})
+	}
+}
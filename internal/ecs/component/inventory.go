@@ -1,12 +1,46 @@
 package component
 
-import "github.com/matjam/sword/internal/ecs"
+import (
+	"sort"
 
+	"github.com/matjam/sword/internal/ecs"
+)
+
+// ItemKind is the broad type of an item, used to group and filter items in
+// the inventory.
+type ItemKind int
+
+const (
+	ItemKindMisc ItemKind = iota
+	ItemKindWeapon
+	ItemKindArmor
+	ItemKindPotion
+	ItemKindFood
+	ItemKindScroll
+)
+
+// Item is a stack of one or more identical items. Items with the same name
+// always stack together in an Inventory.
 type Item struct {
-	Name   string
+	Name string
+	// Kind is the type of the item. Items that don't set it are misc items.
+	Kind ItemKind
+	// Weight is the weight of a single item in the stack.
 	Weight int
+	// Quantity is the number of items in the stack. A Quantity of zero is
+	// treated as a single item; see Size.
+	Quantity int
+}
+
+// Size returns the number of items in the stack, counting a stack with no
+// Quantity set as a single item.
+func (item Item) Size() int {
+	return max(item.Quantity, 1)
 }
 
+// Inventory holds the items an entity is carrying. MaxSize is the number of
+// different stacks it can hold, and MaxCapacity is the total weight it can
+// hold. Either limit is ignored if it is zero.
 type Inventory struct {
 	MaxSize     int
 	MaxCapacity int
@@ -17,3 +51,109 @@ type Inventory struct {
 func (*Inventory) ComponentName() ecs.ComponentName {
 	return "inventory"
 }
+
+// TotalWeight returns the weight of every item in the inventory.
+func (inv *Inventory) TotalWeight() int {
+	total := 0
+	for _, item := range inv.Items {
+		total += item.Weight * item.Size()
+	}
+	return total
+}
+
+// Count returns how many of the named item are in the inventory.
+func (inv *Inventory) Count(name string) int {
+	if i := inv.find(name); i >= 0 {
+		return inv.Items[i].Size()
+	}
+	return 0
+}
+
+// AddItem adds the item to the inventory, stacking it with any items of the
+// same name. It returns false, and leaves the inventory unchanged, if the
+// item would go over MaxCapacity or needs a new stack beyond MaxSize.
+func (inv *Inventory) AddItem(item Item) bool {
+	item.Quantity = item.Size()
+
+	if inv.MaxCapacity > 0 && inv.TotalWeight()+item.Weight*item.Size() > inv.MaxCapacity {
+		return false
+	}
+
+	if i := inv.find(item.Name); i >= 0 {
+		inv.Items[i].Quantity = inv.Items[i].Size() + item.Quantity
+		return true
+	}
+
+	if inv.MaxSize > 0 && len(inv.Items) >= inv.MaxSize {
+		return false
+	}
+
+	inv.Items = append(inv.Items, item)
+	return true
+}
+
+// RemoveItem removes the whole stack of the named item from the inventory and
+// returns it. It returns false if there is no such item.
+func (inv *Inventory) RemoveItem(name string) (Item, bool) {
+	i := inv.find(name)
+	if i < 0 {
+		return Item{}, false
+	}
+
+	item := inv.Items[i]
+	inv.Items = append(inv.Items[:i], inv.Items[i+1:]...)
+	return item, true
+}
+
+// RemoveQuantity splits n of the named item off its stack and returns them as
+// a new stack. The stack is removed from the inventory if it is used up. It
+// returns false, and leaves the inventory unchanged, if there are fewer than n
+// of the item.
+func (inv *Inventory) RemoveQuantity(name string, n int) (Item, bool) {
+	i := inv.find(name)
+	if i < 0 || n <= 0 || inv.Items[i].Size() < n {
+		return Item{}, false
+	}
+
+	if inv.Items[i].Size() == n {
+		return inv.RemoveItem(name)
+	}
+
+	inv.Items[i].Quantity = inv.Items[i].Size() - n
+
+	item := inv.Items[i]
+	item.Quantity = n
+	return item, true
+}
+
+// SortItems sorts the stacks in the inventory using the given less function.
+// The sort is stable, so stacks that compare equal keep the order they were
+// in.
+func (inv *Inventory) SortItems(less func(a, b Item) bool) {
+	sort.SliceStable(inv.Items, func(i, j int) bool {
+		return less(inv.Items[i], inv.Items[j])
+	})
+}
+
+// FilterItems returns the stacks that match the given predicate, in the order
+// they're in the inventory. The returned slice is a copy, so changing it
+// doesn't change the inventory.
+func (inv *Inventory) FilterItems(pred func(Item) bool) []Item {
+	items := make([]Item, 0)
+	for _, item := range inv.Items {
+		if pred(item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// find returns the index of the stack with the given name, or -1.
+func (inv *Inventory) find(name string) int {
+	for i, item := range inv.Items {
+		if item.Name == name {
+			return i
+		}
+	}
+	return -1
+}
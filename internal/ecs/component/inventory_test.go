@@ -0,0 +1,196 @@
+package component_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/matjam/sword/internal/ecs/component"
+)
+
+func TestInventory_AddItemStacks(t *testing.T) {
+	inv := &component.Inventory{}
+
+	for i := 0; i < 20; i++ {
+		if !inv.AddItem(component.Item{Name: "arrow", Weight: 1}) {
+			t.Fatalf("adding arrow %d should succeed", i)
+		}
+	}
+	inv.AddItem(component.Item{Name: "arrow", Weight: 1, Quantity: 5})
+	inv.AddItem(component.Item{Name: "sword", Weight: 10})
+
+	if len(inv.Items) != 2 {
+		t.Fatalf("expected 2 stacks, got %d", len(inv.Items))
+	}
+
+	if inv.Count("arrow") != 25 {
+		t.Errorf("expected 25 arrows, got %d", inv.Count("arrow"))
+	}
+
+	if inv.TotalWeight() != 35 {
+		t.Errorf("expected a total weight of 35, got %d", inv.TotalWeight())
+	}
+}
+
+func TestInventory_AddItemLimits(t *testing.T) {
+	inv := &component.Inventory{MaxSize: 1, MaxCapacity: 10}
+
+	if !inv.AddItem(component.Item{Name: "arrow", Weight: 1, Quantity: 8}) {
+		t.Fatal("adding arrows should succeed")
+	}
+
+	// stacking doesn't need a new slot, but is still limited by weight
+	if !inv.AddItem(component.Item{Name: "arrow", Weight: 1, Quantity: 2}) {
+		t.Error("stacking arrows should succeed")
+	}
+	if inv.AddItem(component.Item{Name: "arrow", Weight: 1}) {
+		t.Error("going over MaxCapacity should fail")
+	}
+
+	inv.RemoveQuantity("arrow", 5)
+	if inv.AddItem(component.Item{Name: "dagger", Weight: 1}) {
+		t.Error("going over MaxSize should fail")
+	}
+
+	if inv.Count("arrow") != 5 || len(inv.Items) != 1 {
+		t.Errorf("failed adds should leave the inventory unchanged, got %+v", inv.Items)
+	}
+}
+
+func TestInventory_RemoveQuantity(t *testing.T) {
+	inv := &component.Inventory{}
+	inv.AddItem(component.Item{Name: "arrow", Weight: 2, Quantity: 20})
+
+	split, ok := inv.RemoveQuantity("arrow", 8)
+	if !ok {
+		t.Fatal("splitting the stack should succeed")
+	}
+
+	if split.Name != "arrow" || split.Weight != 2 || split.Quantity != 8 {
+		t.Errorf("unexpected split stack %+v", split)
+	}
+
+	if inv.Count("arrow") != 12 || inv.TotalWeight() != 24 {
+		t.Errorf("expected 12 arrows weighing 24, got %d weighing %d", inv.Count("arrow"), inv.TotalWeight())
+	}
+
+	if _, ok := inv.RemoveQuantity("arrow", 13); ok {
+		t.Error("removing more than the stack holds should fail")
+	}
+
+	if _, ok := inv.RemoveQuantity("arrow", 12); !ok {
+		t.Fatal("removing the whole stack should succeed")
+	}
+
+	if len(inv.Items) != 0 {
+		t.Errorf("the empty stack should be removed, got %+v", inv.Items)
+	}
+}
+
+func TestInventory_SortItems(t *testing.T) {
+	inv := &component.Inventory{}
+	inv.AddItem(component.Item{Name: "sword", Weight: 10, Kind: component.ItemKindWeapon})
+	inv.AddItem(component.Item{Name: "apple", Weight: 1, Kind: component.ItemKindFood})
+	inv.AddItem(component.Item{Name: "potion", Weight: 1, Kind: component.ItemKindPotion})
+	inv.AddItem(component.Item{Name: "axe", Weight: 12, Kind: component.ItemKindWeapon})
+
+	names := func() []string {
+		names := make([]string, 0, len(inv.Items))
+		for _, item := range inv.Items {
+			names = append(names, item.Name)
+		}
+		return names
+	}
+
+	inv.SortItems(func(a, b component.Item) bool { return a.Name < b.Name })
+	if got := fmt.Sprint(names()); got != "[apple axe potion sword]" {
+		t.Errorf("expected the items sorted by name, got %s", got)
+	}
+
+	// the sort is stable, so the apple stays ahead of the potion
+	inv.SortItems(func(a, b component.Item) bool { return a.Weight < b.Weight })
+	if got := fmt.Sprint(names()); got != "[apple potion sword axe]" {
+		t.Errorf("expected the items sorted by weight, got %s", got)
+	}
+
+	// adding and removing still find the right stacks after sorting
+	inv.AddItem(component.Item{Name: "sword", Weight: 10, Kind: component.ItemKindWeapon})
+	if inv.Count("sword") != 2 || len(inv.Items) != 4 {
+		t.Errorf("expected the sword to stack after sorting, got %+v", inv.Items)
+	}
+
+	if item, ok := inv.RemoveItem("potion"); !ok || item.Name != "potion" {
+		t.Errorf("expected to remove the potion, got %+v", item)
+	}
+	if got := fmt.Sprint(names()); got != "[apple sword axe]" {
+		t.Errorf("expected the potion to be removed, got %s", got)
+	}
+}
+
+func TestInventory_FilterItems(t *testing.T) {
+	isWeapon := func(item component.Item) bool { return item.Kind == component.ItemKindWeapon }
+
+	inv := &component.Inventory{}
+	if items := inv.FilterItems(isWeapon); len(items) != 0 {
+		t.Errorf("expected nothing from an empty inventory, got %+v", items)
+	}
+
+	inv.AddItem(component.Item{Name: "sword", Weight: 10, Kind: component.ItemKindWeapon})
+	inv.AddItem(component.Item{Name: "apple", Weight: 1, Kind: component.ItemKindFood})
+	inv.AddItem(component.Item{Name: "axe", Weight: 12, Kind: component.ItemKindWeapon})
+
+	weapons := inv.FilterItems(isWeapon)
+	if len(weapons) != 2 || weapons[0].Name != "sword" || weapons[1].Name != "axe" {
+		t.Errorf("expected the sword and the axe, got %+v", weapons)
+	}
+
+	// the filtered items are a copy
+	weapons[0].Quantity = 99
+	if inv.Count("sword") != 1 {
+		t.Error("changing the filtered items shouldn't change the inventory")
+	}
+}
+
+func TestInventory_RemoveItem(t *testing.T) {
+	inv := &component.Inventory{}
+	inv.AddItem(component.Item{Name: "arrow", Weight: 1, Quantity: 3})
+	inv.AddItem(component.Item{Name: "sword", Weight: 10})
+
+	item, ok := inv.RemoveItem("arrow")
+	if !ok || item.Quantity != 3 {
+		t.Errorf("expected to remove 3 arrows, got %+v", item)
+	}
+
+	if _, ok := inv.RemoveItem("arrow"); ok {
+		t.Error("removing a missing item should fail")
+	}
+
+	if len(inv.Items) != 1 || inv.Items[0].Name != "sword" {
+		t.Errorf("expected only the sword to remain, got %+v", inv.Items)
+	}
+}
+
+func TestInventory_ZeroQuantity(t *testing.T) {
+	// a stack put straight into Items without a Quantity is a single item,
+	// the same as one added with AddItem
+	inv := &component.Inventory{Items: []component.Item{{Name: "sword", Weight: 10}}}
+
+	if item := (component.Item{Name: "sword"}); item.Size() != 1 {
+		t.Errorf("expected a stack with no Quantity to hold 1 item, got %d", item.Size())
+	}
+	if inv.Count("sword") != 1 || inv.TotalWeight() != 10 {
+		t.Errorf("expected 1 sword weighing 10, got %d weighing %d", inv.Count("sword"), inv.TotalWeight())
+	}
+
+	inv.AddItem(component.Item{Name: "sword", Weight: 10})
+	if inv.Count("sword") != 2 || inv.TotalWeight() != 20 {
+		t.Errorf("expected 2 swords weighing 20, got %d weighing %d", inv.Count("sword"), inv.TotalWeight())
+	}
+
+	inv.Items = append(inv.Items, component.Item{Name: "dagger", Weight: 2})
+	if _, ok := inv.RemoveQuantity("dagger", 2); ok {
+		t.Error("removing 2 daggers from a stack of 1 should fail")
+	}
+	if _, ok := inv.RemoveQuantity("dagger", 1); !ok || inv.Count("dagger") != 0 {
+		t.Errorf("expected removing the dagger to use up its stack, got %+v", inv.Items)
+	}
+}
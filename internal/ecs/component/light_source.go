@@ -0,0 +1,15 @@
+package component
+
+import "github.com/matjam/sword/internal/ecs"
+
+// LightSource makes an entity give off light, such as a torch or a glowing
+// mob. Radius is how far the light reaches, in tiles, and Intensity is how
+// bright it is at the source; it fades out with distance.
+type LightSource struct {
+	Radius    int
+	Intensity uint8
+}
+
+func (*LightSource) ComponentName() ecs.ComponentName {
+	return "light_source"
+}
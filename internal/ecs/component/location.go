@@ -2,7 +2,14 @@ package component
 
 import "github.com/matjam/sword/internal/ecs"
 
+// Ensure that we're implementing the ecs.Positioned interface.
+var _ = ecs.Positioned(&Location{})
+
 // Location is the location of an entity on the Grid.
+//
+// X and Y can be set freely before the Location is added to an entity, but
+// after that the entity must be moved with World.MoveEntity, so that
+// EntitiesAt finds it where it has gone.
 type Location struct {
 	X, Y int
 }
@@ -10,3 +17,20 @@ type Location struct {
 func (*Location) ComponentName() ecs.ComponentName {
 	return "location"
 }
+
+// Position implements ecs.Positioned.
+func (l *Location) Position() (x, y int) {
+	return l.X, l.Y
+}
+
+// SetPosition implements ecs.Positioned.
+func (l *Location) SetPosition(x, y int) {
+	l.X, l.Y = x, y
+}
+
+// EntitiesAt returns the entities that have a Location at the given tile,
+// sorted by EntityID. It looks them up in the world's index, so it is cheap
+// enough to call for every tile that is drawn.
+func EntitiesAt(world *ecs.World, x, y int) []ecs.EntityID {
+	return world.EntitiesAt(x, y)
+}
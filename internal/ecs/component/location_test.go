@@ -0,0 +1,63 @@
+package component_test
+
+import (
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/ecs/entity"
+)
+
+func TestEntitiesAt(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	world := ecs.NewWorld()
+	player := world.AddEntity(&entity.Player{})
+	mob := world.AddEntity(&entity.Mob{})
+
+	// entities are found where they start out
+	if at := component.EntitiesAt(world, 5, 5); !slices.Equal(at, []ecs.EntityID{mob}) {
+		t.Errorf("expected the mob at 5,5, got %v", at)
+	}
+
+	// and where they've moved to, straight away
+	if !world.MoveEntity(player, 5, 5) {
+		t.Fatal("expected the player to move")
+	}
+	if at := component.EntitiesAt(world, 5, 5); !slices.Equal(at, []ecs.EntityID{player, mob}) {
+		t.Errorf("expected the player and the mob at 5,5, got %v", at)
+	}
+	if at := component.EntitiesAt(world, 0, 0); len(at) != 0 {
+		t.Errorf("expected nothing left where the player was, got %v", at)
+	}
+	if location := ecs.GetComponent[*component.Location](world, player); location.X != 5 || location.Y != 5 {
+		t.Errorf("expected the player's location to be 5,5, got %d,%d", location.X, location.Y)
+	}
+	if changed := world.ChangedThisFrame("location"); !slices.Contains(changed, player) {
+		t.Errorf("expected the player's location to be marked as changed, got %v", changed)
+	}
+
+	// replacing the location moves the entity too
+	world.ReplaceComponent(player, &component.Location{X: 1, Y: 2})
+	if at := component.EntitiesAt(world, 1, 2); !slices.Equal(at, []ecs.EntityID{player}) {
+		t.Errorf("expected the player at 1,2, got %v", at)
+	}
+
+	// removed entities and locations aren't found
+	world.RemoveEntity(mob)
+	if at := component.EntitiesAt(world, 5, 5); len(at) != 0 {
+		t.Errorf("expected the removed mob to be gone, got %v", at)
+	}
+
+	world.RemoveComponent(player, &component.Location{})
+	if at := component.EntitiesAt(world, 1, 2); len(at) != 0 {
+		t.Errorf("expected the player to be gone once it has no location, got %v", at)
+	}
+	if world.MoveEntity(player, 3, 3) {
+		t.Error("expected an entity without a location not to move")
+	}
+}
@@ -1,6 +1,7 @@
 package component
 
 import (
+	"encoding/json"
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -9,6 +10,16 @@ import (
 	"github.com/matjam/sword/internal/ecs"
 )
 
+// PlaceholderGlyph and PlaceholderColor are drawn in place of anything that
+// can't be drawn, so that broken entities stand out rather than disappear.
+const PlaceholderGlyph = '?'
+
+var PlaceholderColor = color.RGBA{R: 255, G: 0, B: 255, A: 255}
+
+// LayerFloor is the Layer for things lying on the floor, such as corpses, so
+// that anything standing on them is drawn on top.
+const LayerFloor = -1
+
 type Render struct {
 	// Glyph is the rune to draw for text based rendering.
 	Glyph rune
@@ -16,19 +27,103 @@ type Render struct {
 	Color color.Color
 	// Sprite is the sprite to draw for sprite based rendering.
 	Sprite *ebiten.Image
+	// Layer is the order entities are drawn in: lower layers are drawn
+	// first, underneath higher ones. Creatures use the default of zero.
+	Layer int
 }
 
 func (*Render) ComponentName() ecs.ComponentName {
 	return "render"
 }
 
-// Draw draws the entity to the screen. x & y are grid coordinates.
-func (d *Render) Draw(screen *ebiten.Image, x, y, gridSize int) {
+// renderJSON is how a Render is saved. Color is an interface, so it is saved
+// as RGBA, and the sprite isn't saved at all.
+type renderJSON struct {
+	Glyph rune
+	Color *color.RGBA `json:",omitempty"`
+	Layer int
+}
+
+// MarshalJSON saves everything but the sprite, which is an image on the GPU.
+// An entity loaded from a save game gets the sprite its New gives it.
+func (d *Render) MarshalJSON() ([]byte, error) {
+	save := renderJSON{Glyph: d.Glyph, Layer: d.Layer}
+	if d.Color != nil {
+		c := color.RGBAModel.Convert(d.Color).(color.RGBA)
+		save.Color = &c
+	}
+	return json.Marshal(save)
+}
+
+// UnmarshalJSON loads a Render saved by MarshalJSON, leaving the sprite as it
+// was.
+func (d *Render) UnmarshalJSON(data []byte) error {
+	var save renderJSON
+	if err := json.Unmarshal(data, &save); err != nil {
+		return err
+	}
+
+	d.Glyph, d.Layer = save.Glyph, save.Layer
+	d.Color = nil
+	if save.Color != nil {
+		d.Color = *save.Color
+	}
+
+	return nil
+}
+
+// DrawSprite draws a sprite with its top left corner in the given grid cell.
+// x & y are grid coordinates, and cellWidth & cellHeight are the size of a
+// grid cell in pixels.
+func DrawSprite(screen *ebiten.Image, sprite *ebiten.Image, x, y, cellWidth, cellHeight int) {
+	DrawSpriteAt(screen, sprite, x*cellWidth, y*cellHeight)
+}
+
+// DrawSpriteAt draws a sprite with its top left corner at the given screen
+// position, in pixels.
+func DrawSpriteAt(screen *ebiten.Image, sprite *ebiten.Image, px, py int) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(px), float64(py))
+	screen.DrawImage(sprite, op)
+}
+
+// IsDrawable returns true if the component has a sprite or a glyph to draw. If
+// it doesn't, Draw will draw the placeholder instead.
+func (d *Render) IsDrawable() bool {
+	return d.Sprite != nil || d.Glyph != 0
+}
+
+// Draw draws the entity to the screen. x & y are grid coordinates, and
+// cellWidth & cellHeight are the size of a grid cell in pixels.
+func (d *Render) Draw(screen *ebiten.Image, x, y, cellWidth, cellHeight int) {
+	d.DrawAt(screen, x*cellWidth, y*cellHeight)
+}
+
+// DrawAt draws the entity to the screen with its top left corner at the given
+// screen position, in pixels.
+func (d *Render) DrawAt(screen *ebiten.Image, px, py int) {
 	if d.Sprite != nil {
-		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(float64(x*gridSize), float64(y*gridSize))
-		screen.DrawImage(d.Sprite, op)
-	} else if d.Glyph != 0 {
-		text.Draw(screen, string(d.Glyph), assets.GetFont("square"), x*gridSize, y*(gridSize-1), d.Color)
+		DrawSpriteAt(screen, d.Sprite, px, py)
+		return
 	}
+
+	glyph, clr := d.Glyph, d.Color
+	if glyph == 0 {
+		glyph, clr = PlaceholderGlyph, PlaceholderColor
+	}
+	if clr == nil {
+		clr = PlaceholderColor
+	}
+
+	face := assets.GetFont("square")
+
+	// the font might not have the glyph we've been asked for, in which case
+	// text.Draw would silently draw nothing.
+	if _, ok := face.GlyphAdvance(glyph); !ok {
+		glyph = PlaceholderGlyph
+	}
+
+	// text.Draw takes the position of the baseline, not the top of the glyph
+	ascent := face.Metrics().Ascent.Ceil()
+	text.Draw(screen, string(glyph), face, px, py+ascent, clr)
 }
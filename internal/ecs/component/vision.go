@@ -0,0 +1,32 @@
+package component
+
+import "github.com/matjam/sword/internal/ecs"
+
+// DefaultVisionRadius is how far an entity can see if its Vision has no
+// Radius.
+const DefaultVisionRadius = 8
+
+// Vision gives an entity its own sight, so that mobs only notice the player
+// when the player is close enough and not hidden behind a wall. The Sight
+// system works out CanSeePlayer once a turn.
+type Vision struct {
+	// Radius is how many tiles the entity can see. If it is zero,
+	// DefaultVisionRadius is used.
+	Radius int
+
+	// CanSeePlayer is true if the entity could see the player at the start of
+	// the turn.
+	CanSeePlayer bool
+}
+
+func (*Vision) ComponentName() ecs.ComponentName {
+	return "vision"
+}
+
+// SightRadius returns the radius the entity can see out to.
+func (v *Vision) SightRadius() int {
+	if v.Radius == 0 {
+		return DefaultVisionRadius
+	}
+	return v.Radius
+}
@@ -0,0 +1,111 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// dumpSample is the number of entities Dump describes in full.
+const dumpSample = 5
+
+var imageType = reflect.TypeOf((*ebiten.Image)(nil))
+
+// DumpEntity describes the entity and all of its components as text, for
+// debugging and for test failures. Each component is listed on its own line,
+// sorted by name, with all of its fields. Images are described by their size
+// rather than their pixels.
+//
+// This uses reflection, so it's slow; don't call it every frame.
+func (w *World) DumpEntity(entityID EntityID) string {
+	entity, ok := w.entities[entityID]
+	if !ok {
+		return fmt.Sprintf("entity %d does not exist\n", entityID)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "entity %d (%s)\n", entityID, entity.EntityName())
+
+	components := w.entityComponents[entityID]
+	names := make([]ComponentName, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		fmt.Fprintf(&sb, "  %s: %s\n", name, dumpValue(reflect.ValueOf(w.components[components[name]])))
+	}
+
+	return sb.String()
+}
+
+// Dump describes the whole world as text: how many entities, components and
+// systems there are, how many of each type of entity, and the first few
+// entities in full. Like DumpEntity, it's slow.
+func (w *World) Dump() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "world: %d entities, %d components, %d systems, turn %d\n",
+		len(w.entities), len(w.components), len(w.systems), w.turn)
+
+	names := make([]EntityName, 0, len(w.entitiesByName))
+	for name, entities := range w.entitiesByName {
+		if len(entities) > 0 {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		fmt.Fprintf(&sb, "  %s: %d\n", name, len(w.entitiesByName[name]))
+	}
+
+	entities := make([]EntityID, 0, len(w.entities))
+	for entityID := range w.entities {
+		entities = append(entities, entityID)
+	}
+	slices.Sort(entities)
+
+	for _, entityID := range entities[:min(len(entities), dumpSample)] {
+		sb.WriteString(w.DumpEntity(entityID))
+	}
+	if len(entities) > dumpSample {
+		fmt.Fprintf(&sb, "... and %d more entities\n", len(entities)-dumpSample)
+	}
+
+	return sb.String()
+}
+
+// dumpValue formats a component for DumpEntity, as {Field: value, ...}.
+func dumpValue(v reflect.Value) string {
+	for v.Kind() == reflect.Pointer && v.Type() != imageType {
+		if v.IsNil() {
+			return "nil"
+		}
+		v = v.Elem()
+	}
+
+	if v.Type() == imageType {
+		if v.IsNil() {
+			return "nil"
+		}
+		if !v.CanInterface() {
+			return "image"
+		}
+		bounds := v.Interface().(*ebiten.Image).Bounds()
+		return fmt.Sprintf("image %dx%d", bounds.Dx(), bounds.Dy())
+	}
+
+	if v.Kind() != reflect.Struct {
+		return fmt.Sprintf("%v", v)
+	}
+
+	fields := make([]string, 0, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		fields = append(fields, fmt.Sprintf("%s: %s", v.Type().Field(i).Name, dumpValue(v.Field(i))))
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
@@ -27,24 +27,50 @@
 // memory, and use indices instead of pointers. This would make it easier to
 // iterate over the data, and would be more cache friendly. However, this
 // implementation is simpler, and is good enough for now.
+//
+// Everything the World hands out is in a fixed order, even though it is
+// stored in maps. Systems are updated and drawn in the order they were added,
+// and entities and components are always visited in order of their IDs. IDs
+// are handed out in the same order every time, so the same inputs always play
+// out the same way, which replays, save games and tests all depend on.
+//
+// The IDs of removed entities are reused, but never in a way that lets an old
+// ID refer to the new entity; see EntityID.
 package ecs
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/matjam/sword/internal/rng"
 )
 
-// These IDs are globally unique identifiers for entities, components and
-// systems. They are used to identify an entity, component or system when
-// registering them with the world, and when adding them to an entity.
+// ErrSystemExists is returned when adding a system with the same name as one
+// that has already been added.
+var ErrSystemExists = errors.New("system already exists")
+
+// These IDs are unique identifiers for entities and components. They are
+// used to identify an entity or component when registering them with the
+// world, and when adding them to an entity. Entities and components have
+// their own separate sets of IDs, so an EntityID and a ComponentID can have
+// the same value.
 type ID uint32
 
 // EntityName is a unique identifier for an entity type in the ECS.
 type EntityName string
 
 // EntityID is a unique identifier for an instance of an entity in the ECS.
+// Once an entity has been removed, its ID never refers to another entity, so
+// it's safe to hold on to one: GetEntity returns nil for an entity that no
+// longer exists. The zero EntityID never refers to an entity.
 type EntityID ID
 
 type ComponentName string
@@ -100,9 +126,13 @@ type RenderSystem interface {
 // as well as retrieve all components used by a given system. We also need to
 // be able to retrieve a component for a given entity.
 type World struct {
-	// Every entity, component and system has a unique ID. The nextUniqueID
-	// field stores the next ID to be used.
-	nextUniqueID ID
+	// ids hands out the IDs of entities, reusing the slots of removed ones.
+	ids entityAllocator
+
+	// nextComponentID is the ID the next component will get. Components are
+	// only ever looked up by the systems that were handed their IDs, so
+	// their IDs aren't reused.
+	nextComponentID ID
 
 	// entities holds all of the entities in the world. Each entity is stored
 	// by its ID.
@@ -135,12 +165,53 @@ type World struct {
 	// that have that component.
 	componentEntities map[ComponentName][]EntityID
 
+	// componentOwners maps each component ID back to the entity that owns it.
+	componentOwners map[ComponentID]EntityID
+
+	// cells is the index of which entities are on each tile, going by their
+	// Positioned components, sorted by EntityID. positioned holds the ID of
+	// each entity's Positioned component, and positions the tile it is
+	// indexed under. See MoveEntity.
+	cells      map[[2]int][]EntityID
+	positioned map[EntityID]ComponentID
+	positions  map[EntityID][2]int
+
+	// pools holds the component pools that have been registered, keyed by
+	// the name of the component.
+	pools map[ComponentName]Pool
+
+	// changed holds the entities whose components have changed since the
+	// start of the current frame, keyed by the name of the component. See
+	// MarkChanged.
+	changed map[ComponentName]map[EntityID]struct{}
+
+	// turn is the number of turns that have been completed. Update is called
+	// every frame, but a turn only ends when the player does something.
+	turn uint64
+
+	// rng is the source of all randomness in gameplay, so that a game can be
+	// replayed exactly from its seed. source counts how many numbers it has
+	// handed out, so that a save game can put it back where it was.
+	seed   int64
+	rng    *rand.Rand
+	source *countingSource
+
+	// resources holds the things that belong to the world as a whole rather
+	// than to any one entity, keyed by their type. See SetResource.
+	resources map[reflect.Type]any
+
+	// entityTypes and componentTypes are every type of entity and component
+	// the world knows how to create when loading a save game. See
+	// RegisterEntities.
+	entityTypes    map[EntityName]Entity
+	componentTypes map[ComponentName]reflect.Type
+
 	// componentGroups
 }
 
 func NewWorld() *World {
 	w := &World{
-		nextUniqueID:      1,
+		nextComponentID:   1,
 		entities:          make(map[EntityID]Entity),
 		entitiesByName:    make(map[EntityName][]EntityID),
 		systems:           make([]System, 0),
@@ -149,13 +220,31 @@ func NewWorld() *World {
 		entityComponents:  make(map[EntityID]map[ComponentName]ComponentID),
 		systemComponents:  make(map[SystemName]map[ComponentName][]ComponentID),
 		componentEntities: make(map[ComponentName][]EntityID),
+		componentOwners:   make(map[ComponentID]EntityID),
+		cells:             make(map[[2]int][]EntityID),
+		positioned:        make(map[EntityID]ComponentID),
+		positions:         make(map[EntityID][2]int),
+		pools:             make(map[ComponentName]Pool),
+		changed:           make(map[ComponentName]map[EntityID]struct{}),
+		resources:         make(map[reflect.Type]any),
+		entityTypes:       make(map[EntityName]Entity),
+		componentTypes:    make(map[ComponentName]reflect.Type),
 	}
 
+	w.SetSeed(1)
+
 	return w
 }
 
-// AddSystem adds a system to the world.
-func (w *World) AddSystem(system System) {
+// AddSystem adds a system to the world. There can only be one system with a
+// given name, so adding a second one returns ErrSystemExists and leaves the
+// first one in place.
+func (w *World) AddSystem(system System) error {
+	if w.HasSystem(system) {
+		slog.Error("system already exists", "system", system.SystemName())
+		return fmt.Errorf("%w: %s", ErrSystemExists, system.SystemName())
+	}
+
 	system.Init(w)
 
 	// check if this is a RenderSystem
@@ -177,23 +266,55 @@ func (w *World) AddSystem(system System) {
 		w.systemComponents[system.SystemName()][name] = make([]ComponentID, 0)
 	}
 
+	return nil
+}
+
+// AddSystems adds each of the systems to the world in order, as if by
+// AddSystem. Systems that can't be added are skipped, and the errors for all
+// of them are returned together.
+func (w *World) AddSystems(systems ...System) error {
+	var errs []error
+	for _, system := range systems {
+		if err := w.AddSystem(system); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
 // AddEntity adds an entity to the world. It returns the entity ID. Optionally, you can
 // pass a list of components to add to the entity.
 func (w *World) AddEntity(entity Entity) EntityID {
-	id := EntityID(w.nextID())
+	id := w.ids.alloc()
 
+	w.RegisterEntities(entity)
 	entity, components := entity.New()
+	w.addEntity(id, entity, components, nil)
+
+	return id
+}
 
+// addEntity adds an entity with the given ID and components to the world. If
+// componentIDs is not nil, it holds the ID to give each of the components,
+// otherwise they are given new ones.
+func (w *World) addEntity(id EntityID, entity Entity, components []Component, componentIDs []ComponentID) {
 	if len(components) == 0 {
 		slog.Warn("adding entity with no components", "entity", entity.EntityName())
 	}
 
 	w.entities[id] = entity
 	componentNames := make([]ComponentName, 0)
-	for _, component := range components {
-		w.AddComponent(id, component)
+	for i, component := range components {
+		if pool, ok := w.pools[component.ComponentName()]; ok {
+			component = pool.get(component)
+		}
+
+		if componentIDs != nil {
+			w.addComponent(id, componentIDs[i], component)
+		} else {
+			w.AddComponent(id, component)
+		}
 		componentNames = append(componentNames, component.ComponentName())
 	}
 
@@ -204,14 +325,123 @@ func (w *World) AddEntity(entity Entity) EntityID {
 	w.entitiesByName[entity.EntityName()] = append(w.entitiesByName[entity.EntityName()], id)
 
 	slog.Info("added entity", "id", id, "components", componentNames)
-	return id
+}
+
+// RemoveEntity removes an entity and all of its components from the world.
+// Components with a registered Pool are returned to it. It is safe to remove
+// entities from inside IterateComponents, but not IterateComponentsParallel.
+//
+// The entity's ID may be reused for a later entity, but with a new
+// generation, so the old ID stays dead.
+func (w *World) RemoveEntity(entityID EntityID) {
+	entity, ok := w.entities[entityID]
+	if !ok {
+		slog.Warn("removing entity that does not exist", "entity_id", entityID)
+		return
+	}
+
+	for name, componentID := range w.entityComponents[entityID] {
+		w.removeComponent(entityID, name, componentID)
+	}
+
+	for _, changed := range w.changed {
+		delete(changed, entityID)
+	}
+
+	name := entity.EntityName()
+	w.entitiesByName[name] = removeValue(w.entitiesByName[name], entityID)
+
+	delete(w.entityComponents, entityID)
+	delete(w.entities, entityID)
+	w.ids.release(entityID)
+
+	slog.Info("removed entity", "id", entityID)
+}
+
+// removeComponent removes the component with the given name and ID from the
+// entity, and from every system that uses it, returning it to its Pool if it
+// has one.
+func (w *World) removeComponent(entityID EntityID, name ComponentName, componentID ComponentID) {
+	component := w.components[componentID]
+
+	// the entity leaves every system that needed the component, so that
+	// the system's lists still line up.
+	for _, systemComponents := range w.systemComponents {
+		if _, ok := systemComponents[name]; !ok || !w.hasAll(entityID, systemComponents) {
+			continue
+		}
+
+		for name, componentIDs := range systemComponents {
+			systemComponents[name] = removeValue(componentIDs, w.entityComponents[entityID][name])
+		}
+	}
+
+	w.componentEntities[name] = removeValue(w.componentEntities[name], entityID)
+
+	if w.positioned[entityID] == componentID {
+		w.unindexPosition(entityID)
+	}
+
+	delete(w.entityComponents[entityID], name)
+	delete(w.components, componentID)
+	delete(w.componentOwners, componentID)
+
+	if pool, ok := w.pools[name]; ok {
+		pool.put(component)
+	}
+}
+
+// hasAll returns true if the entity has a component for every name in
+// systemComponents.
+func (w *World) hasAll(entityID EntityID, systemComponents map[ComponentName][]ComponentID) bool {
+	for name := range systemComponents {
+		if _, ok := w.entityComponents[entityID][name]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RemoveComponent removes the entity's component of the same type as the
+// given one, such as a status effect that has worn off. The entity stops
+// being seen by any system that needs the component. Like RemoveEntity, it
+// is safe to call from inside IterateComponents.
+func (w *World) RemoveComponent(entityID EntityID, component Component) {
+	name := component.ComponentName()
+
+	componentID, ok := w.entityComponents[entityID][name]
+	if !ok {
+		slog.Warn("removing component the entity does not have", "entity_id", entityID, "component", name)
+		return
+	}
+
+	w.removeComponent(entityID, name, componentID)
+	if changed, ok := w.changed[name]; ok {
+		delete(changed, entityID)
+	}
+
+	slog.Info("removed component",
+		"entity_id", entityID,
+		"component", name,
+		"component_id", componentID)
+}
+
+// AddPool registers a pool for a type of component. See Pool.
+func (w *World) AddPool(pool Pool) {
+	w.pools[pool.ComponentName()] = pool
 }
 
 // AddComponent adds a component to an entity.
 func (w *World) AddComponent(entityID EntityID, component Component) {
-	id := ComponentID(w.nextID())
+	w.addComponent(entityID, w.nextComponent(), component)
+}
+
+// addComponent adds a component with the given ID to an entity.
+func (w *World) addComponent(entityID EntityID, id ComponentID, component Component) {
 	w.components[id] = component
 	name := component.ComponentName()
+	w.componentTypes[name] = reflect.TypeOf(component)
 
 	// Add the component to the entity.
 	if _, ok := w.entityComponents[entityID]; !ok {
@@ -219,7 +449,8 @@ func (w *World) AddComponent(entityID EntityID, component Component) {
 	}
 
 	// check that the entity doesn't already have the component
-	if _, ok := w.entityComponents[entityID][name]; ok {
+	old, duplicate := w.entityComponents[entityID][name]
+	if duplicate {
 		slog.Error("Entity already has component",
 			"entity_id", entityID,
 			"component", component.ComponentName(),
@@ -228,16 +459,44 @@ func (w *World) AddComponent(entityID EntityID, component Component) {
 
 	// Add the component to the entity.
 	w.entityComponents[entityID][name] = id
+	w.componentOwners[id] = entityID
 
-	// Add the component to the systemComponents map.
-	for systemName, systemComponents := range w.systemComponents {
-		if _, ok := systemComponents[name]; ok {
-			w.systemComponents[systemName][name] = append(w.systemComponents[systemName][name], id)
+	if positioned, ok := component.(Positioned); ok {
+		w.unindexPosition(entityID)
+		w.indexPosition(entityID, id, positioned)
+	}
+
+	// Add the component to the systemComponents map. An entity is only in a
+	// system's lists once it has every component the system needs, so that
+	// the lists line up. They are kept in order of the owning entity, so
+	// that a component added to an existing entity doesn't end up at the
+	// back of the queue.
+	for _, systemComponents := range w.systemComponents {
+		componentIDs, ok := systemComponents[name]
+		if !ok || !w.hasAll(entityID, systemComponents) {
+			continue
+		}
+
+		if duplicate {
+			if i := slices.Index(componentIDs, old); i >= 0 {
+				componentIDs[i] = id
+			}
+			continue
+		}
+
+		for name, componentIDs := range systemComponents {
+			i, _ := slices.BinarySearchFunc(componentIDs, entityID, func(c ComponentID, e EntityID) int {
+				return int(w.componentOwners[c]) - int(e)
+			})
+			systemComponents[name] = slices.Insert(componentIDs, i, w.entityComponents[entityID][name])
 		}
 	}
 
 	// Add the entity to the componentEntities map.
-	w.componentEntities[name] = append(w.componentEntities[name], entityID)
+	i, _ := slices.BinarySearch(w.componentEntities[name], entityID)
+	w.componentEntities[name] = slices.Insert(w.componentEntities[name], i, entityID)
+
+	w.MarkChanged(entityID, name)
 
 	slog.Info("Added component",
 		"entity_id", entityID,
@@ -245,6 +504,38 @@ func (w *World) AddComponent(entityID EntityID, component Component) {
 		"component_id", id)
 }
 
+// ReplaceComponent replaces the entity's component of the same type with the
+// given one. The new component takes over the old one's ComponentID, so the
+// systems see it in the same place as before. If the entity doesn't have a
+// component of that type, it is added as if by AddComponent.
+func (w *World) ReplaceComponent(entityID EntityID, component Component) {
+	name := component.ComponentName()
+
+	id, ok := w.entityComponents[entityID][name]
+	if !ok {
+		w.AddComponent(entityID, component)
+		return
+	}
+
+	old := w.components[id]
+	w.components[id] = component
+	w.MarkChanged(entityID, name)
+
+	if positioned, ok := component.(Positioned); ok {
+		w.unindexPosition(entityID)
+		w.indexPosition(entityID, id, positioned)
+	}
+
+	if pool, ok := w.pools[name]; ok && old != component {
+		pool.put(old)
+	}
+
+	slog.Info("replaced component",
+		"entity_id", entityID,
+		"component", name,
+		"component_id", id)
+}
+
 // HasComponent returns true if the given entity has the given component.
 func (w *World) HasComponent(entityID EntityID, component Component) bool {
 	name := component.ComponentName()
@@ -283,7 +574,7 @@ func (w *World) GetComponent(entityID EntityID, component Component) Component {
 }
 
 // EntitiesForSystem returns a list of entities that have all of the components
-// that the given system operates on.
+// that the given system operates on, sorted by EntityID.
 func (w *World) EntitiesForSystem(system System) []EntityID {
 	return w.GetEntitiesWithComponents(system.Components()...)
 }
@@ -297,8 +588,13 @@ func (w *World) ComponentsForSystem(system System) map[ComponentName][]Component
 	return systemComponents
 }
 
-// Update updates all systems in the world.
+// Update updates all systems in the world, in the order they were added. It
+// starts a new frame, so everything ChangedThisFrame returned is forgotten.
 func (w *World) Update(deltaTime time.Duration) {
+	for _, changed := range w.changed {
+		clear(changed)
+	}
+
 	for _, system := range w.systems {
 		system.Update(deltaTime)
 	}
@@ -308,17 +604,99 @@ func (w *World) Update(deltaTime time.Duration) {
 	}
 }
 
-// Draw draws all render systems in the world.
+// Draw draws all render systems in the world, in the order they were added.
 func (w *World) Draw(screen *ebiten.Image) {
 	for _, renderSystem := range w.renderSystems {
 		renderSystem.Draw(screen)
 	}
 }
 
-// nextID returns the next unique ID to be used.
-func (w *World) nextID() ID {
-	id := w.nextUniqueID
-	w.nextUniqueID++
+// MarkChanged records that the entity's component with the given name has
+// changed this frame. Systems should call it whenever they change a component
+// that something else might be watching, such as a Location when an entity
+// moves. Adding or replacing a component marks it as changed automatically.
+func (w *World) MarkChanged(entityID EntityID, name ComponentName) {
+	changed, ok := w.changed[name]
+	if !ok {
+		changed = make(map[EntityID]struct{})
+		w.changed[name] = changed
+	}
+
+	changed[entityID] = struct{}{}
+}
+
+// ChangedThisFrame returns the entities whose component with the given name
+// has been marked as changed since the start of the current frame, sorted by
+// EntityID. Systems that do expensive work for each entity, such as caching
+// how it's drawn, can use this to skip the entities that haven't changed.
+//
+// Changes are forgotten at the start of every Update, so a change made by a
+// system is only seen by the systems updated after it, and by every Draw.
+func (w *World) ChangedThisFrame(name ComponentName) []EntityID {
+	entities := make([]EntityID, 0, len(w.changed[name]))
+	for entityID := range w.changed[name] {
+		entities = append(entities, entityID)
+	}
+	slices.Sort(entities)
+	return entities
+}
+
+// Turn returns the number of turns that have been completed so far. Systems
+// that work in game time rather than real time, such as status effects or
+// regeneration, should use this instead of the deltaTime passed to Update.
+func (w *World) Turn() uint64 {
+	return w.turn
+}
+
+// EndTurn ends the current turn. It should be called once every time the
+// player completes an action, not every frame.
+func (w *World) EndTurn() {
+	w.turn++
+}
+
+// SetSeed reseeds the world's random number generator. Call it with the game
+// seed once the world has been created; a new world is seeded with 1. The
+// numbers come from an rng.Source, so a seed plays out the same way with
+// every version of Go.
+func (w *World) SetSeed(seed int64) {
+	w.seed = seed
+	w.source = &countingSource{src: rng.NewSource(seed)}
+	w.rng = rand.New(w.source)
+}
+
+// Seed returns the seed the world's random number generator was last seeded
+// with.
+func (w *World) Seed() int64 {
+	return w.seed
+}
+
+// Rand returns the world's random number generator. All gameplay randomness,
+// such as combat rolls or wandering mobs, must come from here so that a game
+// plays out the same way every time for a given seed. Systems must not use
+// the global functions in math/rand.
+//
+// The generator is not safe for concurrent use, so it must not be used from
+// inside IterateComponentsParallel.
+func (w *World) Rand() *rand.Rand {
+	return w.rng
+}
+
+// EntitiesNamed returns every entity of the given type, sorted by EntityID.
+func (w *World) EntitiesNamed(name EntityName) []EntityID {
+	entities := slices.Clone(w.entitiesByName[name])
+	slices.Sort(entities)
+	return entities
+}
+
+// EntityCount returns the number of entities in the world.
+func (w *World) EntityCount() int {
+	return len(w.entities)
+}
+
+// nextComponent returns the ID for a new component.
+func (w *World) nextComponent() ComponentID {
+	id := ComponentID(w.nextComponentID)
+	w.nextComponentID++
 	return id
 }
 
@@ -332,14 +710,19 @@ func GetComponentID[T Component](world *World, componentID ComponentID) T {
 	return world.components[componentID].(T)
 }
 
+// GetComponentIDsForEntity returns the IDs of all of the entity's components,
+// sorted by ComponentID.
 func (world *World) GetComponentIDsForEntity(entityID EntityID) []ComponentID {
 	components := make([]ComponentID, 0)
 	for _, componentID := range world.entityComponents[entityID] {
 		components = append(components, componentID)
 	}
+	slices.Sort(components)
 	return components
 }
 
+// GetEntitiesWithComponents returns the entities that have all of the given
+// components, sorted by EntityID.
 func (world *World) GetEntitiesWithComponents(components ...Component) []EntityID {
 	entities := make([]EntityID, 0)
 	for entityID := range world.entities {
@@ -347,6 +730,7 @@ func (world *World) GetEntitiesWithComponents(components ...Component) []EntityI
 			entities = append(entities, entityID)
 		}
 	}
+	slices.Sort(entities)
 	return entities
 }
 
@@ -358,12 +742,16 @@ func (world *World) GetEntitiesWithComponents(components ...Component) []EntityI
 // For example, if a system operates on a Move component and a Location
 // component, the function will be called with a map of two components, one for
 // Move and one for Location, with the ID of each component.
+//
+// The entities are visited in order of their EntityID.
+//
+// The entities to visit are worked out before f is first called, so f is
+// free to add and remove entities and components, including the ones it was
+// passed. Entities that are removed, or lose one of the system's components,
+// before they are reached are skipped. Entities that are added, or gain the
+// components the system needs, aren't visited until the next call.
 func (w *World) IterateComponents(system System, f func(map[ComponentName]ComponentID)) {
-	systemName := system.SystemName()
-	systemComponents := w.systemComponents[systemName]
-	arg := make(map[ComponentName]ComponentID)
-
-	if len(systemComponents) == 0 {
+	if len(w.systemComponents[system.SystemName()]) == 0 {
 		// This is likely not an actual problem, but it's worth logging a warning
 		// because you probably don't want to iterate over an empty list of
 		// components. Nothing will happen.
@@ -371,18 +759,109 @@ func (w *World) IterateComponents(system System, f func(map[ComponentName]Compon
 		return
 	}
 
+	for _, arg := range w.componentArgs(system) {
+		if !w.stillHas(arg) {
+			continue
+		}
+
+		f(arg)
+	}
+}
+
+// componentArgs builds the argument IterateComponents passes to f for each
+// of the system's entities, in order of their EntityID.
+func (w *World) componentArgs(system System) []map[ComponentName]ComponentID {
+	systemComponents := w.systemComponents[system.SystemName()]
+
 	entityCount := len(systemComponents[system.Components()[0].ComponentName()])
-	for i := 0; i < entityCount; i++ {
+	args := make([]map[ComponentName]ComponentID, entityCount)
+	for i := range args {
+		args[i] = make(map[ComponentName]ComponentID, len(systemComponents))
 		for componentName, componentIDs := range systemComponents {
-			arg[componentName] = componentIDs[i]
+			args[i][componentName] = componentIDs[i]
 		}
+	}
 
-		f(arg)
+	return args
+}
 
-		arg = make(map[ComponentName]ComponentID)
+// stillHas returns true if every component in arg is still in the world.
+func (w *World) stillHas(arg map[ComponentName]ComponentID) bool {
+	for _, componentID := range arg {
+		if _, ok := w.components[componentID]; !ok {
+			return false
+		}
 	}
+
+	return true
 }
 
+// IterateComponentsParallel works like IterateComponents, but shards the
+// entities across a pool of GOMAXPROCS goroutines and calls f concurrently. It
+// returns once f has been called for every entity. This is meant for systems
+// that do a lot of CPU-bound work per entity, such as pathfinding for hundreds
+// of mobs, where calling f one entity at a time would leave cores idle.
+//
+// Because f runs on several goroutines at once, it must follow some rules to
+// stay free of data races:
+//
+//   - f may only read and write the components it was passed. Components that
+//     belong to other entities may be being written by another goroutine.
+//   - f must not make structural changes to the world, such as adding entities
+//     or components. The World is not safe for concurrent writes. Record any
+//     such changes and apply them once IterateComponentsParallel returns.
+//   - Reading the world (GetComponentID, HasComponent and so on) is fine, as
+//     long as nothing else is writing to it at the same time.
+//   - Anything else f shares with other calls, such as counters or slices on
+//     the system, must be protected with a mutex or atomics.
+//
+// The order that f is called in is not defined.
+func (w *World) IterateComponentsParallel(system System, f func(map[ComponentName]ComponentID)) {
+	if len(w.systemComponents[system.SystemName()]) == 0 {
+		slog.Warn("IterateComponentsParallel called with a system that does not use components, stop that")
+		return
+	}
+
+	// build all of the arguments up front, so that the workers never touch
+	// the world's maps themselves.
+	args := w.componentArgs(system)
+	entityCount := len(args)
+
+	workers := min(runtime.GOMAXPROCS(0), entityCount)
+	if workers <= 1 {
+		for _, arg := range args {
+			f(arg)
+		}
+		return
+	}
+
+	// each worker gets a contiguous shard of the entities.
+	shardSize := (entityCount + workers - 1) / workers
+
+	var wg sync.WaitGroup
+	for start := 0; start < entityCount; start += shardSize {
+		shard := args[start:min(start+shardSize, entityCount)]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, arg := range shard {
+				f(arg)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// EntityForComponent returns the ID of the entity that owns the given
+// component. This is useful inside IterateComponents, which only passes the
+// component IDs.
+func (w *World) EntityForComponent(componentID ComponentID) EntityID {
+	return w.componentOwners[componentID]
+}
+
+// GetEntity returns the entity with the given ID, or nil if there isn't one,
+// such as when it has been removed.
 func (w *World) GetEntity(entityID EntityID) Entity {
 	return w.entities[entityID]
 }
@@ -408,3 +887,15 @@ func (w *World) HasSystem(system System) bool {
 
 	return false
 }
+
+// removeValue removes the first occurrence of v from s, keeping the order of
+// the rest. It searches from the end, since entities are most often removed
+// soon after they were added.
+func removeValue[T comparable](s []T, v T) []T {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] == v {
+			return append(s[:i], s[i+1:]...)
+		}
+	}
+	return s
+}
@@ -1,13 +1,41 @@
 package ecs_test
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/matjam/sword/internal/ecs"
-	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/ecs/ecstest"
 )
 
+// testPlayer and testMob stand in for the game's player and mobs.
+
+var testPlayer = &ecstest.Entity{
+	Name: "player",
+	Components: func() []ecs.Component {
+		return []ecs.Component{
+			&ecstest.Position{},
+			&ecstest.Velocity{},
+			&ecstest.Sprite{},
+			&ecstest.Counter{N: 100},
+		}
+	},
+}
+
+var testMob = &ecstest.Entity{
+	Name: "mob",
+	Components: func() []ecs.Component {
+		return []ecs.Component{
+			&ecstest.Position{X: 5, Y: 5},
+			&ecstest.Velocity{},
+			&ecstest.Sprite{},
+			&ecstest.Counter{N: 100},
+			&ecstest.Tag{},
+		}
+	},
+}
+
 // TestEntityWithNoComponents is an entity that has no components.
 
 var _ ecs.Entity = &TestEntityWithNoComponents{}
@@ -34,10 +62,10 @@ func (*TestEntityWithComponents) EntityName() ecs.EntityName {
 
 func (*TestEntityWithComponents) New() (ecs.Entity, []ecs.Component) {
 	return &TestEntityWithComponents{}, []ecs.Component{
-		&component.Location{X: 1, Y: 1},
-		&component.Move{X: 1, Y: 1},
-		&component.Render{},
-		&component.Health{Current: 100, Max: 100},
+		&ecstest.Position{X: 1, Y: 1},
+		&ecstest.Velocity{X: 1, Y: 1},
+		&ecstest.Sprite{},
+		&ecstest.Counter{N: 100},
 	}
 }
 
@@ -91,29 +119,111 @@ func (*TestRenderSystem) Components() []ecs.Component {
 
 func (*TestRenderSystem) Draw(screen *ebiten.Image) {}
 
-// TestSystemMovement is a system that implements ecs.System
-// and is interested in the Move and Location components
+// TestSystemParallel is a system that moves entities like ecstest.Mover,
+// but uses IterateComponentsParallel and burns some CPU for every entity to
+// simulate an expensive system such as pathfinding.
 
-var _ = ecs.System(&TestSystemMovement{})
+var _ = ecs.System(&TestSystemParallel{})
 
-type TestSystemMovement struct {
+type TestSystemParallel struct {
 	world *ecs.World
+
+	// Sequential makes the system use IterateComponents instead, so the two
+	// can be compared.
+	Sequential bool
+	// Work is the number of iterations of busy work to do for each entity.
+	Work int
+
+	calls atomic.Int64
 }
 
-// Init initializes the system.
-func (sys *TestSystemMovement) Init(world *ecs.World) {
+func (sys *TestSystemParallel) Init(world *ecs.World) {
 	sys.world = world
 }
 
-// SystemName returns the name of the system.
-func (sys *TestSystemMovement) SystemName() ecs.SystemName {
-	return "movement"
+func (*TestSystemParallel) SystemName() ecs.SystemName {
+	return "parallel"
 }
 
-// Components returns the components that the system is interested in.
-func (sys *TestSystemMovement) Components() []ecs.Component {
+func (*TestSystemParallel) Components() []ecs.Component {
 	return []ecs.Component{
-		&component.Move{},
-		&component.Location{},
+		&ecstest.Velocity{},
+		&ecstest.Position{},
+	}
+}
+
+func (sys *TestSystemParallel) Update(deltaTime time.Duration) {
+	iterate := sys.world.IterateComponentsParallel
+	if sys.Sequential {
+		iterate = sys.world.IterateComponents
+	}
+
+	iterate(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
+		location := ecs.GetComponentID[*ecstest.Position](sys.world, components["position"])
+		movable := ecs.GetComponentID[*ecstest.Velocity](sys.world, components["velocity"])
+
+		location.X += movable.X + busyWork(sys.Work)
+		location.Y += movable.Y
+
+		movable.X = 0
+		movable.Y = 0
+
+		sys.calls.Add(1)
+	})
+}
+
+// busyWork spins for n iterations and returns 0, in a way that the compiler
+// can't optimise away.
+func busyWork(n int) int {
+	x := uint64(n) | 1
+	for i := 0; i < n; i++ {
+		x ^= x << 13
+		x ^= x >> 7
+		x ^= x << 17
+	}
+
+	// x is never zero, since xorshift never reaches zero from a non-zero seed
+	if x == 0 {
+		return 1
 	}
+	return 0
+}
+
+// TestProjectile is a short-lived entity with a location and movement, that
+// allocates new components every time it is created.
+
+var _ ecs.Entity = &TestProjectile{}
+
+type TestProjectile struct{}
+
+func (*TestProjectile) EntityName() ecs.EntityName {
+	return "projectile"
+}
+
+func (*TestProjectile) New() (ecs.Entity, []ecs.Component) {
+	return &TestProjectile{}, []ecs.Component{
+		&ecstest.Position{X: 1, Y: 1},
+		&ecstest.Velocity{X: 1},
+	}
+}
+
+// TestPooledProjectile is the same as TestProjectile, but returns the same
+// prototype components every time, relying on the world having pools for
+// them.
+
+var _ ecs.Entity = &TestPooledProjectile{}
+
+type TestPooledProjectile struct{}
+
+var testProjectilePrototype = []ecs.Component{
+	&ecstest.Position{X: 1, Y: 1},
+	&ecstest.Velocity{X: 1},
+}
+
+func (*TestPooledProjectile) EntityName() ecs.EntityName {
+	return "projectile"
+}
+
+func (*TestPooledProjectile) New() (ecs.Entity, []ecs.Component) {
+	return &TestPooledProjectile{}, testProjectilePrototype
 }
@@ -1,52 +1,52 @@
 package ecs_test
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"testing"
-	"time"
 
+	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/matjam/sword/internal/ecs"
-	"github.com/matjam/sword/internal/ecs/component"
-	"github.com/matjam/sword/internal/ecs/entity"
+	"github.com/matjam/sword/internal/ecs/ecstest"
 )
 
-// currently these tests rely on external packages. We will implement
-// mocks for these packages in the future.
-
 func TestMove(t *testing.T) {
 	world := ecs.NewWorld()
 
-	if world.HasSystem(&TestSystemMovement{}) {
+	if world.HasSystem(&ecstest.Mover{}) {
 		t.Errorf("The system should not exist")
 	}
 
 	// add a movement system
-	world.AddSystem(&TestSystemMovement{})
+	world.AddSystem(&ecstest.Mover{})
 
-	if !world.HasSystem(&TestSystemMovement{}) {
+	if !world.HasSystem(&ecstest.Mover{}) {
 		t.Errorf("The system should exist")
 	}
 
 	// create a player entity
-	player := world.AddEntity(&entity.Player{})
-	mob := world.AddEntity(&entity.Mob{})
+	player := world.AddEntity(testPlayer)
+	mob := world.AddEntity(testMob)
 
-	location := ecs.GetComponent[*component.Location](world, player)
+	location := ecs.GetComponent[*ecstest.Position](world, player)
 	location.X = 2
 	location.Y = 2
 
 	// Move the player
-	movable := ecs.GetComponent[*component.Move](world, player)
+	movable := ecs.GetComponent[*ecstest.Velocity](world, player)
 	movable.X = 1
 	movable.Y = 2
 
-	location = ecs.GetComponent[*component.Location](world, mob)
+	location = ecs.GetComponent[*ecstest.Position](world, mob)
 	location.X = 5
 	location.Y = 5
 
 	// Move the mob
-	movable = ecs.GetComponent[*component.Move](world, mob)
+	movable = ecs.GetComponent[*ecstest.Velocity](world, mob)
 	movable.X = 3
 	movable.Y = 4
 
@@ -54,7 +54,7 @@ func TestMove(t *testing.T) {
 	world.Update(1)
 
 	// Get the player's location
-	playerLocation := ecs.GetComponent[*component.Location](world, player)
+	playerLocation := ecs.GetComponent[*ecstest.Position](world, player)
 	slog.Info(fmt.Sprintf("Player location: %d, %d", playerLocation.X, playerLocation.Y))
 
 	if playerLocation.X != 3 || playerLocation.Y != 4 {
@@ -62,7 +62,7 @@ func TestMove(t *testing.T) {
 	}
 
 	// Get the mob's location
-	mobLocation := ecs.GetComponent[*component.Location](world, mob)
+	mobLocation := ecs.GetComponent[*ecstest.Position](world, mob)
 	slog.Info(fmt.Sprintf("Mob location: %d, %d", mobLocation.X, mobLocation.Y))
 
 	if mobLocation.X != 8 || mobLocation.Y != 9 {
@@ -87,8 +87,8 @@ func TestAddDuplicateComponents(t *testing.T) {
 	testEntityID := world.AddEntity(&TestEntityWithNoComponents{})
 
 	// Add a duplicate component
-	world.AddComponent(testEntityID, &component.Location{X: 1, Y: 1})
-	world.AddComponent(testEntityID, &component.Location{X: 1, Y: 1})
+	world.AddComponent(testEntityID, &ecstest.Position{X: 1, Y: 1})
+	world.AddComponent(testEntityID, &ecstest.Position{X: 1, Y: 1})
 	components := world.GetComponentIDsForEntity(testEntityID)
 
 	if len(components) != 1 {
@@ -104,15 +104,15 @@ func TestWorld_HasComponent(t *testing.T) {
 	testEntityID := world.AddEntity(&TestEntityWithNoComponents{})
 
 	// Add a component
-	world.AddComponent(testEntityID, &component.Location{X: 1, Y: 1})
+	world.AddComponent(testEntityID, &ecstest.Position{X: 1, Y: 1})
 
 	// Test that the component exists
-	if !world.HasComponent(testEntityID, &component.Location{}) {
+	if !world.HasComponent(testEntityID, &ecstest.Position{}) {
 		t.Errorf("The component should exist")
 	}
 
 	// Test that a non-existent component does not exist
-	if world.HasComponent(testEntityID, &component.Move{}) {
+	if world.HasComponent(testEntityID, &ecstest.Velocity{}) {
 		t.Errorf("The component should not exist")
 	}
 }
@@ -125,17 +125,17 @@ func TestWorld_HasComponents(t *testing.T) {
 	testEntityID := world.AddEntity(&TestEntityWithComponents{})
 
 	// Test that the components exist
-	if !world.HasComponents(testEntityID, &component.Location{}, &component.Move{}, &component.Render{}, &component.Health{}) {
+	if !world.HasComponents(testEntityID, &ecstest.Position{}, &ecstest.Velocity{}, &ecstest.Sprite{}, &ecstest.Counter{}) {
 		t.Errorf("The components should exist")
 	}
 
 	// Test that a non-existent component does not exist
-	if world.HasComponents(testEntityID, &component.Location{}, &component.Move{}, &component.Render{}, &component.Health{}, &component.Inventory{}) {
+	if world.HasComponents(testEntityID, &ecstest.Position{}, &ecstest.Velocity{}, &ecstest.Sprite{}, &ecstest.Counter{}, &ecstest.Tag{}) {
 		t.Errorf("The components should not exist")
 	}
 
 	// Test that checking for a smaller set of components works
-	if !world.HasComponents(testEntityID, &component.Location{}, &component.Move{}, &component.Render{}) {
+	if !world.HasComponents(testEntityID, &ecstest.Position{}, &ecstest.Velocity{}, &ecstest.Sprite{}) {
 		t.Errorf("The components should exist")
 	}
 }
@@ -147,7 +147,7 @@ func TestWorld_GetComponent(t *testing.T) {
 	testEntityID := world.AddEntity(&TestEntityWithComponents{})
 
 	// Test that the component exists
-	location := ecs.GetComponent[*component.Location](world, testEntityID)
+	location := ecs.GetComponent[*ecstest.Position](world, testEntityID)
 
 	if location.X != 1 || location.Y != 1 {
 		t.Errorf("The component should exist")
@@ -161,7 +161,7 @@ func TestWorld_GetComponent(t *testing.T) {
 		}()
 
 		// Test that a non-existent component does not exist
-		inventory := ecs.GetComponent[*component.Inventory](world, testEntityID)
+		inventory := ecs.GetComponent[*ecstest.Tag](world, testEntityID)
 		if inventory != nil {
 			t.Errorf("The component should not exist")
 		}
@@ -175,7 +175,7 @@ func TestWorld_EntitiesForSystem(t *testing.T) {
 	testEntityID := world.AddEntity(&TestEntityWithComponents{})
 
 	// Test that the component exists
-	entities := world.EntitiesForSystem(&TestSystemMovement{})
+	entities := world.EntitiesForSystem(&ecstest.Mover{})
 
 	if len(entities) != 1 {
 		t.Fatal("There should be 1 entity")
@@ -189,20 +189,19 @@ func TestWorld_EntitiesForSystem(t *testing.T) {
 func TestWorld_ComponentsForSystem(t *testing.T) {
 	// Test that the ComponentsForSystem function works
 
-	world := ecs.NewWorld()
-	world.AddSystem(&TestSystemMovement{})
+	world := ecstest.NewWorld(t, &ecstest.Mover{})
 	world.AddEntity(&TestEntityWithComponents{})
 
 	// Test that the component exists
-	components := world.ComponentsForSystem(&TestSystemMovement{})
+	components := world.ComponentsForSystem(&ecstest.Mover{})
 
 	// Should be two components returned: Location and Move
 	if len(components) != 2 {
 		t.Fatal("There should be 2 component")
 	}
 
-	location := ecs.GetComponentID[*component.Location](world, components["location"][0])
-	move := ecs.GetComponentID[*component.Move](world, components["move"][0])
+	location := ecs.GetComponentID[*ecstest.Position](world, components["position"][0])
+	move := ecs.GetComponentID[*ecstest.Velocity](world, components["velocity"][0])
 
 	if location == nil || move == nil {
 		t.Fatal("Location and Move components should exist")
@@ -231,12 +230,64 @@ func TestWorld_GetComponentIDsForEntity(t *testing.T) {
 	}
 }
 
+func TestWorld_EntityForComponent(t *testing.T) {
+	// Test that components can be traced back to the entity that owns them
+
+	world := ecs.NewWorld()
+	player := world.AddEntity(testPlayer)
+	mob := world.AddEntity(testMob)
+
+	for _, entityID := range []ecs.EntityID{player, mob} {
+		for _, componentID := range world.GetComponentIDsForEntity(entityID) {
+			if owner := world.EntityForComponent(componentID); owner != entityID {
+				t.Errorf("component %d should belong to entity %d, got %d", componentID, entityID, owner)
+			}
+		}
+	}
+}
+
 func TestWorld_AddSystemWithNoComponents(t *testing.T) {
 	world := ecs.NewWorld()
 	world.AddSystem(&TestSystemWithNoComponents{})
 	world.Update(1)
 }
 
+func TestWorld_AddSystemDuplicate(t *testing.T) {
+	// Test that a second system with the same name is rejected, and doesn't
+	// replace the first one
+
+	world := ecs.NewWorld()
+	first := &ecstest.System{Name: "movement", Wants: []ecs.Component{&ecstest.Position{}}}
+	if err := world.AddSystem(first); err != nil {
+		t.Fatalf("adding the first system should succeed, got %v", err)
+	}
+
+	world.AddEntity(&TestEntityWithComponents{})
+
+	second := &ecstest.System{Name: "movement"}
+	if err := world.AddSystem(second); !errors.Is(err, ecs.ErrSystemExists) {
+		t.Errorf("expected ErrSystemExists, got %v", err)
+	}
+
+	if second.World != nil {
+		t.Error("the rejected system should not have been initialized")
+	}
+
+	if components := world.ComponentsForSystem(first); len(components["position"]) != 1 {
+		t.Error("the first system's components should not have been reset")
+	}
+
+	// AddSystems adds everything it can, and reports the rest
+	err := world.AddSystems(&TestSystemWithNoComponents{}, &ecstest.System{Name: "movement"})
+	if !errors.Is(err, ecs.ErrSystemExists) {
+		t.Errorf("expected ErrSystemExists, got %v", err)
+	}
+
+	if !world.HasSystem(&TestSystemWithNoComponents{}) {
+		t.Error("the other system should have been added")
+	}
+}
+
 func TestWorld_GetEntity(t *testing.T) {
 	// Test that the GetEntity function works
 
@@ -251,6 +302,122 @@ func TestWorld_GetEntity(t *testing.T) {
 	}
 }
 
+func TestWorld_EntitiesNamed(t *testing.T) {
+	// Test that entities can be looked up by their type
+
+	world := ecs.NewWorld()
+	mob1 := world.AddEntity(testMob)
+	world.AddEntity(testPlayer)
+	mob2 := world.AddEntity(testMob)
+
+	if mobs := world.EntitiesNamed("mob"); len(mobs) != 2 || mobs[0] != mob1 || mobs[1] != mob2 {
+		t.Errorf("Expected the two mobs, got %v", mobs)
+	}
+
+	world.RemoveEntity(mob1)
+
+	if mobs := world.EntitiesNamed("mob"); len(mobs) != 1 || mobs[0] != mob2 {
+		t.Errorf("Expected only the second mob, got %v", mobs)
+	}
+
+	if entities := world.EntitiesNamed("nothing"); len(entities) != 0 {
+		t.Errorf("Expected no entities, got %v", entities)
+	}
+}
+
+func TestWorld_DumpEntity(t *testing.T) {
+	// Test that an entity's components are dumped as readable text
+
+	ecstest.Quiet(t)
+
+	world := ecs.NewWorld()
+	player := world.AddEntity(testPlayer)
+	ecs.GetComponent[*ecstest.Sprite](world, player).Image = ebiten.NewImage(16, 12)
+
+	dump := world.DumpEntity(player)
+
+	for _, expected := range []string{
+		fmt.Sprintf("entity %d (player)\n", player),
+		"  counter: {N: 100}\n",
+		"  position: {X: 0, Y: 0}\n",
+		"Image: image 16x12",
+	} {
+		if !strings.Contains(dump, expected) {
+			t.Errorf("expected the dump to contain %q, got:\n%s", expected, dump)
+		}
+	}
+
+	// the components are sorted by name
+	if strings.Index(dump, "counter:") > strings.Index(dump, "position:") {
+		t.Errorf("expected the components to be sorted, got:\n%s", dump)
+	}
+
+	world.RemoveEntity(player)
+	if dump := world.DumpEntity(player); !strings.Contains(dump, "does not exist") {
+		t.Errorf("expected a removed entity to not exist, got %q", dump)
+	}
+}
+
+func TestWorld_Dump(t *testing.T) {
+	// Test that the whole world is summarised, with only a sample in full
+
+	ecstest.Quiet(t)
+
+	world := ecs.NewWorld()
+	world.AddEntity(testPlayer)
+	for i := 0; i < 9; i++ {
+		world.AddEntity(testMob)
+	}
+
+	dump := world.Dump()
+
+	for _, expected := range []string{
+		"world: 10 entities",
+		"  mob: 9\n",
+		"  player: 1\n",
+		"(player)",
+		"... and 5 more entities",
+	} {
+		if !strings.Contains(dump, expected) {
+			t.Errorf("expected the dump to contain %q, got:\n%s", expected, dump)
+		}
+	}
+
+	if count := strings.Count(dump, "entity "); count != 5 {
+		t.Errorf("expected 5 entities to be dumped in full, got %d", count)
+	}
+}
+
+func TestWorld_Resource(t *testing.T) {
+	// Test that resources are stored and looked up by their type
+
+	type score struct{ points int }
+
+	world := ecs.NewWorld()
+
+	if _, ok := ecs.GetResource[*score](world); ok {
+		t.Error("expected no resource before one is set")
+	}
+
+	world.SetResource(&score{points: 10})
+	world.SetResource("not a score")
+
+	s, ok := ecs.GetResource[*score](world)
+	if !ok || s.points != 10 {
+		t.Errorf("expected the score resource, got %v %v", s, ok)
+	}
+
+	// setting another of the same type replaces it
+	world.SetResource(&score{points: 20})
+	if s, _ := ecs.GetResource[*score](world); s.points != 20 {
+		t.Errorf("expected the score to be replaced, got %d", s.points)
+	}
+
+	if name, ok := ecs.GetResource[string](world); !ok || name != "not a score" {
+		t.Errorf("expected the string resource, got %q %v", name, ok)
+	}
+}
+
 func TestGetEntity(t *testing.T) {
 	// Test that the GetEntity function works
 
@@ -275,18 +442,633 @@ func TestAddRenderSystem(t *testing.T) {
 	}
 }
 
-// Update updates the system.
-func (sys *TestSystemMovement) Update(deltaTime time.Duration) {
-	sys.world.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
-		location := ecs.GetComponentID[*component.Location](sys.world, components["location"])
-		movable := ecs.GetComponentID[*component.Move](sys.world, components["move"])
+func TestWorld_IterateComponentsParallel(t *testing.T) {
+	// Test that every entity is visited exactly once, and that the result is
+	// the same as iterating sequentially.
+
+	ecstest.Quiet(t)
+
+	world := ecs.NewWorld()
+	sys := &TestSystemParallel{Work: 100}
+	world.AddSystem(sys)
+
+	const entityCount = 1000
+	entities := make([]ecs.EntityID, entityCount)
+	for i := range entities {
+		entities[i] = world.AddEntity(&TestEntityWithComponents{})
+
+		movable := ecs.GetComponent[*ecstest.Velocity](world, entities[i])
+		movable.X = i
+		movable.Y = -i
+	}
+
+	world.Update(1)
+
+	if sys.calls.Load() != entityCount {
+		t.Fatalf("expected %d calls, got %d", entityCount, sys.calls.Load())
+	}
+
+	for i, entityID := range entities {
+		location := ecs.GetComponent[*ecstest.Position](world, entityID)
+		if location.X != 1+i || location.Y != 1-i {
+			t.Errorf("entity %d should be at %d, %d, got %d, %d", i, 1+i, 1-i, location.X, location.Y)
+		}
+	}
+}
+
+func TestWorld_ReplaceComponent(t *testing.T) {
+	// Test that replacing a component keeps its ID and the iteration order,
+	// and that replacing a missing component adds it
+
+	world := ecs.NewWorld()
+	sys := &ecstest.Mover{}
+	world.AddSystem(sys)
+
+	entities := make([]ecs.EntityID, 3)
+	for i := range entities {
+		entities[i] = world.AddEntity(&TestEntityWithComponents{})
+	}
+
+	order := func() []ecs.ComponentID {
+		ids := make([]ecs.ComponentID, 0)
+		world.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
+			ids = append(ids, components["position"])
+		})
+		return ids
+	}
+
+	before := order()
+	oldIDs := world.GetComponentIDsForEntity(entities[1])
+
+	replacement := &ecstest.Position{X: 7, Y: 8}
+	world.ReplaceComponent(entities[1], replacement)
+
+	if location := ecs.GetComponent[*ecstest.Position](world, entities[1]); location != replacement {
+		t.Error("The entity should have the new component")
+	}
+
+	if len(world.GetComponentIDsForEntity(entities[1])) != len(oldIDs) {
+		t.Error("Replacing a component should not add a new one")
+	}
+
+	after := order()
+	for i := range before {
+		if before[i] != after[i] {
+			t.Fatalf("The iteration order should not change, got %v then %v", before, after)
+		}
+	}
+
+	world.ReplaceComponent(entities[1], &ecstest.Tag{})
+	if !world.HasComponent(entities[1], &ecstest.Tag{}) {
+		t.Error("Replacing a missing component should add it")
+	}
+}
+
+func TestWorld_DeterministicOrder(t *testing.T) {
+	// Test that entities are always visited in order of their IDs, even after
+	// entities are removed or components are added to existing entities
+
+	run := func() []ecs.EntityID {
+		world := ecs.NewWorld()
+		sys := &ecstest.Mover{}
+		world.AddSystem(sys)
+
+		late := world.AddEntity(&TestEntityWithNoComponents{})
+		entities := make([]ecs.EntityID, 0)
+		for i := 0; i < 5; i++ {
+			entities = append(entities, world.AddEntity(&TestEntityWithComponents{}))
+		}
+		world.RemoveEntity(entities[1])
+		world.AddEntity(&TestEntityWithComponents{})
+
+		world.AddComponent(late, &ecstest.Position{})
+		world.AddComponent(late, &ecstest.Velocity{})
+
+		visited := make([]ecs.EntityID, 0)
+		world.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
+			owner := world.EntityForComponent(components["position"])
+			if world.EntityForComponent(components["velocity"]) != owner {
+				t.Errorf("the components passed together should belong to the same entity")
+			}
+			visited = append(visited, owner)
+		})
+
+		expected := world.EntitiesForSystem(sys)
+		if len(visited) != len(expected) {
+			t.Fatalf("expected to visit %v, got %v", expected, visited)
+		}
+		for i := range expected {
+			if i > 0 && expected[i-1] >= expected[i] {
+				t.Errorf("EntitiesForSystem should be sorted, got %v", expected)
+			}
+			if visited[i] != expected[i] {
+				t.Errorf("expected to visit %v, got %v", expected, visited)
+			}
+		}
+
+		return visited
+	}
+
+	first := run()
+	for i := 0; i < 10; i++ {
+		again := run()
+		for j := range first {
+			if again[j] != first[j] {
+				t.Fatalf("the order changed between runs: %v then %v", first, again)
+			}
+		}
+	}
+}
+
+func TestWorld_ChangedThisFrame(t *testing.T) {
+	world := ecs.NewWorld()
+	e1 := world.AddEntity(&TestEntityWithComponents{})
+	e2 := world.AddEntity(&TestEntityWithComponents{})
+
+	// adding a component counts as changing it
+	if changed := world.ChangedThisFrame("position"); len(changed) != 2 || changed[0] != e1 || changed[1] != e2 {
+		t.Errorf("expected both new entities to have changed, got %v", changed)
+	}
+
+	world.Update(1)
+	if changed := world.ChangedThisFrame("position"); len(changed) != 0 {
+		t.Errorf("expected nothing to have changed in a new frame, got %v", changed)
+	}
+
+	world.MarkChanged(e2, "position")
+	if changed := world.ChangedThisFrame("position"); len(changed) != 1 || changed[0] != e2 {
+		t.Errorf("expected only the marked entity to have changed, got %v", changed)
+	}
+	if changed := world.ChangedThisFrame("velocity"); len(changed) != 0 {
+		t.Errorf("expected other components not to have changed, got %v", changed)
+	}
+
+	world.RemoveEntity(e2)
+	if changed := world.ChangedThisFrame("position"); len(changed) != 0 {
+		t.Errorf("expected removed entities to be forgotten, got %v", changed)
+	}
+}
+
+func TestWorld_Turn(t *testing.T) {
+	// Test that the turn counter only moves when a turn is ended
+
+	world := ecstest.NewWorld(t, &ecstest.Mover{})
+
+	if world.Turn() != 0 {
+		t.Fatalf("The world should start on turn 0, got %d", world.Turn())
+	}
+
+	world.Update(1)
+	world.Update(1)
+
+	if world.Turn() != 0 {
+		t.Errorf("Updating the world should not end the turn, got %d", world.Turn())
+	}
+
+	world.EndTurn()
+	world.EndTurn()
+
+	if world.Turn() != 2 {
+		t.Errorf("The world should be on turn 2, got %d", world.Turn())
+	}
+}
+
+func TestWorld_RemoveEntity(t *testing.T) {
+	// Test that removing an entity removes it from the world and from the
+	// systems, without disturbing other entities
 
-		// move the entity
-		location.X += movable.X
-		location.Y += movable.Y
+	world := ecstest.NewWorld(t, &ecstest.Mover{})
 
-		// reset the movable component
-		movable.X = 0
-		movable.Y = 0
+	player := world.AddEntity(testPlayer)
+	mob := world.AddEntity(testMob)
+
+	world.RemoveEntity(mob)
+
+	if world.EntityCount() != 1 || world.GetEntity(mob) != nil {
+		t.Fatal("The mob should have been removed")
+	}
+
+	if len(world.GetComponentIDsForEntity(mob)) != 0 {
+		t.Error("The mob's components should have been removed")
+	}
+
+	if entities := world.EntitiesForSystem(&ecstest.Mover{}); len(entities) != 1 || entities[0] != player {
+		t.Errorf("Only the player should be left, got %v", entities)
+	}
+
+	movable := ecs.GetComponent[*ecstest.Velocity](world, player)
+	movable.X = 1
+
+	world.Update(1)
+
+	if location := ecs.GetComponent[*ecstest.Position](world, player); location.X != 1 {
+		t.Errorf("The player should still move, got %d", location.X)
+	}
+}
+
+func TestWorld_RemoveComponent(t *testing.T) {
+	// Test that removing a component takes the entity out of the systems
+	// that need it, and leaves the rest of the entity alone
+
+	world := ecstest.NewWorld(t, &ecstest.Mover{})
+	ecstest.Quiet(t)
+
+	player := world.AddEntity(testPlayer)
+	world.RemoveComponent(player, &ecstest.Velocity{})
+
+	if world.HasComponent(player, &ecstest.Velocity{}) {
+		t.Error("the velocity should have been removed")
+	}
+	if !world.HasComponent(player, &ecstest.Position{}) {
+		t.Error("the position should have been left alone")
+	}
+	if entities := world.EntitiesForSystem(&ecstest.Mover{}); len(entities) != 0 {
+		t.Errorf("the mover should no longer see the player, got %v", entities)
+	}
+
+	// removing it again just warns
+	world.RemoveComponent(player, &ecstest.Velocity{})
+
+	world.AddComponent(player, &ecstest.Velocity{X: 1})
+	world.Update(1)
+	if location := ecs.GetComponent[*ecstest.Position](world, player); location.X != 1 {
+		t.Errorf("the player should move once the velocity is added back, got %d", location.X)
+	}
+}
+
+func TestWorld_IterateComponentsWhileChanging(t *testing.T) {
+	// Test that the callback can add and remove entities and components
+	// without any entity being skipped or visited twice
+
+	ecstest.Quiet(t)
+
+	visited := make([]ecs.EntityID, 0)
+	var entities []ecs.EntityID
+
+	sys := &ecstest.System{
+		Wants: []ecs.Component{&ecstest.Position{}, &ecstest.Velocity{}},
+		Each: func(world *ecs.World, components map[ecs.ComponentName]ecs.ComponentID) {
+			entityID := world.EntityForComponent(components["position"])
+			visited = append(visited, entityID)
+
+			switch entityID {
+			case entities[0]:
+				// the current entity loses a component it was visited for
+				world.RemoveComponent(entityID, &ecstest.Velocity{})
+			case entities[1]:
+				// a later entity is removed before it's reached
+				world.RemoveEntity(entities[2])
+				world.AddEntity(testMob)
+			}
+		},
+	}
+	world := ecstest.NewWorld(t, sys)
+
+	for i := 0; i < 4; i++ {
+		entities = append(entities, world.AddEntity(testMob))
+	}
+
+	world.Update(1)
+
+	expected := []ecs.EntityID{entities[0], entities[1], entities[3]}
+	if !slices.Equal(visited, expected) {
+		t.Errorf("expected to visit %v, got %v", expected, visited)
+	}
+
+	// the entity added during the update is visited on the next one, and
+	// the one that lost its velocity isn't
+	visited = visited[:0]
+	world.Update(1)
+	if len(visited) != 3 || visited[0] != entities[1] {
+		t.Errorf("expected to visit the two remaining entities and the new one, got %v", visited)
+	}
+}
+
+func TestWorld_Pool(t *testing.T) {
+	// Test that pooled components are copied from the prototype, and reset
+	// when they are returned to the pool
+
+	world := ecs.NewWorld()
+	world.AddPool(ecs.NewComponentPool[ecstest.Position]())
+	world.AddPool(ecs.NewComponentPool[ecstest.Velocity]())
+
+	first := world.AddEntity(&TestPooledProjectile{})
+	second := world.AddEntity(&TestPooledProjectile{})
+
+	location := ecs.GetComponent[*ecstest.Position](world, first)
+	if location == testProjectilePrototype[0] {
+		t.Fatal("The entity should not share the prototype component")
+	}
+
+	if location.X != 1 || location.Y != 1 {
+		t.Errorf("The component should be copied from the prototype, got %d, %d", location.X, location.Y)
+	}
+
+	location.X = 10
+	if other := ecs.GetComponent[*ecstest.Position](world, second); other.X != 1 {
+		t.Errorf("Entities should not share components, got %d", other.X)
+	}
+
+	world.RemoveEntity(first)
+	if location.X != 0 || location.Y != 0 {
+		t.Errorf("The component should be reset when it's removed, got %d, %d", location.X, location.Y)
+	}
+}
+
+// BenchmarkSpawnDespawn creates and removes 10,000 projectiles per frame, with
+// and without component pools.
+func BenchmarkSpawnDespawn(b *testing.B) {
+	ecstest.Quiet(b)
+
+	const projectiles = 10000
+
+	for _, pooled := range []bool{false, true} {
+		name := "unpooled"
+		if pooled {
+			name = "pooled"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			world := ecstest.NewWorld(b, &ecstest.Mover{})
+
+			var projectile ecs.Entity = &TestProjectile{}
+			if pooled {
+				world.AddPool(ecs.NewComponentPool[ecstest.Position]())
+				world.AddPool(ecs.NewComponentPool[ecstest.Velocity]())
+				projectile = &TestPooledProjectile{}
+			}
+
+			entities := make([]ecs.EntityID, projectiles)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for j := range entities {
+					entities[j] = world.AddEntity(projectile)
+				}
+
+				world.Update(1)
+
+				for j := len(entities) - 1; j >= 0; j-- {
+					world.RemoveEntity(entities[j])
+				}
+			}
+		})
+	}
+}
+
+func TestWorld_Rand(t *testing.T) {
+	// Test that two worlds with the same seed produce the same numbers
+
+	world1 := ecs.NewWorld()
+	world2 := ecs.NewWorld()
+	world1.SetSeed(42)
+	world2.SetSeed(42)
+
+	if world1.Seed() != 42 {
+		t.Errorf("The seed should be 42, got %d", world1.Seed())
+	}
+
+	for i := 0; i < 10; i++ {
+		if a, b := world1.Rand().Int63(), world2.Rand().Int63(); a != b {
+			t.Fatalf("Worlds with the same seed should match, got %d and %d", a, b)
+		}
+	}
+}
+
+func TestWorld_SaveLoad(t *testing.T) {
+	ecstest.Quiet(t)
+
+	world := ecs.NewWorld()
+	world.SetSeed(7)
+	world.AddSystem(&ecstest.Mover{})
+
+	player := world.AddEntity(testPlayer)
+	mob := world.AddEntity(testMob)
+	world.RemoveEntity(world.AddEntity(testMob))
+	ecs.GetComponent[*ecstest.Position](world, mob).X = 12
+	world.AddComponent(player, &ecstest.Tag{})
+	world.EndTurn()
+	world.EndTurn()
+	for i := 0; i < 3; i++ {
+		world.Rand().Intn(100)
+	}
+
+	data, err := json.Marshal(world)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a world that doesn't know about the entities can't load them
+	err = json.Unmarshal(data, ecs.NewWorld())
+	if !errors.Is(err, ecs.ErrUnknownEntity) {
+		t.Errorf("expected ErrUnknownEntity, got %v", err)
+	}
+
+	loaded := ecs.NewWorld()
+	sys := &ecstest.Mover{}
+	loaded.AddSystem(sys)
+	loaded.RegisterEntities(testPlayer, testMob)
+	loaded.AddEntity(testMob) // replaced by the load
+
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Dump() != world.Dump() {
+		t.Errorf("expected the loaded world to match the saved one, got\n%s\nexpected\n%s", loaded.Dump(), world.Dump())
+	}
+
+	if loaded.Turn() != 2 {
+		t.Errorf("expected turn 2, got %d", loaded.Turn())
+	}
+
+	if a, b := world.Rand().Int63(), loaded.Rand().Int63(); a != b {
+		t.Errorf("expected the random numbers to carry on where they left off, got %d and %d", b, a)
+	}
+
+	if a, b := world.AddEntity(testMob), loaded.AddEntity(testMob); a != b {
+		t.Errorf("expected new entities to carry on from the same ID, got %d and %d", b, a)
+	}
+
+	// the systems see the loaded entities
+	count := 0
+	loaded.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
+		count++
 	})
+	if count != 3 {
+		t.Errorf("expected the system to see 3 entities, got %d", count)
+	}
+}
+
+func TestWorld_StaleEntityID(t *testing.T) {
+	// Test that an entity's slot is reused once it's removed, but that the
+	// old ID doesn't refer to the entity that took its place
+
+	world := ecstest.NewWorld(t, &ecstest.Mover{})
+	ecstest.Quiet(t)
+
+	player := world.AddEntity(testPlayer)
+	mob := world.AddEntity(testMob)
+	world.RemoveEntity(mob)
+
+	replacement := world.AddEntity(testMob)
+	if replacement.Index() != mob.Index() {
+		t.Errorf("expected the mob's slot %d to be reused, got %d", mob.Index(), replacement.Index())
+	}
+	if replacement == mob || replacement.Generation() != mob.Generation()+1 {
+		t.Errorf("expected the reused slot to be a new generation, got %d then %d", mob, replacement)
+	}
+
+	if world.GetEntity(mob) != nil {
+		t.Error("GetEntity should return nil for a removed entity, not the one that took its place")
+	}
+	if world.HasComponent(mob, &ecstest.Position{}) {
+		t.Error("a removed entity should have no components")
+	}
+	if world.GetEntity(replacement) == nil || world.GetEntity(player) == nil {
+		t.Error("the live entities should still be there")
+	}
+
+	// removing it again through the stale ID must leave the new one alone
+	world.RemoveEntity(mob)
+	if world.GetEntity(replacement) == nil {
+		t.Error("removing a stale ID should not remove the entity that took its place")
+	}
+	if next := world.AddEntity(testMob); next.Index() == replacement.Index() {
+		t.Errorf("a live entity's slot should not be handed out, got %d", next)
+	}
+}
+
+func TestWorld_EntityIDsAreSeparate(t *testing.T) {
+	// Test that entity IDs come from their own sequence, rather than being
+	// shared with components
+
+	world := ecs.NewWorld()
+	ecstest.Quiet(t)
+
+	first := world.AddEntity(testPlayer)
+	second := world.AddEntity(testMob)
+
+	if first == 0 {
+		t.Error("the zero EntityID should never be used")
+	}
+	if second != first+1 {
+		t.Errorf("expected consecutive entity IDs, got %d and %d", first, second)
+	}
+	if ids := world.GetComponentIDsForEntity(first); len(ids) == 0 || ids[0] != 1 {
+		t.Errorf("expected components to be numbered from 1 on their own, got %v", ids)
+	}
+}
+
+func TestWorld_SaveLoadReusedIDs(t *testing.T) {
+	// Test that a save game remembers which slots are free and which
+	// generation each one is on
+
+	world := ecs.NewWorld()
+	ecstest.Quiet(t)
+
+	player := world.AddEntity(testPlayer)
+	stale := world.AddEntity(testMob)
+	world.RemoveEntity(stale)
+	world.RemoveEntity(world.AddEntity(testMob))
+
+	data, err := json.Marshal(world)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := ecs.NewWorld()
+	loaded.RegisterEntities(testPlayer, testMob)
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.GetEntity(player) == nil || loaded.GetEntity(stale) != nil {
+		t.Error("expected only the player to be loaded")
+	}
+
+	for i := 0; i < 3; i++ {
+		if a, b := world.AddEntity(testMob), loaded.AddEntity(testMob); a != b {
+			t.Errorf("expected new entities to get the same IDs after loading, got %d and %d", b, a)
+		}
+	}
+}
+
+// BenchmarkChangedThisFrame compares visiting every entity each frame with
+// visiting only the ones that changed, for a world where only 1% of the
+// entities move each frame.
+func BenchmarkChangedThisFrame(b *testing.B) {
+	ecstest.Quiet(b)
+
+	const (
+		entityCount = 10000
+		moving      = 100
+	)
+
+	for _, onlyChanged := range []bool{false, true} {
+		name := "all"
+		if onlyChanged {
+			name = "changed"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			world := ecs.NewWorld()
+			sys := &ecstest.Mover{}
+			world.AddSystem(sys)
+
+			entities := make([]ecs.EntityID, entityCount)
+			for i := range entities {
+				entities[i] = world.AddEntity(&TestEntityWithComponents{})
+			}
+
+			total := 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				world.Update(1)
+
+				for j := 0; j < moving; j++ {
+					world.MarkChanged(entities[(i*moving+j)%entityCount], "position")
+				}
+
+				if onlyChanged {
+					for _, entityID := range world.ChangedThisFrame("position") {
+						total += ecs.GetComponent[*ecstest.Position](world, entityID).X
+					}
+				} else {
+					world.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
+						total += ecs.GetComponentID[*ecstest.Position](world, components["position"]).X
+					})
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkIterateComponents compares IterateComponents with
+// IterateComponentsParallel for a system with an artificial per-entity
+// workload.
+func BenchmarkIterateComponents(b *testing.B) {
+	ecstest.Quiet(b)
+
+	for _, sequential := range []bool{true, false} {
+		name := "parallel"
+		if sequential {
+			name = "sequential"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			world := ecs.NewWorld()
+			world.AddSystem(&TestSystemParallel{Sequential: sequential, Work: 10000})
+
+			for i := 0; i < 500; i++ {
+				world.AddEntity(&TestEntityWithComponents{})
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				world.Update(1)
+			}
+		})
+	}
 }
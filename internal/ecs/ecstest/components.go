@@ -0,0 +1,60 @@
+package ecstest
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/matjam/sword/internal/ecs"
+)
+
+// Ensure that we're implementing the ecs.Component interface.
+var (
+	_ = ecs.Component(&Position{})
+	_ = ecs.Component(&Velocity{})
+	_ = ecs.Component(&Counter{})
+	_ = ecs.Component(&Sprite{})
+	_ = ecs.Component(&Tag{})
+)
+
+// Position is a mock component for where an entity is.
+type Position struct {
+	X, Y int
+}
+
+func (*Position) ComponentName() ecs.ComponentName {
+	return "position"
+}
+
+// Velocity is a mock component for how far an entity moves in an update. The
+// Mover system adds it to the entity's Position.
+type Velocity struct {
+	X, Y int
+}
+
+func (*Velocity) ComponentName() ecs.ComponentName {
+	return "velocity"
+}
+
+// Counter is a mock component holding a single number, for anything a test
+// wants to count or use up, like hit points.
+type Counter struct {
+	N int
+}
+
+func (*Counter) ComponentName() ecs.ComponentName {
+	return "counter"
+}
+
+// Sprite is a mock component with an image, which isn't saved with the world.
+type Sprite struct {
+	Image *ebiten.Image `json:"-"`
+}
+
+func (*Sprite) ComponentName() ecs.ComponentName {
+	return "sprite"
+}
+
+// Tag is a mock component with no data, for marking entities.
+type Tag struct{}
+
+func (*Tag) ComponentName() ecs.ComponentName {
+	return "tag"
+}
@@ -0,0 +1,62 @@
+// Package ecstest helps test code that is built on the ecs package, without
+// pulling in the game's own components and entities.
+//
+// NewWorld returns a World that is ready to use in a test, seeded with Seed
+// so that anything random, such as combat rolls or wandering mobs, plays out
+// the same way on every run. The package also has mock components, an Entity
+// and a System that can be put together however a test needs, so a system
+// can be tested against a real World with only the components it cares
+// about.
+package ecstest
+
+import (
+	"io"
+	"log/slog"
+	"math/rand"
+	"testing"
+
+	"github.com/matjam/sword/internal/ecs"
+)
+
+// Seed is the seed every World returned by NewWorld starts with.
+const Seed int64 = 42
+
+// NewWorld returns a new World seeded with Seed, with the given systems
+// added. The world's random number generator is also set as a *rand.Rand
+// resource, so code that takes its randomness with
+// ecs.GetResource[*rand.Rand] gets the same seeded numbers as World.Rand. The
+// test fails straight away if a system can't be added.
+func NewWorld(tb testing.TB, systems ...ecs.System) *ecs.World {
+	tb.Helper()
+
+	world := ecs.NewWorld()
+	world.SetSeed(Seed)
+	world.SetResource(world.Rand())
+
+	if err := world.AddSystems(systems...); err != nil {
+		tb.Fatalf("adding systems: %v", err)
+	}
+
+	return world
+}
+
+// Rand returns the random number generator stored in the world by NewWorld,
+// failing the test if there isn't one.
+func Rand(tb testing.TB, world *ecs.World) *rand.Rand {
+	tb.Helper()
+
+	r, ok := ecs.GetResource[*rand.Rand](world)
+	if !ok {
+		tb.Fatal("the world has no *rand.Rand resource")
+	}
+	return r
+}
+
+// Quiet discards everything logged with slog until the test finishes. The
+// World logs every entity it adds and removes, which drowns out the test's
+// own output when there are a lot of them.
+func Quiet(tb testing.TB) {
+	previous := slog.Default()
+	tb.Cleanup(func() { slog.SetDefault(previous) })
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelWarn})))
+}
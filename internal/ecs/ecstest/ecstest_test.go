@@ -0,0 +1,84 @@
+package ecstest_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/ecstest"
+)
+
+func TestNewWorld(t *testing.T) {
+	// Test that every world starts with the same seed, and that the resource
+	// is the world's own generator
+
+	world1 := ecstest.NewWorld(t)
+	world2 := ecstest.NewWorld(t)
+
+	if world1.Seed() != ecstest.Seed {
+		t.Errorf("expected the seed to be %d, got %d", ecstest.Seed, world1.Seed())
+	}
+
+	if r := ecstest.Rand(t, world1); r != world1.Rand() {
+		t.Error("expected the resource to be the world's generator")
+	}
+
+	for i := 0; i < 10; i++ {
+		if a, b := ecstest.Rand(t, world1).Intn(100), world2.Rand().Intn(100); a != b {
+			t.Fatalf("expected the same rolls from both worlds, got %d and %d", a, b)
+		}
+	}
+
+	if _, ok := ecs.GetResource[*rand.Rand](ecs.NewWorld()); ok {
+		t.Error("expected a plain world to have no generator resource")
+	}
+}
+
+func TestSystem(t *testing.T) {
+	// Test that the mock system visits the entities it wants, and that Mover
+	// moves them
+
+	ecstest.Quiet(t)
+
+	visited := make([]ecs.EntityID, 0)
+	counting := &ecstest.System{
+		Name:  "counting",
+		Wants: []ecs.Component{&ecstest.Counter{}},
+		Each: func(world *ecs.World, components map[ecs.ComponentName]ecs.ComponentID) {
+			ecs.GetComponentID[*ecstest.Counter](world, components["counter"]).N--
+			visited = append(visited, world.EntityForComponent(components["counter"]))
+		},
+	}
+	world := ecstest.NewWorld(t, counting, &ecstest.Mover{})
+
+	mover := world.AddEntity(&ecstest.Entity{
+		Components: func() []ecs.Component {
+			return []ecs.Component{&ecstest.Position{X: 1}, &ecstest.Velocity{X: 2, Y: 3}, &ecstest.Counter{N: 5}}
+		},
+	})
+	world.AddEntity(&ecstest.Entity{Name: "empty"})
+
+	world.Update(1)
+	world.Update(1)
+
+	if counting.Updates != 2 {
+		t.Errorf("expected 2 updates, got %d", counting.Updates)
+	}
+
+	if len(visited) != 2 || visited[0] != mover || visited[1] != mover {
+		t.Errorf("expected only the mover to be visited, got %v", visited)
+	}
+
+	if counter := ecs.GetComponent[*ecstest.Counter](world, mover); counter.N != 3 {
+		t.Errorf("expected the counter to be 3, got %d", counter.N)
+	}
+
+	position := ecs.GetComponent[*ecstest.Position](world, mover)
+	if position.X != 3 || position.Y != 3 {
+		t.Errorf("expected the mover to move once, to 3, 3, got %d, %d", position.X, position.Y)
+	}
+
+	if names := world.EntitiesNamed(ecstest.DefaultEntityName); len(names) != 1 || names[0] != mover {
+		t.Errorf("expected an unnamed entity to get the default name, got %v", names)
+	}
+}
@@ -0,0 +1,47 @@
+package ecstest
+
+import "github.com/matjam/sword/internal/ecs"
+
+// Ensure that we're implementing the ecs.Entity interface.
+var _ = ecs.Entity(&Entity{})
+
+// DefaultEntityName is the name of an Entity that doesn't have one.
+const DefaultEntityName = "mock"
+
+// Entity is a mock entity made of whatever components a test gives it. For
+// example:
+//
+//	mover := &ecstest.Entity{
+//		Name: "mover",
+//		Components: func() []ecs.Component {
+//			return []ecs.Component{&ecstest.Position{}, &ecstest.Velocity{X: 1}}
+//		},
+//	}
+//	id := world.AddEntity(mover)
+type Entity struct {
+	// Name is the entity's name. If it is empty, DefaultEntityName is used.
+	Name ecs.EntityName
+
+	// Components returns the components of a new entity. It is called every
+	// time the entity is added to a world, so it should return new
+	// components each time, unless they are pooled. If it is nil, the entity
+	// has no components.
+	Components func() []ecs.Component
+}
+
+// EntityName returns the entity's name.
+func (e *Entity) EntityName() ecs.EntityName {
+	if e.Name == "" {
+		return DefaultEntityName
+	}
+	return e.Name
+}
+
+// New returns a copy of the entity, and the components from Components.
+func (e *Entity) New() (ecs.Entity, []ecs.Component) {
+	components := []ecs.Component{}
+	if e.Components != nil {
+		components = e.Components()
+	}
+	return &Entity{Name: e.Name, Components: e.Components}, components
+}
@@ -0,0 +1,107 @@
+package ecstest
+
+import (
+	"time"
+
+	"github.com/matjam/sword/internal/ecs"
+)
+
+// Ensure that we're implementing the ecs.System interface.
+var (
+	_ = ecs.System(&System{})
+	_ = ecs.System(&Mover{})
+)
+
+// DefaultSystemName is the name of a System that doesn't have one.
+const DefaultSystemName = "mock"
+
+// System is a mock system that calls Each for every entity that has all of
+// the components in Wants, and counts how many times it has been updated.
+type System struct {
+	// Name is the system's name. If it is empty, DefaultSystemName is used.
+	// Worlds only hold one system of each name, so two Systems in the same
+	// world need different names.
+	Name ecs.SystemName
+	// Wants is the components the system is interested in.
+	Wants []ecs.Component
+	// Each is called with the components of every entity the system is
+	// interested in, each time it is updated. It can be nil.
+	Each func(world *ecs.World, components map[ecs.ComponentName]ecs.ComponentID)
+
+	// World is the world the system was added to, set by Init.
+	World *ecs.World
+	// Updates is the number of times Update has been called.
+	Updates int
+}
+
+// Init initializes the system.
+func (sys *System) Init(world *ecs.World) {
+	sys.World = world
+}
+
+// SystemName returns the name of the system.
+func (sys *System) SystemName() ecs.SystemName {
+	if sys.Name == "" {
+		return DefaultSystemName
+	}
+	return sys.Name
+}
+
+// Components returns the components that the system is interested in.
+func (sys *System) Components() []ecs.Component {
+	if sys.Wants == nil {
+		return []ecs.Component{}
+	}
+	return sys.Wants
+}
+
+// Update calls Each for every entity the system is interested in.
+func (sys *System) Update(deltaTime time.Duration) {
+	sys.Updates++
+
+	if sys.Each == nil {
+		return
+	}
+
+	sys.World.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
+		sys.Each(sys.World, components)
+	})
+}
+
+// Mover is a mock system that adds each entity's Velocity to its Position,
+// and then stops it.
+type Mover struct {
+	world *ecs.World
+}
+
+// Init initializes the system.
+func (sys *Mover) Init(world *ecs.World) {
+	sys.world = world
+}
+
+// SystemName returns the name of the system.
+func (*Mover) SystemName() ecs.SystemName {
+	return "mover"
+}
+
+// Components returns the components that the system is interested in.
+func (*Mover) Components() []ecs.Component {
+	return []ecs.Component{
+		&Velocity{},
+		&Position{},
+	}
+}
+
+// Update moves every entity by its Velocity.
+func (sys *Mover) Update(deltaTime time.Duration) {
+	sys.world.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
+		position := ecs.GetComponentID[*Position](sys.world, components["position"])
+		velocity := ecs.GetComponentID[*Velocity](sys.world, components["velocity"])
+
+		position.X += velocity.X
+		position.Y += velocity.Y
+
+		velocity.X = 0
+		velocity.Y = 0
+	})
+}
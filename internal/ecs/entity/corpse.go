@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"image/color"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+)
+
+// Corpse is the body left behind when something dies. It keeps whatever the
+// dead entity was carrying.
+type Corpse struct{}
+
+func (*Corpse) EntityName() ecs.EntityName {
+	return "corpse"
+}
+
+// New returns the corpse entity and its components.
+func (*Corpse) New() (ecs.Entity, []ecs.Component) {
+	return &Corpse{}, []ecs.Component{
+		&component.Location{},
+		&component.Render{
+			Glyph: '%',
+			Color: color.RGBA{R: 160, G: 32, B: 32, A: 255},
+			Layer: component.LayerFloor,
+		},
+		&component.Inventory{},
+		&component.Description{
+			Short: "a corpse",
+			Long:  "It might still have something on it.",
+		},
+	}
+}
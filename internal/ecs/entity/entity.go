@@ -0,0 +1,14 @@
+package entity
+
+import "github.com/matjam/sword/internal/ecs"
+
+// All returns one of every type of entity in this package. A world has to be
+// told about them with RegisterEntities before a save game is loaded into it,
+// so any new type of entity needs adding here.
+func All() []ecs.Entity {
+	return []ecs.Entity{
+		&Player{},
+		&Mob{},
+		&Corpse{},
+	}
+}
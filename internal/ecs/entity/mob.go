@@ -24,5 +24,12 @@ func (*Mob) New() (ecs.Entity, []ecs.Component) {
 			Max:     100,
 		},
 		&component.Inventory{},
+		&component.HealthBar{},
+		&component.Description{
+			Short: "a monster",
+		},
+		&component.Blocker{},
+		&component.Reward{Experience: 10},
+		&component.Vision{},
 	}
 }
@@ -23,10 +23,23 @@ func (*Player) New() (ecs.Entity, []ecs.Component) {
 			Glyph: '☺',
 			Color: color.RGBA{R: 64, G: 255, B: 64, A: 255},
 		},
+		&component.Damage{},
 		&component.Health{
 			Current: 100,
 			Max:     100,
 		},
 		&component.Inventory{},
+		&component.Encumbrance{},
+		&component.Description{
+			Short: "yourself",
+		},
+		&component.Cooldowns{},
+		&component.Blocker{},
+		&component.Experience{},
+		// the player carries a torch
+		&component.LightSource{
+			Radius:    8,
+			Intensity: 255,
+		},
 	}
 }
@@ -0,0 +1,102 @@
+package ecs
+
+// An EntityID is made of two parts: the index of the slot the entity lives
+// in, and the generation of that slot. When an entity is removed its slot is
+// handed out again to a later entity, but with the generation bumped, so the
+// new entity gets a different EntityID. Anything still holding the old ID,
+// such as a system that was chasing a mob that has since died, finds nothing
+// there rather than the new entity.
+//
+// The index is in the low bits and the generation in the high bits, so the
+// first entity to use each slot has an ID equal to its index.
+const (
+	entityIndexBits = 20
+	entityIndexMask = 1<<entityIndexBits - 1
+
+	// maxGeneration is the highest generation a slot can reach. A slot that
+	// gets there is retired rather than wrapping around, since an ID that
+	// wrapped could match a stale reference again.
+	maxGeneration = 1<<(32-entityIndexBits) - 1
+)
+
+// Index returns the index of the slot the entity lives in.
+func (id EntityID) Index() uint32 {
+	return uint32(id) & entityIndexMask
+}
+
+// Generation returns how many entities have used the entity's slot before
+// it.
+func (id EntityID) Generation() uint32 {
+	return uint32(id) >> entityIndexBits
+}
+
+// newEntityID makes an EntityID from a slot index and generation.
+func newEntityID(index, generation uint32) EntityID {
+	return EntityID(generation<<entityIndexBits | index&entityIndexMask)
+}
+
+// entityAllocator hands out EntityIDs, reusing the slots of entities that
+// have been removed. Slot 0 is never used, so the zero EntityID never refers
+// to an entity.
+type entityAllocator struct {
+	// generations holds the generation of each slot, which is the generation
+	// of the entity in it, or of the next one if it's free.
+	generations []uint16
+
+	// free holds the slots that can be reused, oldest first. Slots are
+	// reused in the order they were freed, so that a slot goes as long as it
+	// can between entities and a stale ID is as unlikely as possible to have
+	// wrapped around to a live one.
+	free []uint32
+}
+
+// alloc returns the EntityID for a new entity.
+func (a *entityAllocator) alloc() EntityID {
+	if len(a.generations) == 0 {
+		a.generations = append(a.generations, 0)
+	}
+
+	if len(a.free) > 0 {
+		index := a.free[0]
+		a.free = a.free[1:]
+		return newEntityID(index, uint32(a.generations[index]))
+	}
+
+	index := uint32(len(a.generations))
+	if index > entityIndexMask {
+		panic("ecs: out of entity IDs")
+	}
+	a.generations = append(a.generations, 0)
+	return newEntityID(index, 0)
+}
+
+// release frees the slot of a removed entity, so that it can be reused.
+func (a *entityAllocator) release(id EntityID) {
+	index := id.Index()
+	if int(index) >= len(a.generations) || uint32(a.generations[index]) != id.Generation() {
+		return
+	}
+
+	a.generations[index]++
+	if a.generations[index] > maxGeneration {
+		return
+	}
+	a.free = append(a.free, index)
+}
+
+// reserve makes sure the slot for id is in use, for entities that are added
+// with an ID they already have, such as when loading a save game. Slots
+// before it that nothing has used yet are freed for later entities.
+func (a *entityAllocator) reserve(id EntityID) {
+	index := id.Index()
+	if len(a.generations) == 0 {
+		a.generations = append(a.generations, 0)
+	}
+	for uint32(len(a.generations)) <= index {
+		a.free = append(a.free, uint32(len(a.generations)))
+		a.generations = append(a.generations, 0)
+	}
+
+	a.generations[index] = uint16(id.Generation())
+	a.free = removeValue(a.free, index)
+}
@@ -0,0 +1,68 @@
+package ecs
+
+import "sync"
+
+// Pool is a pool of components of a single type. Pools are opt-in, and are
+// registered with World.AddPool. Once a pool is registered, AddEntity copies
+// each component of that type returned by Entity.New into a component taken
+// from the pool, and RemoveEntity resets the component and returns it to the
+// pool.
+//
+// This cuts down on garbage when lots of short-lived entities, such as
+// projectiles or particles, are created and destroyed every frame. To get the
+// full benefit, the entity's New method should return the same prototype
+// components every time instead of allocating new ones. That is only safe for
+// components that have a pool, since without a pool every entity would end up
+// sharing the prototype.
+type Pool interface {
+	// ComponentName returns the name of the component type in the pool.
+	ComponentName() ComponentName
+
+	get(prototype Component) Component
+	put(component Component)
+}
+
+// ComponentPool is a Pool backed by a sync.Pool. T is the component's struct
+// type, and P is the pointer to it that implements Component.
+type ComponentPool[T any, P interface {
+	*T
+	Component
+}] struct {
+	pool sync.Pool
+}
+
+// NewComponentPool creates a pool for the given component type, for example
+// NewComponentPool[component.Location]().
+func NewComponentPool[T any, P interface {
+	*T
+	Component
+}]() *ComponentPool[T, P] {
+	return &ComponentPool[T, P]{}
+}
+
+// ComponentName returns the name of the component type in the pool.
+func (p *ComponentPool[T, P]) ComponentName() ComponentName {
+	return P(new(T)).ComponentName()
+}
+
+// get returns a component from the pool, with the same contents as the
+// prototype.
+func (p *ComponentPool[T, P]) get(prototype Component) Component {
+	c, ok := p.pool.Get().(P)
+	if !ok {
+		c = new(T)
+	}
+
+	*c = *prototype.(P)
+	return c
+}
+
+// put resets the component to its zero value and returns it to the pool.
+func (p *ComponentPool[T, P]) put(component Component) {
+	c := component.(P)
+
+	var zero T
+	*c = zero
+
+	p.pool.Put(c)
+}
@@ -0,0 +1,77 @@
+package ecs
+
+import (
+	"log/slog"
+	"slices"
+)
+
+// Positioned is a component that puts an entity on a tile of the map, such
+// as component.Location. The world keeps an index of where every positioned
+// entity is, so that EntitiesAt doesn't have to check every entity. An
+// entity should have at most one Positioned component.
+//
+// The index only finds out about a move if it goes through MoveEntity, so
+// once a Positioned component has been added to an entity, its position must
+// not be changed any other way.
+type Positioned interface {
+	Component
+	// Position returns the tile the entity is on.
+	Position() (x, y int)
+	// SetPosition puts the entity on the given tile. Only the World should
+	// call it; everything else should use MoveEntity.
+	SetPosition(x, y int)
+}
+
+// MoveEntity moves the entity to the given tile, updating both its
+// Positioned component and the index EntitiesAt uses, and marks the
+// component as changed. It returns false, and does nothing, if the entity
+// doesn't have a Positioned component.
+func (w *World) MoveEntity(entityID EntityID, x, y int) bool {
+	componentID, ok := w.positioned[entityID]
+	if !ok {
+		slog.Warn("moving entity that has no position", "entity_id", entityID)
+		return false
+	}
+
+	positioned := w.components[componentID].(Positioned)
+	w.unindexPosition(entityID)
+	positioned.SetPosition(x, y)
+	w.indexPosition(entityID, componentID, positioned)
+
+	w.MarkChanged(entityID, positioned.ComponentName())
+
+	return true
+}
+
+// EntitiesAt returns the entities whose Positioned component puts them on the
+// given tile, sorted by EntityID.
+func (w *World) EntitiesAt(x, y int) []EntityID {
+	return slices.Clone(w.cells[[2]int{x, y}])
+}
+
+// indexPosition adds the entity to the index at the position of its
+// Positioned component, which has the given ID.
+func (w *World) indexPosition(entityID EntityID, componentID ComponentID, positioned Positioned) {
+	x, y := positioned.Position()
+	cell := [2]int{x, y}
+
+	i, _ := slices.BinarySearch(w.cells[cell], entityID)
+	w.cells[cell] = slices.Insert(w.cells[cell], i, entityID)
+	w.positioned[entityID] = componentID
+	w.positions[entityID] = cell
+}
+
+// unindexPosition takes the entity out of the index, if it is in it.
+func (w *World) unindexPosition(entityID EntityID) {
+	cell, ok := w.positions[entityID]
+	if !ok {
+		return
+	}
+
+	w.cells[cell] = removeValue(w.cells[cell], entityID)
+	if len(w.cells[cell]) == 0 {
+		delete(w.cells, cell)
+	}
+	delete(w.positioned, entityID)
+	delete(w.positions, entityID)
+}
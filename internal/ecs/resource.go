@@ -0,0 +1,18 @@
+package ecs
+
+import "reflect"
+
+// SetResource stores something that belongs to the world as a whole, rather
+// than to any one entity, such as a map that several systems share. There can
+// only be one resource of each type, so setting a second one of the same type
+// replaces the first. Get it back with GetResource.
+func (w *World) SetResource(resource any) {
+	w.resources[reflect.TypeOf(resource)] = resource
+}
+
+// GetResource returns the world's resource of the given type, and whether
+// there is one.
+func GetResource[T any](world *World) (T, bool) {
+	resource, ok := world.resources[reflect.TypeOf((*T)(nil)).Elem()].(T)
+	return resource, ok
+}
@@ -0,0 +1,239 @@
+package ecs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"reflect"
+	"slices"
+)
+
+// ErrUnknownEntity is returned when loading a world that has an entity whose
+// type hasn't been registered with RegisterEntities.
+var ErrUnknownEntity = errors.New("unknown entity type")
+
+// RegisterEntities tells the world about types of entity, and the components
+// they are made of, so that it can recreate them when loading a save game.
+// Entities added with AddEntity are registered automatically, but a world
+// that is about to be loaded hasn't added any yet, so every type of entity
+// that could be in the save needs to be registered first.
+func (w *World) RegisterEntities(entities ...Entity) {
+	for _, entity := range entities {
+		if _, ok := w.entityTypes[entity.EntityName()]; ok {
+			continue
+		}
+
+		w.entityTypes[entity.EntityName()] = entity
+
+		_, components := entity.New()
+		w.RegisterComponents(components...)
+	}
+}
+
+// RegisterComponents tells the world about types of component that can be
+// added to entities after they are created, and so aren't registered along
+// with any entity, so that it can recreate them when loading a save game.
+// Components must be pointers to structs.
+func (w *World) RegisterComponents(components ...Component) {
+	for _, component := range components {
+		w.componentTypes[component.ComponentName()] = reflect.TypeOf(component)
+	}
+}
+
+// worldJSON is how a World is saved. Components are saved with encoding/json,
+// so each one is saved as its exported fields unless it implements
+// json.Marshaler itself.
+//
+// NextID is the ID the next component will get. Saves from before entity IDs
+// were reused don't have Generations or FreeEntities; their entity IDs are
+// still valid, and the slots they didn't use are freed when they are loaded.
+type worldJSON struct {
+	NextID       ID           `json:"next_id"`
+	Generations  []uint16     `json:"generations,omitempty"`
+	FreeEntities []uint32     `json:"free_entities,omitempty"`
+	Turn         uint64       `json:"turn"`
+	Seed         int64        `json:"seed"`
+	RandDraws    uint64       `json:"rand_draws"`
+	Entities     []entityJSON `json:"entities"`
+}
+
+type entityJSON struct {
+	ID           EntityID                          `json:"id"`
+	Name         EntityName                        `json:"name"`
+	Components   map[ComponentName]json.RawMessage `json:"components"`
+	ComponentIDs map[ComponentName]ComponentID     `json:"component_ids"`
+}
+
+// MarshalJSON saves every entity in the world with its components, along
+// with the turn and the state of the random number generator. Systems and
+// resources aren't saved; they belong to the game rather than the save.
+func (w *World) MarshalJSON() ([]byte, error) {
+	save := worldJSON{
+		NextID:       w.nextComponentID,
+		Generations:  w.ids.generations,
+		FreeEntities: w.ids.free,
+		Turn:         w.turn,
+		Seed:         w.seed,
+		RandDraws:    w.source.draws,
+		Entities:     make([]entityJSON, 0, len(w.entities)),
+	}
+
+	ids := make([]EntityID, 0, len(w.entities))
+	for id := range w.entities {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		entity := entityJSON{
+			ID:           id,
+			Name:         w.entities[id].EntityName(),
+			Components:   make(map[ComponentName]json.RawMessage),
+			ComponentIDs: make(map[ComponentName]ComponentID),
+		}
+
+		for name, componentID := range w.entityComponents[id] {
+			data, err := json.Marshal(w.components[componentID])
+			if err != nil {
+				return nil, fmt.Errorf("saving %s of entity %d: %w", name, id, err)
+			}
+			entity.Components[name] = data
+			entity.ComponentIDs[name] = componentID
+		}
+
+		save.Entities = append(save.Entities, entity)
+	}
+
+	return json.Marshal(save)
+}
+
+// UnmarshalJSON replaces every entity in the world with the ones in data, as
+// saved by MarshalJSON, and puts the turn and random number generator back
+// the way they were. Entities and components keep their IDs, so anything that
+// refers to one, such as a system's Player, is still right once the world is
+// loaded, and new entities get the same IDs they would have before saving.
+//
+// Systems should be added before the world is loaded, and every type of
+// entity in the save must have been registered with RegisterEntities. Each
+// entity is created with New, and then the saved components are loaded over
+// the top of the ones it returns, so anything that isn't saved, such as a
+// sprite, comes from New. Components that aren't registered are skipped with
+// a warning, so that a save from a newer version still loads as much as it
+// can.
+func (w *World) UnmarshalJSON(data []byte) error {
+	var save worldJSON
+	if err := json.Unmarshal(data, &save); err != nil {
+		return err
+	}
+
+	for _, entity := range save.Entities {
+		if _, ok := w.entityTypes[entity.Name]; !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownEntity, entity.Name)
+		}
+	}
+
+	existing := make([]EntityID, 0, len(w.entities))
+	for id := range w.entities {
+		existing = append(existing, id)
+	}
+	for _, id := range existing {
+		w.RemoveEntity(id)
+	}
+
+	w.nextComponentID = save.NextID
+	w.ids = entityAllocator{generations: save.Generations, free: save.FreeEntities}
+	if save.Generations == nil {
+		for _, saved := range save.Entities {
+			w.ids.reserve(saved.ID)
+		}
+	}
+	w.turn = save.Turn
+	w.SetSeed(save.Seed)
+	w.source.skip(save.RandDraws)
+
+	for _, saved := range save.Entities {
+		entity, defaults := w.entityTypes[saved.Name].New()
+
+		components := make([]Component, 0, len(saved.Components))
+		componentIDs := make([]ComponentID, 0, len(saved.Components))
+		for _, component := range defaults {
+			if raw, ok := saved.Components[component.ComponentName()]; ok {
+				components = append(components, component)
+				componentIDs = append(componentIDs, w.savedComponentID(saved, component.ComponentName()))
+				delete(saved.Components, component.ComponentName())
+
+				if err := json.Unmarshal(raw, component); err != nil {
+					return fmt.Errorf("loading %s of entity %d: %w", component.ComponentName(), saved.ID, err)
+				}
+			}
+		}
+
+		// anything left was added to the entity after it was created
+		names := make([]ComponentName, 0, len(saved.Components))
+		for name := range saved.Components {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		for _, name := range names {
+			t, ok := w.componentTypes[name]
+			if !ok {
+				slog.Warn("skipping unknown component in save", "entity_id", saved.ID, "component", name)
+				continue
+			}
+
+			component := reflect.New(t.Elem()).Interface().(Component)
+			if err := json.Unmarshal(saved.Components[name], component); err != nil {
+				return fmt.Errorf("loading %s of entity %d: %w", name, saved.ID, err)
+			}
+			components = append(components, component)
+			componentIDs = append(componentIDs, w.savedComponentID(saved, name))
+		}
+
+		w.addEntity(saved.ID, entity, components, componentIDs)
+	}
+
+	return nil
+}
+
+// savedComponentID returns the ID the entity's component with the given name
+// was saved with, or a new one if it wasn't saved with one.
+func (w *World) savedComponentID(saved entityJSON, name ComponentName) ComponentID {
+	if id, ok := saved.ComponentIDs[name]; ok {
+		return id
+	}
+	return w.nextComponent()
+}
+
+// countingSource is a rand.Source that counts how many numbers it has handed
+// out. math/rand can't save the state of a generator, but since it always
+// hands out the same numbers for a seed, reseeding it and skipping the same
+// number of draws puts it back where it was.
+type countingSource struct {
+	src   rand.Source64
+	draws uint64
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.draws++
+	return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.draws = 0
+}
+
+// skip draws n numbers and throws them away.
+func (s *countingSource) skip(n uint64) {
+	for i := uint64(0); i < n; i++ {
+		s.Uint64()
+	}
+}
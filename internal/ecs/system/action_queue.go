@@ -0,0 +1,60 @@
+package system
+
+// DefaultQueueSize is the number of actions an ActionQueue holds if its Size
+// isn't set.
+const DefaultQueueSize = 4
+
+// ActionQueue holds the actions the player has asked for but that haven't
+// been carried out yet, oldest first. Key presses are queued as soon as they
+// happen, and taken off the queue one at a time when it's the player's turn,
+// so that keys pressed during a busy frame or while the player is waiting to
+// act aren't lost.
+//
+// The queue is capped so that mashing a key doesn't leave the player walking
+// long after they've let go of it: once there are Size actions queued, any
+// more are dropped.
+type ActionQueue struct {
+	// Size is the most actions the queue holds. If it is zero,
+	// DefaultQueueSize is used.
+	Size int
+
+	actions []Action
+}
+
+// Push adds the action to the back of the queue. It returns false, and drops
+// the action, if the queue is full.
+func (q *ActionQueue) Push(action Action) bool {
+	size := q.Size
+	if size == 0 {
+		size = DefaultQueueSize
+	}
+
+	if len(q.actions) >= size {
+		return false
+	}
+
+	q.actions = append(q.actions, action)
+	return true
+}
+
+// Pop takes the oldest action off the front of the queue. It returns false if
+// the queue is empty.
+func (q *ActionQueue) Pop() (Action, bool) {
+	if len(q.actions) == 0 {
+		return ActionNone, false
+	}
+
+	action := q.actions[0]
+	q.actions = q.actions[1:]
+	return action, true
+}
+
+// Len returns the number of actions in the queue.
+func (q *ActionQueue) Len() int {
+	return len(q.actions)
+}
+
+// Clear throws away every action in the queue.
+func (q *ActionQueue) Clear() {
+	q.actions = q.actions[:0]
+}
@@ -0,0 +1,77 @@
+package system_test
+
+import (
+	"testing"
+
+	"github.com/matjam/sword/internal/ecs/system"
+)
+
+func TestActionQueue(t *testing.T) {
+	q := &system.ActionQueue{}
+
+	// the player mashes a direction six times while their last move is still
+	// resolving; only the first DefaultQueueSize presses are kept.
+	for i := 0; i < 6; i++ {
+		queued := q.Push(system.ActionMoveEast)
+		if queued != (i < system.DefaultQueueSize) {
+			t.Errorf("press %d: expected queued to be %v, got %v", i, i < system.DefaultQueueSize, queued)
+		}
+	}
+
+	// the moves are taken off one per turn, and after two turns there's room
+	// for the player to queue a wait behind the moves that are left.
+	turns := 0
+	for q.Len() > 0 {
+		action, ok := q.Pop()
+		if !ok || action != system.ActionMoveEast {
+			t.Fatalf("turn %d: expected to move east, got %v %v", turns, action, ok)
+		}
+		turns++
+
+		if turns == 2 {
+			q.Push(system.ActionWait)
+			break
+		}
+	}
+
+	var actions []system.Action
+	for {
+		action, ok := q.Pop()
+		if !ok {
+			break
+		}
+		actions = append(actions, action)
+	}
+
+	expected := []system.Action{system.ActionMoveEast, system.ActionMoveEast, system.ActionWait}
+	if len(actions) != len(expected) {
+		t.Fatalf("expected the rest of the queue to be %v, got %v", expected, actions)
+	}
+	for i := range expected {
+		if actions[i] != expected[i] {
+			t.Errorf("expected the rest of the queue to be %v, got %v", expected, actions)
+			break
+		}
+	}
+
+	if _, ok := q.Pop(); ok {
+		t.Error("expected the queue to be empty")
+	}
+}
+
+func TestActionQueueSize(t *testing.T) {
+	q := &system.ActionQueue{Size: 1}
+
+	if !q.Push(system.ActionMoveNorth) || q.Push(system.ActionMoveSouth) {
+		t.Error("expected a queue of size 1 to hold exactly one action")
+	}
+
+	q.Clear()
+	if q.Len() != 0 {
+		t.Errorf("expected Clear to empty the queue, got %d", q.Len())
+	}
+
+	if !q.Push(system.ActionMoveSouth) {
+		t.Error("expected a cleared queue to have room")
+	}
+}
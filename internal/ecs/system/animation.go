@@ -0,0 +1,50 @@
+package system
+
+import (
+	"time"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+)
+
+// Ensure that we're implementing the ecs.System interface.
+var _ = ecs.System(&Animation{})
+
+// Animation moves every entity's Animation on by the time since the last
+// update. See component.Animation.
+type Animation struct {
+	world *ecs.World
+
+	// OnFinish, if set, is called when an animation that doesn't loop
+	// finishes, such as to apply an attack's damage once the attack has
+	// been seen.
+	OnFinish func(entityID ecs.EntityID)
+}
+
+// Init initializes the system.
+func (sys *Animation) Init(world *ecs.World) {
+	sys.world = world
+}
+
+// SystemName returns the name of the system.
+func (sys *Animation) SystemName() ecs.SystemName {
+	return "animation"
+}
+
+// Components returns the components that the system is interested in.
+func (sys *Animation) Components() []ecs.Component {
+	return []ecs.Component{
+		&component.Animation{},
+	}
+}
+
+// Update updates the system.
+func (sys *Animation) Update(deltaTime time.Duration) {
+	sys.world.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
+		animation := ecs.GetComponentID[*component.Animation](sys.world, components["animation"])
+
+		if animation.Advance(deltaTime) && sys.OnFinish != nil {
+			sys.OnFinish(sys.world.EntityForComponent(components["animation"]))
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package system_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/ecs/ecstest"
+	"github.com/matjam/sword/internal/ecs/entity"
+	"github.com/matjam/sword/internal/ecs/system"
+)
+
+func TestAnimation(t *testing.T) {
+	ecstest.Quiet(t)
+
+	var finished []ecs.EntityID
+	world := ecstest.NewWorld(t, &system.Animation{
+		OnFinish: func(entityID ecs.EntityID) { finished = append(finished, entityID) },
+	})
+
+	frames := component.SliceFrames(ebiten.NewImage(20, 10), 10, 10)
+
+	attacker := world.AddEntity(&entity.Mob{})
+	world.AddComponent(attacker, &component.Animation{Frames: frames, FrameDuration: time.Second})
+
+	idler := world.AddEntity(&entity.Mob{})
+	world.AddComponent(idler, &component.Animation{Frames: frames, FrameDuration: time.Second, Loop: true})
+
+	world.Update(time.Second)
+	if frame := ecs.GetComponent[*component.Animation](world, attacker).Frame(); frame != frames[1] {
+		t.Error("expected the attack to be on its second frame")
+	}
+	if len(finished) != 0 {
+		t.Errorf("expected nothing to have finished yet, got %v", finished)
+	}
+
+	// only the animation that doesn't loop finishes, and only once
+	for i := 0; i < 3; i++ {
+		world.Update(time.Second)
+	}
+	if len(finished) != 1 || finished[0] != attacker {
+		t.Errorf("expected only the attack to finish, got %v", finished)
+	}
+}
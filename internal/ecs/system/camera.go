@@ -0,0 +1,90 @@
+package system
+
+import (
+	"time"
+
+	"github.com/matjam/sword/internal/camera"
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/tilemap"
+)
+
+// Ensure that we're implementing the ecs.System interface.
+var _ = ecs.System(&Camera{})
+
+// Camera moves the camera. While the camera is in camera.ModeFollow it is
+// kept centered on the player; in camera.ModeFree it is left wherever the
+// Input system has panned it to. Either way it is kept inside the map. It
+// should be added after the Movement system, so that the camera follows the
+// player on the same frame they move.
+type Camera struct {
+	world  *ecs.World
+	Player ecs.EntityID
+
+	// Camera is the camera to move. If it is nil, the world's *camera.Camera
+	// resource is used.
+	Camera *camera.Camera
+
+	// Tilemap is the map the camera is kept inside. If it is nil, the
+	// world's *tilemap.Grid resource is used, and if there isn't one, the
+	// camera can go anywhere.
+	Tilemap *tilemap.Grid
+
+	// ScreenWidth and ScreenHeight are the size of the screen, in pixels.
+	// They must be set for the camera to be moved.
+	ScreenWidth  int
+	ScreenHeight int
+}
+
+// Init initializes the system.
+func (sys *Camera) Init(world *ecs.World) {
+	sys.world = world
+}
+
+// SystemName returns the name of the system.
+func (sys *Camera) SystemName() ecs.SystemName {
+	return "camera"
+}
+
+// Components returns the components that the system is interested in. The
+// camera looks up the player directly, so it doesn't need any.
+func (sys *Camera) Components() []ecs.Component {
+	return []ecs.Component{}
+}
+
+// Update updates the system.
+func (sys *Camera) Update(deltaTime time.Duration) {
+	cam := sys.camera()
+	if cam == nil || sys.ScreenWidth == 0 || sys.ScreenHeight == 0 {
+		return
+	}
+
+	if cam.Mode == camera.ModeFollow && sys.world.HasComponent(sys.Player, &component.Location{}) {
+		location := ecs.GetComponent[*component.Location](sys.world, sys.Player)
+		cam.CenterOn(location.X, location.Y, sys.ScreenWidth, sys.ScreenHeight)
+	}
+
+	if tm := sys.tilemap(); tm != nil {
+		cam.Clamp(tm.Width, tm.Height, sys.ScreenWidth, sys.ScreenHeight)
+	}
+}
+
+// camera returns the camera to move.
+func (sys *Camera) camera() *camera.Camera {
+	if sys.Camera != nil {
+		return sys.Camera
+	}
+
+	cam, _ := ecs.GetResource[*camera.Camera](sys.world)
+	return cam
+}
+
+// tilemap returns the map the camera is kept inside.
+func (sys *Camera) tilemap() *tilemap.Grid {
+	if sys.Tilemap != nil {
+		return sys.Tilemap
+	}
+
+	tm, _ := ecs.GetResource[*tilemap.Grid](sys.world)
+	return tm
+}
@@ -0,0 +1,82 @@
+package system_test
+
+import (
+	"testing"
+
+	"github.com/matjam/sword/internal/camera"
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/ecs/entity"
+	"github.com/matjam/sword/internal/ecs/system"
+	"github.com/matjam/sword/internal/tilemap"
+)
+
+func TestCamera_FreeMode(t *testing.T) {
+	tm := tilemap.NewGrid(100, 100)
+	tm.SetTile(51, 50, &tilemap.Tile{Type: tilemap.TileTypeFloor})
+	cam := camera.New(16, 16, 1)
+
+	world := ecs.NewWorld()
+	world.SetResource(tm)
+	world.SetResource(cam)
+
+	input := &system.Input{}
+	follow := &system.Camera{ScreenWidth: 320, ScreenHeight: 160}
+	if err := world.AddSystems(input, &system.Movement{}, follow); err != nil {
+		t.Fatal(err)
+	}
+
+	input.Player = world.AddEntity(&entity.Player{})
+	follow.Player = input.Player
+	world.MoveEntity(input.Player, 50, 50)
+	player := ecs.GetComponent[*component.Location](world, input.Player)
+
+	// the camera follows the player, with them in the middle of the screen
+	world.Update(1)
+	if tx, ty := cam.ScreenToTile(160, 80); tx != 50 || ty != 50 {
+		t.Fatalf("expected the camera to be centered on the player, got %d,%d", tx, ty)
+	}
+	followX, followY := cam.X, cam.Y
+
+	// in free mode the move keys pan the camera instead of moving the player,
+	// and don't use up any turns
+	for _, action := range []system.Action{
+		system.ActionFreeCamera,
+		system.ActionMoveEast,
+		system.ActionMoveEast,
+		system.ActionMoveSouth,
+	} {
+		input.Queue.Push(action)
+		world.Update(1)
+	}
+
+	if !input.IsCameraFree() {
+		t.Fatal("expected the camera to be free")
+	}
+	if cam.X != followX+32 || cam.Y != followY+16 {
+		t.Errorf("expected the camera to pan two tiles east and one south, got %d,%d from %d,%d", cam.X, cam.Y, followX, followY)
+	}
+	if player.X != 50 || player.Y != 50 || world.Turn() != 0 {
+		t.Errorf("expected the player not to move or use a turn, got %d,%d on turn %d", player.X, player.Y, world.Turn())
+	}
+
+	// the camera can't be panned off the map
+	cam.X = -1000
+	world.Update(1)
+	if cam.X != 0 {
+		t.Errorf("expected the camera to be kept inside the map, got %d", cam.X)
+	}
+
+	// recentering puts the camera back on the player, and it follows them
+	input.Queue.Push(system.ActionCenterCamera)
+	world.Update(1)
+	if input.IsCameraFree() || cam.X != followX || cam.Y != followY {
+		t.Errorf("expected the camera to follow the player again, got %d,%d", cam.X, cam.Y)
+	}
+
+	input.Queue.Push(system.ActionMoveEast)
+	world.Update(1)
+	if player.X != 51 || cam.X != followX+16 {
+		t.Errorf("expected the player to move and the camera to follow, got %d and %d", player.X, cam.X)
+	}
+}
@@ -0,0 +1,80 @@
+package system
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Command is a single action the player took, and the turn they took it on.
+type Command struct {
+	Turn   uint64 `json:"turn"`
+	Action Action `json:"action"`
+}
+
+// CommandLog is a record of every action the player took in a game, along
+// with the seed the game was started with. Replaying the log through the
+// Input system, in a world seeded with the same seed, plays the game out
+// exactly as it happened, which makes it easy to reproduce bugs from a
+// player's log or to check that a score is genuine.
+//
+// This only works if the game is deterministic: given the same seed and the
+// same actions, every system must do exactly the same thing. Systems must
+// take all of their randomness from World.Rand, must not depend on the order
+// of map iteration or on the wall clock, and must only change the game when
+// a turn ends, never in response to how fast frames are being drawn.
+type CommandLog struct {
+	Seed     int64     `json:"seed"`
+	Commands []Command `json:"commands"`
+
+	// next is the index of the next command to be replayed
+	next int
+}
+
+// LoadCommandLog reads a command log saved by Save.
+func LoadCommandLog(path string) (*CommandLog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	log := &CommandLog{}
+	if err := json.Unmarshal(data, log); err != nil {
+		return nil, err
+	}
+
+	return log, nil
+}
+
+// Save writes the command log to the given file as JSON.
+func (log *CommandLog) Save(path string) error {
+	data, err := json.Marshal(log)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Record adds the action to the end of the log.
+func (log *CommandLog) Record(turn uint64, action Action) {
+	log.Commands = append(log.Commands, Command{Turn: turn, Action: action})
+}
+
+// Peek returns the next command to be replayed, without moving past it. It
+// returns false once every command has been replayed.
+func (log *CommandLog) Peek() (Command, bool) {
+	if log.next >= len(log.Commands) {
+		return Command{}, false
+	}
+	return log.Commands[log.next], true
+}
+
+// Next returns the next command to be replayed, and moves past it. It returns
+// false once every command has been replayed.
+func (log *CommandLog) Next() (Command, bool) {
+	command, ok := log.Peek()
+	if ok {
+		log.next++
+	}
+	return command, ok
+}
@@ -0,0 +1,75 @@
+package system_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/ecs/entity"
+	"github.com/matjam/sword/internal/ecs/system"
+)
+
+func TestCommandLogSaveLoad(t *testing.T) {
+	log := &system.CommandLog{Seed: 42}
+	log.Record(0, system.ActionMoveEast)
+	log.Record(1, system.ActionWait)
+
+	path := filepath.Join(t.TempDir(), "commands.json")
+	if err := log.Save(path); err != nil {
+		t.Fatalf("saving the log failed: %v", err)
+	}
+
+	loaded, err := system.LoadCommandLog(path)
+	if err != nil {
+		t.Fatalf("loading the log failed: %v", err)
+	}
+
+	if loaded.Seed != log.Seed || !reflect.DeepEqual(loaded.Commands, log.Commands) {
+		t.Errorf("expected %+v, got %+v", log, loaded)
+	}
+
+	if _, err := system.LoadCommandLog(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected loading a missing log to fail")
+	}
+}
+
+func TestInputReplay(t *testing.T) {
+	replay := &system.CommandLog{
+		Seed: 1,
+		Commands: []system.Command{
+			{Turn: 0, Action: system.ActionMoveEast},
+			{Turn: 1, Action: system.ActionMoveEast},
+			{Turn: 2, Action: system.ActionMoveSouthEast},
+			{Turn: 3, Action: system.ActionWait},
+		},
+	}
+	record := &system.CommandLog{Seed: 1}
+
+	world := ecs.NewWorld()
+	input := &system.Input{Replay: replay, Record: record}
+	if err := world.AddSystems(input, &system.Movement{}); err != nil {
+		t.Fatal(err)
+	}
+	input.Player = world.AddEntity(&entity.Player{})
+
+	// one command is replayed every frame, and then nothing more happens
+	for i := 0; i < 10; i++ {
+		world.Update(1)
+	}
+
+	location := ecs.GetComponent[*component.Location](world, input.Player)
+	if location.X != 3 || location.Y != 1 {
+		t.Errorf("expected the player to end up at 3,1, got %d,%d", location.X, location.Y)
+	}
+
+	if world.Turn() != 4 {
+		t.Errorf("expected 4 turns to have passed, got %d", world.Turn())
+	}
+
+	// recording while replaying gives back the same log
+	if !reflect.DeepEqual(record.Commands, replay.Commands) {
+		t.Errorf("expected the recording to match the replay, got %+v", record.Commands)
+	}
+}
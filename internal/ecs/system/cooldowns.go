@@ -0,0 +1,54 @@
+package system
+
+import (
+	"time"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+)
+
+// Ensure that we're implementing the ecs.System interface.
+var _ = ecs.System(&Cooldowns{})
+
+// Cooldowns counts down every entity's ability cooldowns once a turn. See
+// component.Cooldowns.
+type Cooldowns struct {
+	world *ecs.World
+
+	// lastTurn is the turn the cooldowns were last counted down for
+	lastTurn uint64
+}
+
+// Init initializes the system.
+func (sys *Cooldowns) Init(world *ecs.World) {
+	sys.world = world
+
+	// a world that's been loaded, or has already been played, starts on a
+	// later turn, and that turn's cooldowns have already been counted down.
+	sys.lastTurn = world.Turn()
+}
+
+// SystemName returns the name of the system.
+func (sys *Cooldowns) SystemName() ecs.SystemName {
+	return "cooldowns"
+}
+
+// Components returns the components that the system is interested in.
+func (sys *Cooldowns) Components() []ecs.Component {
+	return []ecs.Component{
+		&component.Cooldowns{},
+	}
+}
+
+// Update updates the system.
+func (sys *Cooldowns) Update(deltaTime time.Duration) {
+	if sys.world.Turn() == sys.lastTurn {
+		return
+	}
+	sys.lastTurn = sys.world.Turn()
+
+	sys.world.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
+		cooldowns := ecs.GetComponentID[*component.Cooldowns](sys.world, components["cooldowns"])
+		cooldowns.Tick()
+	})
+}
@@ -0,0 +1,88 @@
+package system_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/ecs/entity"
+	"github.com/matjam/sword/internal/ecs/system"
+)
+
+func TestCooldowns(t *testing.T) {
+	world := ecs.NewWorld()
+	if err := world.AddSystem(&system.Cooldowns{}); err != nil {
+		t.Fatal(err)
+	}
+
+	player := world.AddEntity(&entity.Player{})
+	cooldowns := ecs.GetComponent[*component.Cooldowns](world, player)
+	cooldowns.Trigger("dash", 2)
+	cooldowns.Trigger("fireball", 5)
+
+	// the cooldowns only count down when a turn passes, not every frame
+	world.Update(1)
+	world.Update(1)
+	if cooldowns.Remaining["dash"] != 2 {
+		t.Errorf("expected the dash to have 2 turns left, got %d", cooldowns.Remaining["dash"])
+	}
+
+	world.EndTurn()
+	world.Update(1)
+	world.Update(1)
+	if cooldowns.Remaining["dash"] != 1 {
+		t.Errorf("expected the dash to have 1 turn left, got %d", cooldowns.Remaining["dash"])
+	}
+
+	// the cooldowns are saved with the rest of the world
+	data, err := json.Marshal(world)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := ecs.NewWorld()
+	loaded.RegisterEntities(entity.All()...)
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	remaining := ecs.GetComponent[*component.Cooldowns](loaded, player).Remaining
+	if remaining["dash"] != 1 || remaining["fireball"] != 4 {
+		t.Errorf("expected the loaded cooldowns to be dash 1 and fireball 4, got %v", remaining)
+	}
+
+	world.EndTurn()
+	world.Update(1)
+	if !cooldowns.Ready("dash") || cooldowns.Ready("fireball") {
+		t.Errorf("expected only the dash to be ready, got %v", cooldowns.Remaining)
+	}
+}
+
+func TestCooldowns_LaterTurn(t *testing.T) {
+	world := ecs.NewWorld()
+	for i := 0; i < 3; i++ {
+		world.EndTurn()
+	}
+
+	// adding the system on turn 3 shouldn't count down a turn that hasn't
+	// passed yet
+	if err := world.AddSystem(&system.Cooldowns{}); err != nil {
+		t.Fatal(err)
+	}
+
+	player := world.AddEntity(&entity.Player{})
+	cooldowns := ecs.GetComponent[*component.Cooldowns](world, player)
+	cooldowns.Trigger("dash", 2)
+
+	world.Update(1)
+	if cooldowns.Remaining["dash"] != 2 {
+		t.Errorf("expected the dash to have 2 turns left, got %d", cooldowns.Remaining["dash"])
+	}
+
+	world.EndTurn()
+	world.Update(1)
+	if cooldowns.Remaining["dash"] != 1 {
+		t.Errorf("expected the dash to have 1 turn left, got %d", cooldowns.Remaining["dash"])
+	}
+}
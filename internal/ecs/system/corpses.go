@@ -0,0 +1,58 @@
+package system
+
+import (
+	"time"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/ecs/entity"
+)
+
+// Ensure that we're implementing the ecs.System interface.
+var _ = ecs.System(&Corpses{})
+
+// Corpses leaves a corpse where each Dead entity died, and drops everything
+// the entity was carrying into it as loot. It must be added after the Injury
+// system and before the Reaper.
+type Corpses struct {
+	world *ecs.World
+}
+
+// Init initializes the system.
+func (sys *Corpses) Init(world *ecs.World) {
+	sys.world = world
+}
+
+// SystemName returns the name of the system.
+func (sys *Corpses) SystemName() ecs.SystemName {
+	return "corpses"
+}
+
+// Components returns the components that the system is interested in.
+func (sys *Corpses) Components() []ecs.Component {
+	return []ecs.Component{
+		&component.Dead{},
+	}
+}
+
+// Update updates the system.
+func (sys *Corpses) Update(deltaTime time.Duration) {
+	sys.world.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
+		entityID := sys.world.EntityForComponent(components["dead"])
+		corpse := sys.world.AddEntity(&entity.Corpse{})
+
+		if sys.world.HasComponent(entityID, &component.Location{}) {
+			location := ecs.GetComponent[*component.Location](sys.world, entityID)
+			sys.world.MoveEntity(corpse, location.X, location.Y)
+		}
+
+		// the corpse keeps everything the entity was carrying, so it can be
+		// looted.
+		if sys.world.HasComponent(entityID, &component.Inventory{}) {
+			inventory := ecs.GetComponent[*component.Inventory](sys.world, entityID)
+			corpseInventory := ecs.GetComponent[*component.Inventory](sys.world, corpse)
+			corpseInventory.Items = inventory.Items
+			inventory.Items = nil
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package system
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+)
+
+// Ensure that we're implementing the ecs.RenderSystem interface.
+var _ = ecs.RenderSystem(&DebugOverlay{})
+
+// DebugOverlay draws a small debug HUD in the top left corner of the screen
+// showing the FPS, the current turn, the number of entities in the world and
+// the location of the player. It is off by default, and can be toggled with
+// F3 or by setting Enabled.
+type DebugOverlay struct {
+	world *ecs.World
+
+	Player  ecs.EntityID
+	Enabled bool
+}
+
+// Init initializes the system.
+func (sys *DebugOverlay) Init(world *ecs.World) {
+	sys.world = world
+}
+
+// SystemName returns the name of the system.
+func (sys *DebugOverlay) SystemName() ecs.SystemName {
+	return "debug_overlay"
+}
+
+// Components returns the components that the system is interested in. The
+// overlay looks up the player directly, so it doesn't need any.
+func (sys *DebugOverlay) Components() []ecs.Component {
+	return []ecs.Component{}
+}
+
+// Update toggles the overlay when F3 is pressed.
+func (sys *DebugOverlay) Update(deltaTime time.Duration) {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+		sys.Enabled = !sys.Enabled
+	}
+}
+
+// Draw draws the overlay if it is enabled.
+func (sys *DebugOverlay) Draw(screen *ebiten.Image) {
+	if !sys.Enabled {
+		return
+	}
+
+	ebitenutil.DebugPrintAt(screen, sys.text(), 4, 4)
+}
+
+func (sys *DebugOverlay) text() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "FPS: %0.1f\n", ebiten.ActualFPS())
+	fmt.Fprintf(&sb, "Turn: %d\n", sys.world.Turn())
+	fmt.Fprintf(&sb, "Entities: %d\n", sys.world.EntityCount())
+
+	if sys.world.HasComponent(sys.Player, &component.Location{}) {
+		location := ecs.GetComponent[*component.Location](sys.world, sys.Player)
+		fmt.Fprintf(&sb, "Player: %d, %d\n", location.X, location.Y)
+	} else {
+		sb.WriteString("Player: none\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,80 @@
+package system
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+)
+
+// Ensure that we're implementing the ecs.System interface.
+var _ = ecs.System(&Encumbrance{})
+
+// encumbranceMessages is what the player is told when they become each level
+// of encumbrance.
+var encumbranceMessages = map[component.EncumbranceLevel]string{
+	component.Unburdened: "You are no longer burdened.",
+	component.Burdened:   "You are burdened.",
+	component.Overloaded: "You are overloaded.",
+}
+
+// Encumbrance weighs the inventory of every entity with an Encumbrance
+// component, and updates how encumbered it is. The Movement system slows
+// down entities that are carrying too much. See component.Encumbrance.
+type Encumbrance struct {
+	world  *ecs.World
+	Player ecs.EntityID
+
+	// Notify, if set, is given a message whenever the player becomes more or
+	// less encumbered. If it is nil, the message is logged.
+	Notify func(message string)
+}
+
+// Init initializes the system.
+func (sys *Encumbrance) Init(world *ecs.World) {
+	sys.world = world
+}
+
+// SystemName returns the name of the system.
+func (sys *Encumbrance) SystemName() ecs.SystemName {
+	return "encumbrance"
+}
+
+// Components returns the components that the system is interested in.
+func (sys *Encumbrance) Components() []ecs.Component {
+	return []ecs.Component{
+		&component.Inventory{},
+		&component.Encumbrance{},
+	}
+}
+
+// Update updates the system.
+func (sys *Encumbrance) Update(deltaTime time.Duration) {
+	sys.world.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
+		inventory := ecs.GetComponentID[*component.Inventory](sys.world, components["inventory"])
+		encumbrance := ecs.GetComponentID[*component.Encumbrance](sys.world, components["encumbrance"])
+
+		level := encumbrance.LevelFor(inventory.TotalWeight())
+		if level == encumbrance.Level {
+			return
+		}
+		encumbrance.Level = level
+
+		entityID := sys.world.EntityForComponent(components["encumbrance"])
+		sys.world.MarkChanged(entityID, "encumbrance")
+
+		if entityID == sys.Player {
+			sys.notify(encumbranceMessages[level])
+		}
+	})
+}
+
+// notify tells the player the message.
+func (sys *Encumbrance) notify(message string) {
+	if sys.Notify != nil {
+		sys.Notify(message)
+		return
+	}
+	slog.Info(message)
+}
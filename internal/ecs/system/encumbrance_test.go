@@ -0,0 +1,145 @@
+package system_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/ecs/ecstest"
+	"github.com/matjam/sword/internal/ecs/entity"
+	"github.com/matjam/sword/internal/ecs/system"
+	"github.com/matjam/sword/internal/tilemap"
+)
+
+func TestEncumbrance(t *testing.T) {
+	ecstest.Quiet(t)
+
+	messages := make([]string, 0)
+	encumbranceSystem := &system.Encumbrance{
+		Notify: func(message string) { messages = append(messages, message) },
+	}
+	world := ecstest.NewWorld(t, encumbranceSystem)
+
+	player := world.AddEntity(&entity.Player{})
+	encumbranceSystem.Player = player
+	world.ReplaceComponent(player, &component.Encumbrance{Capacity: 10})
+
+	inventory := ecs.GetComponent[*component.Inventory](world, player)
+	encumbrance := ecs.GetComponent[*component.Encumbrance](world, player)
+
+	steps := []struct {
+		weight   int
+		expected component.EncumbranceLevel
+	}{
+		{weight: 10, expected: component.Unburdened},
+		{weight: 11, expected: component.Burdened},
+		{weight: 15, expected: component.Burdened},
+		{weight: 16, expected: component.Overloaded},
+		{weight: 0, expected: component.Unburdened},
+	}
+
+	for _, step := range steps {
+		inventory.Items = []component.Item{{Name: "rock", Weight: 1, Quantity: step.weight}}
+		world.Update(1)
+
+		if encumbrance.Level != step.expected {
+			t.Errorf("weight %d: expected %v, got %v", step.weight, step.expected, encumbrance.Level)
+		}
+	}
+
+	// the player is only told when the level changes
+	expected := []string{"You are burdened.", "You are overloaded.", "You are no longer burdened."}
+	if !slices.Equal(messages, expected) {
+		t.Errorf("expected the messages %q, got %q", expected, messages)
+	}
+
+	// mobs don't tell the player anything
+	mob := world.AddEntity(&entity.Mob{})
+	world.AddComponent(mob, &component.Encumbrance{Capacity: 1})
+	ecs.GetComponent[*component.Inventory](world, mob).Items = []component.Item{{Name: "rock", Weight: 5, Quantity: 1}}
+	world.Update(1)
+
+	if level := ecs.GetComponent[*component.Encumbrance](world, mob).Level; level != component.Overloaded {
+		t.Errorf("expected the mob to be overloaded, got %v", level)
+	}
+	if len(messages) != len(expected) {
+		t.Errorf("expected no messages about the mob, got %q", messages[len(expected):])
+	}
+}
+
+func TestMovement_Encumbrance(t *testing.T) {
+	ecstest.Quiet(t)
+
+	tests := []struct {
+		level    component.EncumbranceLevel
+		expected []int
+	}{
+		{level: component.Unburdened, expected: []int{1, 2, 3, 4}},
+		{level: component.Burdened, expected: []int{1, 1, 2, 2}},
+		{level: component.Overloaded, expected: []int{0, 0, 0, 0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.level.String(), func(t *testing.T) {
+			world := ecstest.NewWorld(t, &system.Movement{}, &system.Cooldowns{})
+
+			player := world.AddEntity(&entity.Player{})
+			ecs.GetComponent[*component.Encumbrance](world, player).Level = test.level
+
+			location := ecs.GetComponent[*component.Location](world, player)
+			move := ecs.GetComponent[*component.Move](world, player)
+
+			// the player tries to move east every turn
+			for turn, expected := range test.expected {
+				move.X = 1
+				world.EndTurn()
+				world.Update(1)
+
+				if location.X != expected {
+					t.Errorf("turn %d: expected the player at x %d, got %d", turn, expected, location.X)
+				}
+			}
+		})
+	}
+}
+
+func TestMovement_BurdenedBlocked(t *testing.T) {
+	ecstest.Quiet(t)
+
+	// a corridor along y 2, with walls everywhere else
+	tm := tilemap.NewGrid(10, 5)
+	for x := 1; x < 9; x++ {
+		tm.SetTile(x, 2, &tilemap.Tile{Type: tilemap.TileTypeFloor})
+	}
+
+	world := ecstest.NewWorld(t, &system.Movement{Tilemap: tm}, &system.Cooldowns{})
+
+	player := world.AddEntity(&entity.Player{})
+	world.MoveEntity(player, 2, 2)
+	ecs.GetComponent[*component.Encumbrance](world, player).Level = component.Burdened
+
+	location := ecs.GetComponent[*component.Location](world, player)
+	move := ecs.GetComponent[*component.Move](world, player)
+
+	// walking into the wall doesn't slow the player down, so they can still
+	// move on the next turn
+	move.Y = 1
+	world.EndTurn()
+	world.Update(1)
+
+	move.X = 1
+	world.EndTurn()
+	world.Update(1)
+	if location.X != 3 || location.Y != 2 {
+		t.Errorf("expected the player to move to 3,2 after walking into the wall, got %d,%d", location.X, location.Y)
+	}
+
+	// but moving does
+	move.X = 1
+	world.EndTurn()
+	world.Update(1)
+	if location.X != 3 {
+		t.Errorf("expected the player to wait a turn after moving, got x %d", location.X)
+	}
+}
@@ -0,0 +1,73 @@
+package system
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+)
+
+// Ensure that we're implementing the ecs.System interface.
+var _ = ecs.System(&Experience{})
+
+// Experience credits the killer of each Dead entity with the experience in
+// the dead entity's Reward, if the killer has an Experience component to put
+// it in. It must be added after the Injury system and before the Reaper.
+type Experience struct {
+	world  *ecs.World
+	Player ecs.EntityID
+
+	// Notify, if set, is given a message whenever the player earns
+	// experience. If it is nil, the message is logged.
+	Notify func(message string)
+}
+
+// Init initializes the system.
+func (sys *Experience) Init(world *ecs.World) {
+	sys.world = world
+}
+
+// SystemName returns the name of the system.
+func (sys *Experience) SystemName() ecs.SystemName {
+	return "experience"
+}
+
+// Components returns the components that the system is interested in.
+func (sys *Experience) Components() []ecs.Component {
+	return []ecs.Component{
+		&component.Dead{},
+		&component.Reward{},
+	}
+}
+
+// Update updates the system.
+func (sys *Experience) Update(deltaTime time.Duration) {
+	sys.world.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
+		dead := ecs.GetComponentID[*component.Dead](sys.world, components["dead"])
+		reward := ecs.GetComponentID[*component.Reward](sys.world, components["reward"])
+
+		killer := dead.Killer
+		if reward.Experience == 0 || !sys.world.HasComponent(killer, &component.Experience{}) {
+			return
+		}
+
+		experience := ecs.GetComponent[*component.Experience](sys.world, killer)
+		experience.Points += reward.Experience
+		sys.world.MarkChanged(killer, "experience")
+
+		if killer == sys.Player {
+			sys.notify(fmt.Sprintf("You gain %d experience.", reward.Experience))
+		}
+	})
+}
+
+// notify tells the player the message.
+func (sys *Experience) notify(message string) {
+	if sys.Notify != nil {
+		sys.Notify(message)
+		return
+	}
+	slog.Info(message)
+}
@@ -0,0 +1,55 @@
+package system
+
+import (
+	"github.com/matjam/sword/internal/camera"
+	"github.com/matjam/sword/internal/ecs"
+)
+
+// IsCameraFree returns true if the camera has been let go of the player, so
+// that the move actions pan it around instead.
+func (sys *Input) IsCameraFree() bool {
+	cam := sys.camera()
+	return cam != nil && cam.Mode == camera.ModeFree
+}
+
+// moveCamera handles the camera actions, and every action while the camera is
+// free. ActionFreeCamera lets go of the player, and the move actions then pan
+// the camera a tile at a time. ActionFreeCamera, ActionCenterCamera or
+// ActionCancel put it back on the player, and the Camera system follows them
+// again. Anything else is ignored while the camera is free, so that the
+// player can't act without being able to see what they're doing.
+func (sys *Input) moveCamera(action Action) {
+	cam := sys.camera()
+	if cam == nil {
+		return
+	}
+
+	if cam.Mode != camera.ModeFree {
+		if action == ActionFreeCamera {
+			cam.Mode = camera.ModeFree
+		}
+		return
+	}
+
+	if direction, ok := actionDirections[action]; ok {
+		width, height := cam.CellSize()
+		cam.Move(direction[0]*width, direction[1]*height)
+		return
+	}
+
+	switch action {
+	case ActionFreeCamera, ActionCenterCamera, ActionCancel:
+		cam.Mode = camera.ModeFollow
+	}
+}
+
+// camera returns the camera the player can pan around, or nil if there isn't
+// one.
+func (sys *Input) camera() *camera.Camera {
+	if sys.Camera != nil {
+		return sys.Camera
+	}
+
+	cam, _ := ecs.GetResource[*camera.Camera](sys.world)
+	return cam
+}
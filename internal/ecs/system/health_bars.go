@@ -0,0 +1,83 @@
+package system
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/matjam/sword/internal/camera"
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+)
+
+// Ensure that we're implementing the ecs.RenderSystem interface.
+var _ = ecs.RenderSystem(&HealthBars{})
+
+// healthBarBackground is drawn behind the part of the bar that's been lost.
+var healthBarBackground = color.RGBA{R: 64, G: 0, B: 0, A: 255}
+
+// HealthBars draws a small health bar just above every hurt entity that has a
+// HealthBar component. The bar is as wide as a tile, and goes from green at
+// full health, through yellow, to red.
+type HealthBars struct {
+	world *ecs.World
+
+	// Camera is used to work out where each entity is on the screen.
+	Camera *camera.Camera
+}
+
+// Init initializes the system.
+func (sys *HealthBars) Init(world *ecs.World) {
+	sys.world = world
+}
+
+// SystemName returns the name of the system.
+func (sys *HealthBars) SystemName() ecs.SystemName {
+	return "health_bars"
+}
+
+// Components returns the components that the system is interested in.
+func (sys *HealthBars) Components() []ecs.Component {
+	return []ecs.Component{
+		&component.HealthBar{},
+		&component.Health{},
+		&component.Location{},
+	}
+}
+
+// Update updates the system.
+func (sys *HealthBars) Update(delta time.Duration) {
+	// the health bars don't need to update anything
+}
+
+// Draw draws the health bars.
+func (sys *HealthBars) Draw(screen *ebiten.Image) {
+	cellWidth, cellHeight := sys.Camera.CellSize()
+	barHeight := max(cellHeight/8, 2)
+
+	sys.world.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
+		health := ecs.GetComponentID[*component.Health](sys.world, components["health"])
+		location := ecs.GetComponentID[*component.Location](sys.world, components["location"])
+
+		if health.Current >= health.Max {
+			return
+		}
+
+		ratio := health.Ratio()
+		x, y := sys.Camera.TileToScreen(location.X, location.Y)
+		y -= barHeight + 1
+
+		vector.DrawFilledRect(screen, float32(x), float32(y), float32(cellWidth), float32(barHeight), healthBarBackground, false)
+		vector.DrawFilledRect(screen, float32(x), float32(y), float32(float64(cellWidth)*ratio), float32(barHeight), healthColor(ratio), false)
+	})
+}
+
+// healthColor returns the color of a health bar for the given ratio of
+// health: green when full, yellow when half full, and red when empty.
+func healthColor(ratio float64) color.RGBA {
+	if ratio >= 0.5 {
+		return color.RGBA{R: uint8((1 - ratio) * 2 * 255), G: 255, A: 255}
+	}
+	return color.RGBA{R: 255, G: uint8(ratio * 2 * 255), A: 255}
+}
@@ -0,0 +1,100 @@
+package system
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+)
+
+// Ensure that we're implementing the ecs.System interface.
+var _ = ecs.System(&Injury{})
+
+// Injury applies the damage recorded in each entity's Damage component to its
+// Health, and marks anything that dies as a result with a Dead component,
+// crediting whoever dealt the killing blow. Other systems, such as Corpses and
+// Experience, react to the death, and the Reaper removes the entity at the end
+// of the frame. The player isn't marked; their health just stays at zero.
+type Injury struct {
+	world  *ecs.World
+	Player ecs.EntityID
+
+	// dead holds the entities that died this update. They're only marked
+	// once we've finished iterating over the components, so that the Dead
+	// components are all added in one go.
+	dead []ecs.EntityID
+	// killedBy holds the damage record that killed each entity in dead.
+	killedBy map[ecs.EntityID]component.DamageRecord
+}
+
+// Init initializes the system.
+func (sys *Injury) Init(world *ecs.World) {
+	sys.world = world
+	sys.killedBy = make(map[ecs.EntityID]component.DamageRecord)
+}
+
+// SystemName returns the name of the system.
+func (sys *Injury) SystemName() ecs.SystemName {
+	return "injury"
+}
+
+// Components returns the components that the system is interested in.
+func (sys *Injury) Components() []ecs.Component {
+	return []ecs.Component{
+		&component.Damage{},
+		&component.Health{},
+	}
+}
+
+// Update updates the system.
+func (sys *Injury) Update(deltaTime time.Duration) {
+	sys.dead = sys.dead[:0]
+	clear(sys.killedBy)
+
+	sys.world.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
+		damage := ecs.GetComponentID[*component.Damage](sys.world, components["damage"])
+		if len(damage.Records) == 0 {
+			return
+		}
+
+		health := ecs.GetComponentID[*component.Health](sys.world, components["health"])
+		alive := !health.IsDead()
+		var killedBy component.DamageRecord
+		for _, record := range damage.Records {
+			health.Damage(record.Amount)
+			if alive && health.IsDead() && killedBy.Amount == 0 {
+				killedBy = record
+			}
+		}
+		damage.ClearDamage()
+
+		entityID := sys.world.EntityForComponent(components["health"])
+		sys.world.MarkChanged(entityID, "health")
+
+		if alive && health.IsDead() {
+			sys.dead = append(sys.dead, entityID)
+			sys.killedBy[entityID] = killedBy
+		}
+	})
+
+	for _, entityID := range sys.dead {
+		sys.die(entityID)
+	}
+}
+
+// die marks the entity as dead.
+func (sys *Injury) die(entityID ecs.EntityID) {
+	if entityID == sys.Player {
+		slog.Info("the player has died")
+		return
+	}
+
+	killedBy := sys.killedBy[entityID]
+	sys.world.AddComponent(entityID, &component.Dead{
+		Cause:  killedBy.Source,
+		Killer: killedBy.Attacker,
+	})
+
+	slog.Debug("entity died", "entity", sys.world.GetEntity(entityID).EntityName(), "entity_id", entityID, "cause", killedBy.Source)
+}
@@ -0,0 +1,124 @@
+package system_test
+
+import (
+	"testing"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/ecs/entity"
+	"github.com/matjam/sword/internal/ecs/system"
+)
+
+func TestInjury(t *testing.T) {
+	world := ecs.NewWorld()
+	injury := &system.Injury{}
+	if err := world.AddSystems(injury, &system.Corpses{}, &system.Reaper{}); err != nil {
+		t.Fatal(err)
+	}
+
+	player := world.AddEntity(&entity.Player{})
+	injury.Player = player
+	mob := world.AddEntity(&entity.Mob{})
+
+	world.MoveEntity(mob, 3, 4)
+	ecs.GetComponent[*component.Inventory](world, mob).AddItem(component.Item{Name: "sword", Weight: 10})
+
+	ecs.GetComponent[*component.Damage](world, player).RecordDamage(30, "trap")
+	ecs.GetComponent[*component.Damage](world, mob).RecordDamage(60, "trap")
+	ecs.GetComponent[*component.Damage](world, mob).RecordDamage(60, "trap")
+
+	world.Update(1)
+
+	// the player is hurt, and the damage has been used up
+	if health := ecs.GetComponent[*component.Health](world, player); health.Current != 70 {
+		t.Errorf("expected the player to have 70 health, got %d", health.Current)
+	}
+	if damage := ecs.GetComponent[*component.Damage](world, player); len(damage.Records) != 0 {
+		t.Errorf("expected the damage to be cleared, got %+v", damage.Records)
+	}
+
+	// the mob is replaced by its corpse, which has its sword
+	if world.GetEntity(mob) != nil {
+		t.Fatal("expected the dead mob to be removed")
+	}
+
+	corpses := world.EntitiesNamed("corpse")
+	if len(corpses) != 1 {
+		t.Fatalf("expected one corpse, got %v", corpses)
+	}
+
+	corpseLocation := ecs.GetComponent[*component.Location](world, corpses[0])
+	if corpseLocation.X != 3 || corpseLocation.Y != 4 {
+		t.Errorf("expected the corpse at 3,4, got %d,%d", corpseLocation.X, corpseLocation.Y)
+	}
+	if render := ecs.GetComponent[*component.Render](world, corpses[0]); render.Glyph != '%' || render.Layer >= 0 {
+		t.Errorf("expected the corpse to be drawn as %% under everything else, got %+v", render)
+	}
+	if inventory := ecs.GetComponent[*component.Inventory](world, corpses[0]); inventory.Count("sword") != 1 {
+		t.Errorf("expected the corpse to have the mob's sword, got %+v", inventory.Items)
+	}
+
+	// the player doesn't leave a corpse
+	ecs.GetComponent[*component.Damage](world, player).RecordDamage(100, "trap")
+	world.Update(1)
+
+	if world.GetEntity(player) == nil || len(world.EntitiesNamed("corpse")) != 1 {
+		t.Error("expected the player to stay in the world when they die")
+	}
+}
+
+func TestInjury_Death(t *testing.T) {
+	// one death is seen by every system that cares about it, before the
+	// entity is removed
+	world := ecs.NewWorld()
+	var messages []string
+	experience := &system.Experience{Notify: func(message string) { messages = append(messages, message) }}
+	if err := world.AddSystems(&system.Injury{}, &system.Corpses{}, experience, &system.Reaper{}); err != nil {
+		t.Fatal(err)
+	}
+
+	player := world.AddEntity(&entity.Player{})
+	experience.Player = player
+	mob := world.AddEntity(&entity.Mob{})
+	world.MoveEntity(mob, 3, 4)
+	ecs.GetComponent[*component.Inventory](world, mob).AddItem(component.Item{Name: "gold", Weight: 1, Quantity: 5})
+
+	damage := ecs.GetComponent[*component.Damage](world, mob)
+	damage.RecordDamage(60, "trap")
+	damage.RecordAttack(60, "yourself", player)
+
+	world.Update(1)
+
+	if world.GetEntity(mob) != nil {
+		t.Error("expected the dead mob to be removed at the end of the frame")
+	}
+
+	// the loot was dropped
+	corpses := component.EntitiesAt(world, 3, 4)
+	if len(corpses) != 1 || world.GetEntity(corpses[0]).EntityName() != "corpse" {
+		t.Fatalf("expected a corpse where the mob died, got %v", corpses)
+	}
+	if inventory := ecs.GetComponent[*component.Inventory](world, corpses[0]); inventory.Count("gold") != 5 {
+		t.Errorf("expected the corpse to have the mob's gold, got %+v", inventory.Items)
+	}
+
+	// and the player, who dealt the killing blow, earned the experience
+	if points := ecs.GetComponent[*component.Experience](world, player).Points; points != 10 {
+		t.Errorf("expected the player to earn 10 experience, got %d", points)
+	}
+	if len(messages) != 1 || messages[0] != "You gain 10 experience." {
+		t.Errorf("expected the player to be told about the experience, got %q", messages)
+	}
+
+	// nothing is earned for a death the player didn't cause
+	other := world.AddEntity(&entity.Mob{})
+	ecs.GetComponent[*component.Damage](world, other).RecordDamage(200, "trap")
+	world.Update(1)
+
+	if world.GetEntity(other) != nil {
+		t.Error("expected the second mob to be removed")
+	}
+	if points := ecs.GetComponent[*component.Experience](world, player).Points; points != 10 {
+		t.Errorf("expected no experience for a mob killed by a trap, got %d", points)
+	}
+}
@@ -1,27 +1,169 @@
 package system
 
 import (
+	"log/slog"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/matjam/sword/internal/camera"
 	"github.com/matjam/sword/internal/ecs"
 	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/tilemap"
 )
 
 // Ensure that we're implementing the ecs.System interface.
 var _ = ecs.System(&Input{})
 
+// Action is something the player can do by pressing a key.
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionMoveNorth
+	ActionMoveSouth
+	ActionMoveEast
+	ActionMoveWest
+	ActionMoveNorthEast
+	ActionMoveNorthWest
+	ActionMoveSouthEast
+	ActionMoveSouthWest
+	ActionWait
+	ActionRest
+	ActionLook
+	ActionCancel
+	ActionFreeCamera
+	ActionCenterCamera
+)
+
+// DefaultRestTurns is the most turns the player rests for if RestTurns isn't
+// set.
+const DefaultRestTurns = 100
+
+// actionDirections is how far each of the move actions moves the player.
+var actionDirections = map[Action][2]int{
+	ActionMoveNorth:     {0, -1},
+	ActionMoveSouth:     {0, 1},
+	ActionMoveEast:      {1, 0},
+	ActionMoveWest:      {-1, 0},
+	ActionMoveNorthEast: {1, -1},
+	ActionMoveNorthWest: {-1, -1},
+	ActionMoveSouthEast: {1, 1},
+	ActionMoveSouthWest: {-1, 1},
+}
+
+// Keymap maps keys to the actions they perform. Several keys can perform the
+// same action.
+type Keymap map[ebiten.Key]Action
+
+// DefaultKeymap returns the keys used if the Input system isn't given a
+// Keymap: WASD, the arrow keys, and the vi keys that roguelike players
+// expect, with yubn for the diagonals.
+func DefaultKeymap() Keymap {
+	return Keymap{
+		ebiten.KeyW: ActionMoveNorth,
+		ebiten.KeyS: ActionMoveSouth,
+		ebiten.KeyD: ActionMoveEast,
+		ebiten.KeyA: ActionMoveWest,
+
+		ebiten.KeyArrowUp:    ActionMoveNorth,
+		ebiten.KeyArrowDown:  ActionMoveSouth,
+		ebiten.KeyArrowRight: ActionMoveEast,
+		ebiten.KeyArrowLeft:  ActionMoveWest,
+
+		ebiten.KeyK: ActionMoveNorth,
+		ebiten.KeyJ: ActionMoveSouth,
+		ebiten.KeyL: ActionMoveEast,
+		ebiten.KeyH: ActionMoveWest,
+		ebiten.KeyU: ActionMoveNorthEast,
+		ebiten.KeyY: ActionMoveNorthWest,
+		ebiten.KeyN: ActionMoveSouthEast,
+		ebiten.KeyB: ActionMoveSouthWest,
+
+		ebiten.KeyPeriod:  ActionWait,
+		ebiten.KeyNumpad5: ActionWait,
+		ebiten.KeyR:       ActionRest,
+
+		ebiten.KeyX:         ActionLook,
+		ebiten.KeySemicolon: ActionLook,
+		ebiten.KeyEscape:    ActionCancel,
+
+		ebiten.KeyC:    ActionFreeCamera,
+		ebiten.KeyHome: ActionCenterCamera,
+	}
+}
+
 type Input struct {
 	world  *ecs.World
 	Player ecs.EntityID
 	keys   []ebiten.Key
+
+	// Keymap is the keys the player can press, and what they do. If it is
+	// nil when the system is added to the world, DefaultKeymap is used.
+	Keymap Keymap
+
+	// Tilemap is used to check whether the player can see any mobs while
+	// resting. If it is nil, mobs never interrupt a rest.
+	Tilemap *tilemap.Grid
+
+	// Camera is the camera that ActionFreeCamera lets the player pan around.
+	// If it is nil, the world's *camera.Camera resource is used, and if
+	// there isn't one, the camera actions do nothing.
+	Camera *camera.Camera
+
+	// RestTurns is the most turns ActionRest rests for, if the player isn't
+	// healed or interrupted first. If it is zero, DefaultRestTurns is used.
+	RestTurns int
+
+	// Ready, if set, returns whether the player can act this frame, such as
+	// once an animation has finished. Until it does, key presses are held in
+	// Queue. If it is nil, the player can always act.
+	Ready func() bool
+
+	// Queue holds the actions for keys that have been pressed but not acted
+	// on yet. One action is taken off it every time the player can act. Set
+	// Queue.Size to change how many presses are remembered.
+	Queue ActionQueue
+
+	// Record, if set, has every action the player takes added to it, so that
+	// the game can be replayed later.
+	Record *CommandLog
+
+	// Replay, if set, is where the player's actions come from instead of the
+	// keyboard. Each command is carried out on the turn it was recorded on.
+	// Once every command has been replayed, the player does nothing more.
+	Replay *CommandLog
+
+	// Describe, if set, is given the description of whatever is under the
+	// cursor in look mode each time the cursor moves. If it is nil, the
+	// description is logged.
+	Describe func(description string)
+
+	// resting is the number of turns the player has left to rest for, and
+	// untilHealed is whether the rest stops once the player is at full
+	// health. lastHealth and lastDamage are the player's health and number
+	// of damage records when the last turn of the rest ended, so that we can
+	// tell when they've been hurt.
+	resting     int
+	untilHealed bool
+	lastHealth  int
+	lastDamage  int
+
+	// looking is true while the player is in look mode, moving a cursor
+	// around instead of themselves. cursorX and cursorY are where the cursor
+	// is.
+	looking          bool
+	cursorX, cursorY int
 }
 
 // Init initializes the system.
 func (sys *Input) Init(world *ecs.World) {
 	sys.world = world
 	sys.keys = make([]ebiten.Key, 0, 20)
+
+	if sys.Keymap == nil {
+		sys.Keymap = DefaultKeymap()
+	}
 }
 
 // SystemName returns the name of the system.
@@ -38,31 +180,206 @@ func (sys *Input) Components() []ecs.Component {
 
 // Update updates the system.
 func (sys *Input) Update(deltaTime time.Duration) {
+	if sys.Replay != nil {
+		sys.replay()
+		return
+	}
+
+	pressed := false
+
 	sys.keys = inpututil.AppendPressedKeys(sys.keys[:0])
 	for _, key := range sys.keys {
-		switch key {
-		case ebiten.KeyW:
-			if inpututil.IsKeyJustPressed(ebiten.KeyW) {
-				sys.movePlayer(0, -1)
-			}
-		case ebiten.KeyS:
-			if inpututil.IsKeyJustPressed(ebiten.KeyS) {
-				sys.movePlayer(0, 1)
-			}
-		case ebiten.KeyA:
-			if inpututil.IsKeyJustPressed(ebiten.KeyA) {
-				sys.movePlayer(-1, 0)
-			}
-		case ebiten.KeyD:
-			if inpututil.IsKeyJustPressed(ebiten.KeyD) {
-				sys.movePlayer(1, 0)
-			}
+		if !inpututil.IsKeyJustPressed(key) {
+			continue
+		}
+
+		pressed = true
+		if action, ok := sys.Keymap[key]; ok {
+			sys.Queue.Push(action)
 		}
 	}
+
+	if sys.resting > 0 {
+		// pressing any key stops the player resting, and is otherwise
+		// ignored.
+		if pressed {
+			slog.Debug("rest cancelled")
+			sys.resting = 0
+			sys.Queue.Clear()
+			return
+		}
+
+		sys.rest()
+		return
+	}
+
+	if sys.Ready != nil && !sys.Ready() {
+		return
+	}
+
+	// the player only gets to do one thing per turn
+	action, ok := sys.Queue.Pop()
+	if !ok {
+		return
+	}
+
+	// looking around doesn't take a turn and doesn't change the game, so it
+	// isn't recorded.
+	if sys.looking || action == ActionLook {
+		sys.look(action)
+		return
+	}
+
+	// neither does moving the camera around
+	if sys.IsCameraFree() || action == ActionFreeCamera || action == ActionCenterCamera {
+		sys.moveCamera(action)
+		return
+	}
+
+	sys.perform(action)
+}
+
+// replay carries out the next command in the Replay log, once its turn has
+// come.
+func (sys *Input) replay() {
+	command, ok := sys.Replay.Peek()
+
+	if sys.resting > 0 {
+		// the player can only have acted in the middle of a rest if they
+		// pressed a key to cancel it, so the rest ends when the next command
+		// is due.
+		if !ok || command.Turn != sys.world.Turn() {
+			sys.rest()
+			return
+		}
+		sys.resting = 0
+	}
+
+	if !ok || (sys.Ready != nil && !sys.Ready()) {
+		return
+	}
+
+	if command.Turn != sys.world.Turn() {
+		slog.Error("replay is out of step with the game, stopping it",
+			"turn", sys.world.Turn(), "expected", command.Turn)
+		sys.Replay = nil
+		return
+	}
+
+	sys.Replay.Next()
+	sys.perform(command.Action)
+}
+
+// perform carries out the given action for the player.
+func (sys *Input) perform(action Action) {
+	if sys.Record != nil {
+		sys.Record.Record(sys.world.Turn(), action)
+	}
+
+	if direction, ok := actionDirections[action]; ok {
+		sys.movePlayer(direction[0], direction[1])
+		return
+	}
+
+	switch action {
+	case ActionWait:
+		// waiting uses up the player's turn without doing anything
+		sys.world.EndTurn()
+	case ActionRest:
+		turns := sys.RestTurns
+		if turns == 0 {
+			turns = DefaultRestTurns
+		}
+		sys.Rest(turns, true)
+	}
+}
+
+// Rest makes the player wait for up to the given number of turns, one turn
+// every Update so that the rest of the world keeps moving while they do. If
+// untilHealed is true, the rest stops as soon as the player is at full
+// health. The rest is interrupted if the player takes damage, a mob comes
+// into view, or a key is pressed.
+func (sys *Input) Rest(turns int, untilHealed bool) {
+	sys.resting = turns
+	sys.untilHealed = untilHealed
+	sys.lastHealth, sys.lastDamage = sys.playerHealth()
+}
+
+// IsResting returns true if the player is in the middle of a rest.
+func (sys *Input) IsResting() bool {
+	return sys.resting > 0
+}
+
+// rest spends one turn of the current rest, unless something has happened
+// that should stop it.
+func (sys *Input) rest() {
+	health, damage := sys.playerHealth()
+
+	switch {
+	case health < sys.lastHealth || damage > sys.lastDamage:
+		slog.Info("rest interrupted by taking damage")
+	case sys.mobInView():
+		slog.Info("rest interrupted by a mob coming into view")
+	case sys.untilHealed && sys.isHealed():
+		slog.Debug("rested until healed")
+	default:
+		sys.lastHealth, sys.lastDamage = health, damage
+		sys.resting--
+		sys.world.EndTurn()
+		return
+	}
+
+	sys.resting = 0
+}
+
+// playerHealth returns the player's current health and how many damage
+// records they have, or zero for either component they don't have.
+func (sys *Input) playerHealth() (health int, damage int) {
+	if sys.world.HasComponent(sys.Player, &component.Health{}) {
+		health = ecs.GetComponent[*component.Health](sys.world, sys.Player).Current
+	}
+	if sys.world.HasComponent(sys.Player, &component.Damage{}) {
+		damage = len(ecs.GetComponent[*component.Damage](sys.world, sys.Player).Records)
+	}
+	return health, damage
+}
+
+// isHealed returns true if the player is at full health, or doesn't have any
+// health to heal.
+func (sys *Input) isHealed() bool {
+	if !sys.world.HasComponent(sys.Player, &component.Health{}) {
+		return true
+	}
+
+	health := ecs.GetComponent[*component.Health](sys.world, sys.Player)
+	return health.Current >= health.Max
+}
+
+// mobInView returns true if there's a mob the player can see.
+func (sys *Input) mobInView() bool {
+	if sys.Tilemap == nil {
+		return false
+	}
+
+	player := ecs.GetComponent[*component.Location](sys.world, sys.Player)
+	for _, mob := range sys.world.EntitiesNamed("mob") {
+		if !sys.world.HasComponent(mob, &component.Location{}) {
+			continue
+		}
+
+		location := ecs.GetComponent[*component.Location](sys.world, mob)
+		if sys.Tilemap.IsVisible(player.X, player.Y, location.X, location.Y) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (sys *Input) movePlayer(x, y int) {
 	movable := ecs.GetComponent[*component.Move](sys.world, sys.Player)
 	movable.X = x
 	movable.Y = y
+
+	sys.world.EndTurn()
 }
@@ -0,0 +1,63 @@
+package system
+
+import (
+	"time"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/tilemap"
+)
+
+// Ensure that we're implementing the ecs.System interface.
+var _ = ecs.System(&Lighting{})
+
+// Lighting works out how brightly lit every tile of the tilemap is, from the
+// entities with a LightSource component. The light is recomputed from scratch
+// once every turn, since that's the only time anything can move.
+type Lighting struct {
+	world *ecs.World
+
+	// Tilemap is the map to light.
+	Tilemap *tilemap.Grid
+
+	// lastTurn is the turn the light was last computed for, and computed is
+	// whether it has been computed at all.
+	lastTurn uint64
+	computed bool
+}
+
+// Init initializes the system.
+func (sys *Lighting) Init(world *ecs.World) {
+	sys.world = world
+}
+
+// SystemName returns the name of the system.
+func (sys *Lighting) SystemName() ecs.SystemName {
+	return "lighting"
+}
+
+// Components returns the components that the system is interested in.
+func (sys *Lighting) Components() []ecs.Component {
+	return []ecs.Component{
+		&component.LightSource{},
+		&component.Location{},
+	}
+}
+
+// Update updates the system.
+func (sys *Lighting) Update(deltaTime time.Duration) {
+	if sys.computed && sys.lastTurn == sys.world.Turn() {
+		return
+	}
+	sys.lastTurn = sys.world.Turn()
+	sys.computed = true
+
+	sys.Tilemap.ClearLight()
+
+	sys.world.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
+		light := ecs.GetComponentID[*component.LightSource](sys.world, components["light_source"])
+		location := ecs.GetComponentID[*component.Location](sys.world, components["location"])
+
+		sys.Tilemap.AddLight(location.X, location.Y, light.Radius, light.Intensity)
+	})
+}
@@ -0,0 +1,115 @@
+package system
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/tilemap"
+)
+
+// IsLooking returns true if the player is in look mode.
+func (sys *Input) IsLooking() bool {
+	return sys.looking
+}
+
+// LookCursor returns the tile the look cursor is on, and whether the player is
+// in look mode at all.
+func (sys *Input) LookCursor() (x, y int, ok bool) {
+	return sys.cursorX, sys.cursorY, sys.looking
+}
+
+// look handles an action while the player is in look mode. ActionLook starts
+// it with the cursor on the player, the move actions move the cursor, and
+// ActionLook or ActionCancel leave it again. Anything else is ignored until
+// the player stops looking.
+func (sys *Input) look(action Action) {
+	if !sys.looking {
+		location := ecs.GetComponent[*component.Location](sys.world, sys.Player)
+		sys.looking = true
+		sys.cursorX, sys.cursorY = location.X, location.Y
+		sys.describe()
+		return
+	}
+
+	if direction, ok := actionDirections[action]; ok {
+		sys.cursorX += direction[0]
+		sys.cursorY += direction[1]
+		sys.describe()
+		return
+	}
+
+	if action == ActionLook || action == ActionCancel {
+		sys.looking = false
+	}
+}
+
+// describe passes the description of the tile under the cursor to Describe.
+func (sys *Input) describe() {
+	description := sys.DescribeTile(sys.cursorX, sys.cursorY)
+	if sys.Describe == nil {
+		slog.Info(description)
+		return
+	}
+	sys.Describe(description)
+}
+
+// DescribeTile returns a sentence or two saying what's on the given tile: the
+// first entity there that has a Description, and what the floor is. If there
+// is no Tilemap, only the entity is described.
+//
+// Only what the player knows about is described. Tiles that have never been
+// seen aren't described at all, entities are only described on tiles that
+// can be seen right now, and traps that haven't been revealed are described
+// as the floor they look like.
+func (sys *Input) DescribeTile(x, y int) string {
+	floor := ""
+	visible := true
+	if sys.Tilemap != nil {
+		tile := sys.Tilemap.GetTile(x, y)
+		if tile == nil {
+			return "You see nothing there."
+		}
+		if !tile.Seen {
+			return "You can't see that."
+		}
+
+		tileType := tile.Type
+		if tileType == tilemap.TileTypeTrap && !tile.Revealed {
+			tileType = tilemap.TileTypeFloor
+		}
+		floor = strings.ReplaceAll(tileType.String(), "_", " ")
+		visible = tile.Visible
+	}
+
+	var description *component.Description
+	if visible {
+		for _, entityID := range component.EntitiesAt(sys.world, x, y) {
+			if sys.world.HasComponent(entityID, &component.Description{}) {
+				description = ecs.GetComponent[*component.Description](sys.world, entityID)
+				break
+			}
+		}
+	}
+
+	var sb strings.Builder
+	switch {
+	case description != nil && floor != "":
+		fmt.Fprintf(&sb, "You see %s on the %s.", description.Short, floor)
+	case description != nil:
+		fmt.Fprintf(&sb, "You see %s.", description.Short)
+	case floor != "":
+		fmt.Fprintf(&sb, "You see the %s.", floor)
+	default:
+		sb.WriteString("You see nothing there.")
+	}
+
+	if description != nil && description.Long != "" {
+		sb.WriteString(" ")
+		sb.WriteString(description.Long)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,112 @@
+package system_test
+
+import (
+	"testing"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/ecs/entity"
+	"github.com/matjam/sword/internal/ecs/system"
+	"github.com/matjam/sword/internal/tilemap"
+)
+
+func TestLook(t *testing.T) {
+	tm := tilemap.NewGrid(10, 10)
+	for x := 0; x < 10; x++ {
+		tm.SetTile(x, 2, &tilemap.Tile{Type: tilemap.TileTypeFloor})
+	}
+	tm.ComputeFOV(2, 2, 10)
+
+	var described []string
+	world := ecs.NewWorld()
+	input := &system.Input{
+		Tilemap:  tm,
+		Describe: func(description string) { described = append(described, description) },
+	}
+	if err := world.AddSystems(input, &system.Movement{Tilemap: tm}); err != nil {
+		t.Fatal(err)
+	}
+
+	input.Player = world.AddEntity(&entity.Player{})
+	world.MoveEntity(input.Player, 2, 2)
+	player := ecs.GetComponent[*component.Location](world, input.Player)
+
+	mob := world.AddEntity(&entity.Mob{})
+	world.MoveEntity(mob, 3, 2)
+
+	for _, action := range []system.Action{
+		system.ActionLook,
+		system.ActionMoveEast,
+		system.ActionMoveEast,
+		system.ActionMoveNorth,
+		system.ActionCancel,
+	} {
+		input.Queue.Push(action)
+		world.Update(1)
+	}
+
+	expected := []string{
+		"You see yourself on the floor.",
+		"You see a monster on the floor.",
+		"You see the floor.",
+		"You see the wall.",
+	}
+	if len(described) != len(expected) {
+		t.Fatalf("expected %d descriptions, got %q", len(expected), described)
+	}
+	for i := range expected {
+		if described[i] != expected[i] {
+			t.Errorf("expected description %d to be %q, got %q", i, expected[i], described[i])
+		}
+	}
+
+	// looking around doesn't move the player or use up any turns
+	if input.IsLooking() {
+		t.Error("expected ActionCancel to leave look mode")
+	}
+	if player.X != 2 || player.Y != 2 {
+		t.Errorf("expected the player to stay at 2,2, got %d,%d", player.X, player.Y)
+	}
+	if world.Turn() != 0 {
+		t.Errorf("expected no turns to pass, got %d", world.Turn())
+	}
+}
+
+func TestLook_HiddenThings(t *testing.T) {
+	tm := tilemap.NewGrid(10, 10)
+	for x := 0; x < 10; x++ {
+		tm.SetTile(x, 2, &tilemap.Tile{Type: tilemap.TileTypeFloor})
+	}
+	tm.SetTile(3, 2, &tilemap.Tile{Type: tilemap.TileTypeTrap})
+	tm.SetTile(5, 2, &tilemap.Tile{Type: tilemap.TileTypeClosedDoor})
+	tm.ComputeFOV(2, 2, 10)
+
+	world := ecs.NewWorld()
+	input := &system.Input{Tilemap: tm}
+	if err := world.AddSystems(input); err != nil {
+		t.Fatal(err)
+	}
+
+	// a mob behind the closed door
+	mob := world.AddEntity(&entity.Mob{})
+	world.MoveEntity(mob, 7, 2)
+
+	if got := input.DescribeTile(3, 2); got != "You see the floor." {
+		t.Errorf("expected a hidden trap to look like the floor, got %q", got)
+	}
+	if got := input.DescribeTile(7, 2); got != "You can't see that." {
+		t.Errorf("expected a tile that hasn't been seen not to be described, got %q", got)
+	}
+
+	tm.GetTile(3, 2).Revealed = true
+	if got := input.DescribeTile(3, 2); got != "You see the trap." {
+		t.Errorf("expected a revealed trap to be described, got %q", got)
+	}
+
+	// once the far side has been seen, the floor is remembered, but the mob
+	// can't be seen through the closed door
+	tm.GetTile(7, 2).Seen = true
+	if got := input.DescribeTile(7, 2); got != "You see the floor." {
+		t.Errorf("expected only the remembered floor, got %q", got)
+	}
+}
@@ -5,13 +5,50 @@ import (
 
 	"github.com/matjam/sword/internal/ecs"
 	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/tilemap"
 )
 
 // Ensure that we're implementing the ecs.System interface.
 var _ = ecs.System(&Movement{})
 
+// DefaultTrapDamage is the damage dealt by a trap if TrapDamage isn't set.
+const DefaultTrapDamage = 10
+
+// DefaultBumpDamage is the damage dealt by bumping into an entity if
+// BumpDamage isn't set.
+const DefaultBumpDamage = 10
+
+// burdenedCooldown is the name of the cooldown that stops a burdened entity
+// moving on the turn after it last moved.
+const burdenedCooldown = "burdened"
+
+// Movement moves every entity by its Move component, one step at a time. It
+// also slows down entities that are carrying too much: overloaded entities
+// can't move at all, and burdened entities that have Cooldowns can only move
+// every other turn. See component.Encumbrance.
 type Movement struct {
 	world *ecs.World
+
+	// Tilemap is the map that entities are moving around. If it is set,
+	// entities can't move into walls or closed doors, or squeeze diagonally
+	// between two wall corners, and any entity that steps onto a trap
+	// reveals it and, if it has a Damage component, takes TrapDamage damage.
+	// If it is nil, the world's *tilemap.Grid resource is used instead, and
+	// if there isn't one either, nothing gets in the way.
+	Tilemap *tilemap.Grid
+
+	// OnBlocked, if set, is called whenever an entity's move is stopped by
+	// the tilemap, with the tile it tried to move onto.
+	OnBlocked func(entityID ecs.EntityID, x, y int)
+
+	// TrapDamage is the damage dealt by stepping on a trap. If it is zero,
+	// DefaultTrapDamage is used.
+	TrapDamage int
+
+	// BumpDamage is the damage dealt to a Blocker entity when something tries
+	// to move onto its tile, which is how entities attack each other. If it
+	// is zero, DefaultBumpDamage is used.
+	BumpDamage int
 }
 
 // Init initializes the system.
@@ -38,12 +75,158 @@ func (sys *Movement) Update(deltaTime time.Duration) {
 		location := ecs.GetComponentID[*component.Location](sys.world, components["location"])
 		movable := ecs.GetComponentID[*component.Move](sys.world, components["move"])
 
-		// move the entity
-		location.X += movable.X
-		location.Y += movable.Y
+		if movable.X == 0 && movable.Y == 0 {
+			return
+		}
+
+		dx, dy := movable.X, movable.Y
 
 		// reset the movable component
 		movable.X = 0
 		movable.Y = 0
+
+		entityID := sys.world.EntityForComponent(components["location"])
+		if !sys.canMove(entityID) {
+			return
+		}
+
+		// moves are a single step in any of the eight directions
+		if !sys.canStep(location, dx, dy) {
+			if sys.OnBlocked != nil {
+				sys.OnBlocked(entityID, location.X+dx, location.Y+dy)
+			}
+			return
+		}
+
+		// walking into a wall doesn't take any time, but moving or
+		// attacking does
+		sys.slowDown(entityID)
+
+		// if something is in the way, we attack it instead of moving
+		if blocker, ok := sys.blockerAt(entityID, location.X+dx, location.Y+dy); ok {
+			sys.bump(entityID, blocker)
+			return
+		}
+
+		// move the entity
+		sys.world.MoveEntity(entityID, location.X+dx, location.Y+dy)
+
+		sys.triggerTrap(entityID, location)
 	})
 }
+
+// canMove returns true if the entity isn't too encumbered to move this turn.
+func (sys *Movement) canMove(entityID ecs.EntityID) bool {
+	if !sys.world.HasComponent(entityID, &component.Encumbrance{}) {
+		return true
+	}
+
+	switch ecs.GetComponent[*component.Encumbrance](sys.world, entityID).Level {
+	case component.Overloaded:
+		return false
+	case component.Burdened:
+		if !sys.world.HasComponent(entityID, &component.Cooldowns{}) {
+			return true
+		}
+
+		return ecs.GetComponent[*component.Cooldowns](sys.world, entityID).Ready(burdenedCooldown)
+	}
+
+	return true
+}
+
+// slowDown makes a burdened entity that has just moved wait a turn before it
+// moves again.
+func (sys *Movement) slowDown(entityID ecs.EntityID) {
+	if !sys.world.HasComponent(entityID, &component.Encumbrance{}) || !sys.world.HasComponent(entityID, &component.Cooldowns{}) {
+		return
+	}
+	if ecs.GetComponent[*component.Encumbrance](sys.world, entityID).Level != component.Burdened {
+		return
+	}
+
+	// the cooldown is counted down at the end of this turn and the next, so
+	// it's ready again the turn after that
+	ecs.GetComponent[*component.Cooldowns](sys.world, entityID).Trigger(burdenedCooldown, 2)
+}
+
+// tilemap returns the map entities are moving around, or nil if there isn't
+// one.
+func (sys *Movement) tilemap() *tilemap.Grid {
+	if sys.Tilemap != nil {
+		return sys.Tilemap
+	}
+
+	tm, _ := ecs.GetResource[*tilemap.Grid](sys.world)
+	return tm
+}
+
+// canStep returns true if an entity at the given location can step by dx,dy.
+// Without a tilemap there's nothing to bump into, so every step is allowed.
+func (sys *Movement) canStep(location *component.Location, dx, dy int) bool {
+	tm := sys.tilemap()
+	if tm == nil {
+		return true
+	}
+
+	return tm.CanStep(location.X, location.Y, dx, dy)
+}
+
+// blockerAt returns the Blocker entity at the given tile, other than the one
+// that is moving, if there is one.
+func (sys *Movement) blockerAt(mover ecs.EntityID, x, y int) (ecs.EntityID, bool) {
+	for _, entityID := range component.EntitiesAt(sys.world, x, y) {
+		if entityID != mover && sys.world.HasComponent(entityID, &component.Blocker{}) {
+			return entityID, true
+		}
+	}
+	return 0, false
+}
+
+// bump makes the mover attack the entity it bumped into, if it can be hurt.
+func (sys *Movement) bump(mover, target ecs.EntityID) {
+	if !sys.world.HasComponent(target, &component.Damage{}) {
+		return
+	}
+
+	amount := sys.BumpDamage
+	if amount == 0 {
+		amount = DefaultBumpDamage
+	}
+
+	// the damage is recorded as coming from the kind of entity the mover
+	// is, rather than its description, which for the player is "yourself".
+	source := string(sys.world.GetEntity(mover).EntityName())
+
+	damage := ecs.GetComponent[*component.Damage](sys.world, target)
+	damage.RecordAttack(amount, source, mover)
+	sys.world.MarkChanged(target, "damage")
+}
+
+// triggerTrap springs the trap at the given location, if there is one.
+func (sys *Movement) triggerTrap(entityID ecs.EntityID, location *component.Location) {
+	tm := sys.tilemap()
+	if tm == nil {
+		return
+	}
+
+	tile := tm.GetTile(location.X, location.Y)
+	if tile == nil || tile.Type != tilemap.TileTypeTrap {
+		return
+	}
+
+	tile.Revealed = true
+
+	if !sys.world.HasComponent(entityID, &component.Damage{}) {
+		return
+	}
+
+	amount := sys.TrapDamage
+	if amount == 0 {
+		amount = DefaultTrapDamage
+	}
+
+	damage := ecs.GetComponent[*component.Damage](sys.world, entityID)
+	damage.RecordDamage(amount, "trap")
+	sys.world.MarkChanged(entityID, "damage")
+}
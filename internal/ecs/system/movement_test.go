@@ -0,0 +1,107 @@
+package system_test
+
+import (
+	"testing"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/ecs/entity"
+	"github.com/matjam/sword/internal/ecs/system"
+	"github.com/matjam/sword/internal/tilemap"
+)
+
+func TestMovement_Blocker(t *testing.T) {
+	world := ecs.NewWorld()
+	if err := world.AddSystem(&system.Movement{BumpDamage: 7}); err != nil {
+		t.Fatal(err)
+	}
+
+	player := world.AddEntity(&entity.Player{})
+	world.MoveEntity(player, 2, 2)
+	location := ecs.GetComponent[*component.Location](world, player)
+
+	corpse := world.AddEntity(&entity.Corpse{})
+	world.MoveEntity(corpse, 3, 2)
+
+	mob := world.AddEntity(&entity.Mob{})
+	world.MoveEntity(mob, 4, 2)
+	mobLocation := ecs.GetComponent[*component.Location](world, mob)
+
+	move := ecs.GetComponent[*component.Move](world, player)
+
+	// corpses don't block, so the player walks onto it
+	move.X = 1
+	world.Update(1)
+	if location.X != 3 || location.Y != 2 {
+		t.Fatalf("expected the player to walk over the corpse to 3,2, got %d,%d", location.X, location.Y)
+	}
+
+	// mobs do, so the player attacks it and stays where they are
+	move.X = 1
+	world.Update(1)
+	if location.X != 3 || location.Y != 2 {
+		t.Errorf("expected the player to stay at 3,2, got %d,%d", location.X, location.Y)
+	}
+	if mobLocation.X != 4 || mobLocation.Y != 2 {
+		t.Errorf("expected the mob to stay at 4,2, got %d,%d", mobLocation.X, mobLocation.Y)
+	}
+
+	records := ecs.GetComponent[*component.Damage](world, mob).Records
+	if len(records) != 1 || records[0].Amount != 7 || records[0].Source != "player" {
+		t.Errorf("expected the mob to take 7 damage from the player, got %+v", records)
+	}
+}
+
+func TestMovement_TilemapResource(t *testing.T) {
+	// a corridor along y 2, with walls everywhere else
+	tm := tilemap.NewGrid(10, 5)
+	for x := 1; x < 9; x++ {
+		tm.SetTile(x, 2, &tilemap.Tile{Type: tilemap.TileTypeFloor})
+	}
+
+	var blocked [][2]int
+	world := ecs.NewWorld()
+	if err := world.AddSystem(&system.Movement{
+		OnBlocked: func(entityID ecs.EntityID, x, y int) { blocked = append(blocked, [2]int{x, y}) },
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	player := world.AddEntity(&entity.Player{})
+	world.MoveEntity(player, 2, 2)
+	location := ecs.GetComponent[*component.Location](world, player)
+	move := ecs.GetComponent[*component.Move](world, player)
+
+	// without a tilemap, nothing is in the way
+	move.Y = 1
+	world.Update(1)
+	if location.Y != 3 {
+		t.Fatalf("expected the player to move without a tilemap, got %d,%d", location.X, location.Y)
+	}
+	world.MoveEntity(player, 2, 2)
+
+	world.SetResource(tm)
+
+	// the wall stops the move, and the move is used up
+	move.Y = 1
+	world.Update(1)
+	if location.X != 2 || location.Y != 2 {
+		t.Errorf("expected the wall to stop the player at 2,2, got %d,%d", location.X, location.Y)
+	}
+	if move.X != 0 || move.Y != 0 {
+		t.Errorf("expected the move to be reset, got %d,%d", move.X, move.Y)
+	}
+	if len(blocked) != 1 || blocked[0] != [2]int{2, 3} {
+		t.Errorf("expected to be told the move into 2,3 was blocked, got %v", blocked)
+	}
+
+	// the floor doesn't
+	move.X = 1
+	world.Update(1)
+	if location.X != 3 || location.Y != 2 {
+		t.Errorf("expected the player to move along the corridor to 3,2, got %d,%d", location.X, location.Y)
+	}
+	if len(blocked) != 1 {
+		t.Errorf("expected no more blocked moves, got %v", blocked)
+	}
+}
@@ -0,0 +1,168 @@
+package system
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/matjam/sword/internal/camera"
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/grid"
+)
+
+// Ensure that we're implementing the ecs.RenderSystem interface.
+var _ = ecs.RenderSystem(&PathOverlay{})
+
+// DefaultPathColor is the color a path is drawn in if PathOverlay.PathColor
+// isn't set.
+var DefaultPathColor = color.RGBA{R: 0, G: 160, B: 255, A: 160}
+
+// heatAlpha is how opaque the cells of a distance map are drawn, so that the
+// map underneath can still be seen.
+const heatAlpha = 96
+
+// PathOverlay draws pathfinding debug information over the map, to help when
+// tuning AI: a distance map as a heat gradient from red (close) to blue (far),
+// and a path as a line through a trail of colored cells on top of it. Whatever
+// computes them sets Path and Distances, and the overlay draws the latest ones
+// each frame. It is a development aid, so it is off by default, and can be
+// toggled with F4 or by setting Enabled.
+type PathOverlay struct {
+	world *ecs.World
+
+	// Camera is used to work out where each cell is on the screen.
+	Camera *camera.Camera
+
+	// Enabled is whether the overlay is drawn. F4 toggles it.
+	Enabled bool
+
+	// Path is a list of tile positions, in the order they are walked.
+	Path [][2]int
+
+	// PathColor is the color the path is drawn in. If it is nil,
+	// DefaultPathColor is used.
+	PathColor color.Color
+
+	// Distances is a distance map, such as a Dijkstra map, the same size as
+	// the tilemap. Negative distances are unreachable and aren't drawn.
+	Distances *grid.Grid[int]
+}
+
+// Init initializes the system.
+func (sys *PathOverlay) Init(world *ecs.World) {
+	sys.world = world
+}
+
+// SystemName returns the name of the system.
+func (sys *PathOverlay) SystemName() ecs.SystemName {
+	return "path_overlay"
+}
+
+// Components returns the components that the system is interested in. The
+// overlay only draws what it's given, so it doesn't need any.
+func (sys *PathOverlay) Components() []ecs.Component {
+	return []ecs.Component{}
+}
+
+// Update toggles the overlay when F4 is pressed.
+func (sys *PathOverlay) Update(deltaTime time.Duration) {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF4) {
+		sys.Enabled = !sys.Enabled
+	}
+}
+
+// Draw draws the distance map and then the path, if the overlay is enabled.
+func (sys *PathOverlay) Draw(screen *ebiten.Image) {
+	if !sys.Enabled {
+		return
+	}
+
+	if sys.Distances != nil {
+		DrawDistanceMap(screen, sys.Camera, sys.Distances)
+	}
+
+	clr := sys.PathColor
+	if clr == nil {
+		clr = DefaultPathColor
+	}
+	DrawPath(screen, sys.Camera, sys.Path, clr)
+}
+
+// DrawPath draws a path over the map as a trail of cells in the given color,
+// with a line joining the middle of each cell to the next. Cells that are off
+// the screen are skipped, but the line still runs through them.
+func DrawPath(screen *ebiten.Image, cam *camera.Camera, path [][2]int, clr color.Color) {
+	if len(path) == 0 {
+		return
+	}
+
+	cellWidth, cellHeight := cam.CellSize()
+	viewport := cam.Viewport(screen.Bounds().Dx(), screen.Bounds().Dy())
+	inset := float32(max(min(cellWidth, cellHeight)/4, 1))
+	lineWidth := float32(max(min(cellWidth, cellHeight)/8, 1))
+
+	for i, p := range path {
+		x, y := cam.TileToScreen(p[0], p[1])
+
+		if (image.Point{p[0], p[1]}).In(viewport) {
+			vector.DrawFilledRect(screen, float32(x)+inset, float32(y)+inset,
+				float32(cellWidth)-inset*2, float32(cellHeight)-inset*2, clr, false)
+		}
+
+		if i > 0 {
+			px, py := cam.TileToScreen(path[i-1][0], path[i-1][1])
+			vector.StrokeLine(screen,
+				float32(px+cellWidth/2), float32(py+cellHeight/2),
+				float32(x+cellWidth/2), float32(y+cellHeight/2),
+				lineWidth, clr, false)
+		}
+	}
+}
+
+// DrawDistanceMap draws a distance map over the map, shading each reachable
+// cell from red at distance 0 to blue at the furthest distance on the map.
+// Only the cells on the screen are drawn.
+func DrawDistanceMap(screen *ebiten.Image, cam *camera.Camera, distances *grid.Grid[int]) {
+	furthest := 0
+	for y := 0; y < distances.Height; y++ {
+		for x := 0; x < distances.Width; x++ {
+			furthest = max(furthest, distances.Get(x, y))
+		}
+	}
+
+	cellWidth, cellHeight := cam.CellSize()
+	viewport := cam.Viewport(screen.Bounds().Dx(), screen.Bounds().Dy()).
+		Intersect(image.Rect(0, 0, distances.Width, distances.Height))
+
+	for y := viewport.Min.Y; y < viewport.Max.Y; y++ {
+		for x := viewport.Min.X; x < viewport.Max.X; x++ {
+			d := distances.Get(x, y)
+			if d < 0 {
+				continue
+			}
+
+			sx, sy := cam.TileToScreen(x, y)
+			vector.DrawFilledRect(screen, float32(sx), float32(sy), float32(cellWidth), float32(cellHeight),
+				heatColor(d, furthest), false)
+		}
+	}
+}
+
+// heatColor returns the color of a cell at distance d on a distance map whose
+// furthest cell is at distance furthest. The color is premultiplied by
+// heatAlpha, as ebiten expects.
+func heatColor(d, furthest int) color.RGBA {
+	ratio := 0.0
+	if furthest > 0 {
+		ratio = float64(min(d, furthest)) / float64(furthest)
+	}
+
+	return color.RGBA{
+		R: uint8((1 - ratio) * heatAlpha),
+		B: uint8(ratio * heatAlpha),
+		A: heatAlpha,
+	}
+}
@@ -0,0 +1,42 @@
+package system
+
+import (
+	"time"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+)
+
+// Ensure that we're implementing the ecs.System interface.
+var _ = ecs.System(&Reaper{})
+
+// Reaper removes every Dead entity from the world. It must be added after
+// every system that reacts to deaths, so that they all see the entity before
+// it's gone.
+type Reaper struct {
+	world *ecs.World
+}
+
+// Init initializes the system.
+func (sys *Reaper) Init(world *ecs.World) {
+	sys.world = world
+}
+
+// SystemName returns the name of the system.
+func (sys *Reaper) SystemName() ecs.SystemName {
+	return "reaper"
+}
+
+// Components returns the components that the system is interested in.
+func (sys *Reaper) Components() []ecs.Component {
+	return []ecs.Component{
+		&component.Dead{},
+	}
+}
+
+// Update updates the system.
+func (sys *Reaper) Update(deltaTime time.Duration) {
+	sys.world.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
+		sys.world.RemoveEntity(sys.world.EntityForComponent(components["dead"]))
+	})
+}
@@ -1,9 +1,13 @@
 package system
 
 import (
+	"cmp"
+	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/matjam/sword/internal/camera"
 	"github.com/matjam/sword/internal/ecs"
 	"github.com/matjam/sword/internal/ecs/component"
 )
@@ -11,16 +15,43 @@ import (
 // Ensure that we're implementing the ecs.System interface.
 var _ = ecs.RenderSystem(&Renderer{})
 
-// Renderer renders all of the entities that have a Render component.
+// Renderer renders all of the entities that have a Render component. Entities
+// that also have an Animation with frames are drawn with its current frame
+// instead of their sprite.
 type Renderer struct {
 	world *ecs.World
 
-	GridSize int
+	// CellWidth and CellHeight are the size of a grid cell in pixels. They
+	// are only used if there's no Camera.
+	CellWidth  int
+	CellHeight int
+
+	// Camera is used to work out where each entity is on the screen. If it
+	// is nil, the world's *camera.Camera resource is used, and if there's
+	// none of those, entities are drawn at their grid cell from the top left
+	// of the screen.
+	Camera *camera.Camera
+
+	// undrawable is the set of entity names we've already warned about
+	// having nothing to draw, so that we only log once for each.
+	undrawable map[ecs.EntityName]bool
+
+	// draws is reused every frame to sort the entities by layer.
+	draws []renderDraw
+}
+
+// renderDraw is a single entity to be drawn. frame is the current frame of
+// its animation, or nil if it isn't animated.
+type renderDraw struct {
+	render   *component.Render
+	location *component.Location
+	frame    *ebiten.Image
 }
 
 // Init initializes the system.
 func (sys *Renderer) Init(world *ecs.World) {
 	sys.world = world
+	sys.undrawable = make(map[ecs.EntityName]bool)
 }
 
 // SystemName returns the name of the system.
@@ -46,10 +77,72 @@ func (sys *Renderer) WillDraw() bool {
 }
 
 func (sys *Renderer) Draw(screen *ebiten.Image) {
+	sys.draws = sys.draws[:0]
+
 	sys.world.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
 		render := ecs.GetComponentID[*component.Render](sys.world, components["render"])
 		location := ecs.GetComponentID[*component.Location](sys.world, components["location"])
 
-		render.Draw(screen, location.X, location.Y, sys.GridSize)
+		var frame *ebiten.Image
+		entityID := sys.world.EntityForComponent(components["render"])
+		if sys.world.HasComponent(entityID, &component.Animation{}) {
+			frame = ecs.GetComponent[*component.Animation](sys.world, entityID).Frame()
+		}
+
+		if frame == nil && !render.IsDrawable() {
+			sys.warnUndrawable(components["render"])
+		}
+
+		sys.draws = append(sys.draws, renderDraw{render, location, frame})
+	})
+
+	// the sort is stable, so entities on the same layer are still drawn in
+	// order of their EntityID.
+	slices.SortStableFunc(sys.draws, func(a, b renderDraw) int {
+		return cmp.Compare(a.render.Layer, b.render.Layer)
 	})
+
+	cam := sys.camera()
+	for _, draw := range sys.draws {
+		px, py := draw.location.X*sys.CellWidth, draw.location.Y*sys.CellHeight
+		if cam != nil {
+			px, py = cam.TileToScreen(draw.location.X, draw.location.Y)
+		}
+
+		if draw.frame != nil {
+			component.DrawSpriteAt(screen, draw.frame, px, py)
+			continue
+		}
+		draw.render.DrawAt(screen, px, py)
+	}
+}
+
+// camera returns the camera the entities are drawn through, or nil if there
+// isn't one.
+func (sys *Renderer) camera() *camera.Camera {
+	if sys.Camera != nil {
+		return sys.Camera
+	}
+
+	cam, _ := ecs.GetResource[*camera.Camera](sys.world)
+	return cam
+}
+
+// warnUndrawable logs a warning the first time we see an entity of a given
+// type that has a Render component with nothing to draw.
+func (sys *Renderer) warnUndrawable(renderID ecs.ComponentID) {
+	entityID := sys.world.EntityForComponent(renderID)
+	entity := sys.world.GetEntity(entityID)
+	if entity == nil {
+		// the component has been orphaned, so there's nothing to name
+		return
+	}
+
+	name := entity.EntityName()
+	if sys.undrawable[name] {
+		return
+	}
+
+	sys.undrawable[name] = true
+	slog.Warn("entity has a Render component with no glyph or sprite", "entity", name, "entity_id", entityID)
 }
@@ -0,0 +1,102 @@
+package system
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/scent"
+	"github.com/matjam/sword/internal/tilemap"
+)
+
+// Ensure that we're implementing the ecs.System interface.
+var _ = ecs.System(&Scent{})
+
+// DefaultScentStrength is how much scent the player leaves if Strength isn't
+// set.
+const DefaultScentStrength = 255
+
+// Scent keeps the world's scent map up to date. Every turn the old scent
+// fades and spreads, and the player leaves fresh scent where they're
+// standing. The map is stored as a world resource, so that mob AI can get it
+// with ecs.GetResource[*scent.Map] and follow the trail with Sniff.
+type Scent struct {
+	world  *ecs.World
+	Player ecs.EntityID
+
+	// Tilemap is the map the scent is spread over. Scent doesn't spread
+	// through the tiles that can't be walked through. If it is nil, the
+	// world's *tilemap.Grid resource is used.
+	Tilemap *tilemap.Grid
+
+	// Map is the scent map. If it is nil, one the size of Tilemap is created
+	// when the system is added to the world. If there's no Tilemap either,
+	// the system does nothing.
+	Map *scent.Map
+
+	// Strength is how much scent the player leaves on each tile. If it is
+	// zero, DefaultScentStrength is used.
+	Strength uint8
+
+	// lastTurn is the turn the scent was last updated for
+	lastTurn uint64
+}
+
+// Init initializes the system.
+func (sys *Scent) Init(world *ecs.World) {
+	sys.world = world
+
+	if sys.Tilemap == nil {
+		sys.Tilemap, _ = ecs.GetResource[*tilemap.Grid](world)
+	}
+
+	if sys.Map == nil {
+		if sys.Tilemap == nil {
+			slog.Warn("scent system has no map or tilemap, scent is disabled")
+			return
+		}
+		sys.Map = scent.New(sys.Tilemap.Width, sys.Tilemap.Height)
+	}
+	if sys.Map.Passable == nil && sys.Tilemap != nil {
+		sys.Map.Passable = func(x, y int) bool {
+			tile := sys.Tilemap.GetTile(x, y)
+			return tile != nil && tile.Type.IsPassable()
+		}
+	}
+
+	world.SetResource(sys.Map)
+}
+
+// SystemName returns the name of the system.
+func (sys *Scent) SystemName() ecs.SystemName {
+	return "scent"
+}
+
+// Components returns the components that the system is interested in. The
+// scent looks up the player directly, so it doesn't need any.
+func (sys *Scent) Components() []ecs.Component {
+	return []ecs.Component{}
+}
+
+// Update updates the system.
+func (sys *Scent) Update(deltaTime time.Duration) {
+	if sys.Map == nil || sys.world.Turn() == sys.lastTurn {
+		return
+	}
+	sys.lastTurn = sys.world.Turn()
+
+	sys.Map.Update()
+
+	if !sys.world.HasComponent(sys.Player, &component.Location{}) {
+		return
+	}
+
+	strength := sys.Strength
+	if strength == 0 {
+		strength = DefaultScentStrength
+	}
+
+	location := ecs.GetComponent[*component.Location](sys.world, sys.Player)
+	sys.Map.Deposit(location.X, location.Y, strength)
+}
@@ -0,0 +1,60 @@
+package system_test
+
+import (
+	"testing"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/entity"
+	"github.com/matjam/sword/internal/ecs/system"
+	"github.com/matjam/sword/internal/scent"
+	"github.com/matjam/sword/internal/tilemap"
+)
+
+func TestScent_TilemapResource(t *testing.T) {
+	tm := tilemap.NewGrid(5, 5)
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 5; x++ {
+			tm.SetTile(x, y, &tilemap.Tile{Type: tilemap.TileTypeFloor})
+		}
+	}
+
+	world := ecs.NewWorld()
+	world.SetResource(tm)
+	sys := &system.Scent{}
+	if err := world.AddSystems(sys); err != nil {
+		t.Fatal(err)
+	}
+
+	sys.Player = world.AddEntity(&entity.Player{})
+	world.MoveEntity(sys.Player, 2, 2)
+	world.EndTurn()
+	world.Update(1)
+
+	m, ok := ecs.GetResource[*scent.Map](world)
+	if !ok {
+		t.Fatal("expected the scent map to be a world resource")
+	}
+	if m.Width != tm.Width || m.Height != tm.Height {
+		t.Errorf("expected a %dx%d scent map, got %dx%d", tm.Width, tm.Height, m.Width, m.Height)
+	}
+	if m.Get(2, 2) != system.DefaultScentStrength {
+		t.Errorf("expected the player to leave scent, got %d", m.Get(2, 2))
+	}
+}
+
+func TestScent_NoMap(t *testing.T) {
+	world := ecs.NewWorld()
+	sys := &system.Scent{}
+	if err := world.AddSystems(sys); err != nil {
+		t.Fatal(err)
+	}
+
+	sys.Player = world.AddEntity(&entity.Player{})
+	world.MoveEntity(sys.Player, 2, 2)
+	world.EndTurn()
+	world.Update(1)
+
+	if _, ok := ecs.GetResource[*scent.Map](world); ok {
+		t.Error("expected no scent map without a tilemap")
+	}
+}
@@ -0,0 +1,89 @@
+package system
+
+import (
+	"time"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/tilemap"
+)
+
+// Ensure that we're implementing the ecs.System interface.
+var _ = ecs.System(&Sight{})
+
+// Sight works out which entities with a Vision can see the player. Each one
+// looks from its own Location out to its own radius, so a mob that is too far
+// away or behind a wall won't notice the player even if the player can see
+// it. The result is stored in Vision.CanSeePlayer for the mob AI to use.
+//
+// The check is only done once a turn, as nothing that changes what can be
+// seen happens between turns. Mobs that are added part way through a turn
+// won't see anything until the next one.
+type Sight struct {
+	world  *ecs.World
+	Player ecs.EntityID
+
+	// Tilemap is the map that blocks sight. If it is nil, the world's
+	// *tilemap.Grid resource is used.
+	Tilemap *tilemap.Grid
+
+	// lastTurn is the turn sight was last worked out for, and updated is set
+	// once it has been worked out at all.
+	lastTurn uint64
+	updated  bool
+}
+
+// Init initializes the system.
+func (sys *Sight) Init(world *ecs.World) {
+	sys.world = world
+}
+
+// SystemName returns the name of the system.
+func (sys *Sight) SystemName() ecs.SystemName {
+	return "sight"
+}
+
+// Components returns the components that the system is interested in.
+func (sys *Sight) Components() []ecs.Component {
+	return []ecs.Component{
+		&component.Vision{},
+		&component.Location{},
+	}
+}
+
+// Update updates the system.
+func (sys *Sight) Update(deltaTime time.Duration) {
+	if sys.updated && sys.world.Turn() == sys.lastTurn {
+		return
+	}
+	sys.lastTurn = sys.world.Turn()
+	sys.updated = true
+
+	tm := sys.tilemap()
+	var player *component.Location
+	if sys.world.HasComponent(sys.Player, &component.Location{}) {
+		player = ecs.GetComponent[*component.Location](sys.world, sys.Player)
+	}
+
+	sys.world.IterateComponents(sys, func(components map[ecs.ComponentName]ecs.ComponentID) {
+		vision := ecs.GetComponentID[*component.Vision](sys.world, components["vision"])
+		location := ecs.GetComponentID[*component.Location](sys.world, components["location"])
+
+		canSee := player != nil && tm != nil &&
+			tm.CanSee(location.X, location.Y, player.X, player.Y, vision.SightRadius())
+		if canSee != vision.CanSeePlayer {
+			vision.CanSeePlayer = canSee
+			sys.world.MarkChanged(sys.world.EntityForComponent(components["vision"]), "vision")
+		}
+	})
+}
+
+// tilemap returns the map that blocks sight.
+func (sys *Sight) tilemap() *tilemap.Grid {
+	if sys.Tilemap != nil {
+		return sys.Tilemap
+	}
+
+	tm, _ := ecs.GetResource[*tilemap.Grid](sys.world)
+	return tm
+}
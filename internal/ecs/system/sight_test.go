@@ -0,0 +1,63 @@
+package system_test
+
+import (
+	"testing"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/ecs/entity"
+	"github.com/matjam/sword/internal/ecs/system"
+	"github.com/matjam/sword/internal/tilemap"
+)
+
+func TestSight(t *testing.T) {
+	// a corridor with a wall at 6,0
+	tm := tilemap.NewGrid(10, 1)
+	for x := 0; x < 10; x++ {
+		if x != 6 {
+			tm.SetTile(x, 0, &tilemap.Tile{Type: tilemap.TileTypeFloor})
+		}
+	}
+
+	world := ecs.NewWorld()
+	sight := &system.Sight{Tilemap: tm}
+	if err := world.AddSystems(sight); err != nil {
+		t.Fatal(err)
+	}
+
+	sight.Player = world.AddEntity(&entity.Player{})
+	world.MoveEntity(sight.Player, 0, 0)
+
+	near := world.AddEntity(&entity.Mob{})
+	world.MoveEntity(near, 3, 0)
+	far := world.AddEntity(&entity.Mob{})
+	world.MoveEntity(far, 5, 0)
+	ecs.GetComponent[*component.Vision](world, far).Radius = 3
+	walled := world.AddEntity(&entity.Mob{})
+	world.MoveEntity(walled, 8, 0)
+	ecs.GetComponent[*component.Vision](world, walled).Radius = 20
+
+	world.Update(1)
+
+	for _, test := range []struct {
+		name     string
+		mob      ecs.EntityID
+		expected bool
+	}{
+		{"near", near, true},
+		{"far", far, false},
+		{"walled", walled, false},
+	} {
+		vision := ecs.GetComponent[*component.Vision](world, test.mob)
+		if vision.CanSeePlayer != test.expected {
+			t.Errorf("expected the %s mob's CanSeePlayer to be %v", test.name, test.expected)
+		}
+	}
+
+	// sight is only worked out once a turn
+	world.MoveEntity(sight.Player, 4, 0)
+	world.Update(1)
+	if ecs.GetComponent[*component.Vision](world, far).CanSeePlayer {
+		t.Error("expected sight not to change until the next turn")
+	}
+}
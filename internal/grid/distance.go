@@ -0,0 +1,39 @@
+package grid
+
+// These helpers measure the distance between two grid positions. Which one
+// to use depends on how the subsystem thinks about space:
+//
+//   - Manhattan counts orthogonal steps, which suits anything that only moves
+//     in four directions, like tunnels carved between rooms or picking the
+//     closest room centre during map generation.
+//   - Chebyshev counts steps when diagonals cost the same as orthogonal moves,
+//     which is how entities move, so use it for movement range and adjacency
+//     checks, and as the pathfinding heuristic.
+//   - EuclideanSquared is the straight line distance, squared so it stays in
+//     integers. Compare it against radius*radius for round areas such as the
+//     field of view and light radius.
+
+// Manhattan returns the number of orthogonal steps between two positions.
+func Manhattan(x1, y1, x2, y2 int) int {
+	return abs(x2-x1) + abs(y2-y1)
+}
+
+// Chebyshev returns the number of steps between two positions when diagonal
+// steps are allowed and cost the same as orthogonal ones.
+func Chebyshev(x1, y1, x2, y2 int) int {
+	return max(abs(x2-x1), abs(y2-y1))
+}
+
+// EuclideanSquared returns the square of the straight line distance between
+// two positions.
+func EuclideanSquared(x1, y1, x2, y2 int) int {
+	dx, dy := x2-x1, y2-y1
+	return dx*dx + dy*dy
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -3,6 +3,11 @@ package grid
 // package grid implements a generic grid of tiles. It can be used to
 // represent a tilemap, or a grid of any other type of data.
 
+import (
+	"encoding/binary"
+	"errors"
+)
+
 type Grid[T any] struct {
 	Width  int
 	Height int
@@ -41,6 +46,27 @@ func (m *Grid[T]) Set(x, y int, t T) {
 	m.grid[y*m.Width+x] = t
 }
 
+// Row returns the tiles of row y as a slice, from x 0 to Width-1. The slice
+// shares its tiles with the grid, so writing to it changes the grid, and it
+// can't be appended to past the end of the row.
+//
+// Unlike Get and Set, nothing is checked for each tile, so it's much faster
+// for walking along a row in a tight loop, such as scanning the whole map. It
+// is only meant for those hot loops; use Get and Set everywhere else. Row
+// panics if y is outside the grid.
+func (m *Grid[T]) Row(y int) []T {
+	end := (y + 1) * m.Width
+	return m.grid[y*m.Width : end : end]
+}
+
+// At returns the tile at index i, where tiles are numbered row by row from
+// the top left, so the tile at x, y is at y*Width+x. Like Row, it's for hot
+// loops that already know the index is inside the grid, and panics if it
+// isn't.
+func (m *Grid[T]) At(i int) T {
+	return m.grid[i]
+}
+
 // Clear sets all the tiles in the grid to the given value. This is useful
 // for clearing the grid before generating a new map.
 func (m *Grid[T]) Clear(t T) {
@@ -50,15 +76,278 @@ func (m *Grid[T]) Clear(t T) {
 }
 
 // SetRect sets all the tiles in the given rectangle to the given value.
-// If the rectangle is outside the bounds of the grid, it does nothing.
+// Any part of the rectangle that falls outside the bounds of the grid is
+// clipped, so only the tiles inside the grid are changed.
 func (m *Grid[T]) SetRect(x, y, w, h int, t T) {
-	if x < 0 || x >= m.Width || y < 0 || y >= m.Height {
-		return
+	minX := max(0, x)
+	minY := max(0, y)
+	maxX := min(m.Width, x+w)
+	maxY := min(m.Height, y+h)
+
+	for py := minY; py < maxY; py++ {
+		for px := minX; px < maxX; px++ {
+			m.grid[py*m.Width+px] = t
+		}
+	}
+}
+
+// Blit copies the contents of src into the grid, with the top left corner of
+// src at destX, destY. Any part of src that falls outside the bounds of the
+// grid is clipped.
+func (m *Grid[T]) Blit(src *Grid[T], destX, destY int) {
+	m.BlitFunc(src, destX, destY, nil)
+}
+
+// BlitFunc copies the contents of src into the grid like Blit, but only copies
+// the cells for which include returns true. This lets you use some values in
+// src as a mask, for example to only copy cells that aren't the zero value. If
+// include is nil, every cell is copied.
+func (m *Grid[T]) BlitFunc(src *Grid[T], destX, destY int, include func(T) bool) {
+	// work out the part of src that actually lands inside the grid, so we
+	// don't waste time on cells that would be clipped.
+	minX := max(0, -destX)
+	minY := max(0, -destY)
+	maxX := min(src.Width, m.Width-destX)
+	maxY := min(src.Height, m.Height-destY)
+
+	for sy := minY; sy < maxY; sy++ {
+		for sx := minX; sx < maxX; sx++ {
+			t := src.grid[sy*src.Width+sx]
+			if include != nil && !include(t) {
+				continue
+			}
+
+			m.grid[(sy+destY)*m.Width+sx+destX] = t
+		}
+	}
+}
+
+// ErrInvalidData is returned by Unmarshal when the data wasn't produced by
+// Marshal, or has been truncated.
+var ErrInvalidData = errors.New("grid: invalid data")
+
+// Marshal serializes the grid. Since the grid can hold any type, the caller
+// supplies encode to turn each tile into bytes. The tiles can be encoded to
+// any length, and don't all need to be the same length.
+func (m *Grid[T]) Marshal(encode func(T) []byte) []byte {
+	data := binary.AppendUvarint(nil, uint64(m.Width))
+	data = binary.AppendUvarint(data, uint64(m.Height))
+
+	for _, t := range m.grid {
+		b := encode(t)
+		data = binary.AppendUvarint(data, uint64(len(b)))
+		data = append(data, b...)
+	}
+
+	return data
+}
+
+// Unmarshal replaces the contents of the grid, including its size, with data
+// produced by Marshal. decode is called with the bytes that encode returned
+// for each tile. If the data is invalid, the grid is left unchanged and
+// ErrInvalidData is returned.
+func (m *Grid[T]) Unmarshal(data []byte, decode func([]byte) T) error {
+	next := func() (uint64, bool) {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, false
+		}
+		data = data[n:]
+		return v, true
+	}
+
+	width, ok1 := next()
+	height, ok2 := next()
+	if !ok1 || !ok2 || width > uint64(len(data)) || height > uint64(len(data)) || width*height > uint64(len(data)) {
+		// every tile takes at least one byte for its length, so this also
+		// stops us allocating a huge grid from a corrupt header.
+		return ErrInvalidData
+	}
+
+	tiles := make([]T, width*height)
+	for i := range tiles {
+		size, ok := next()
+		if !ok || size > uint64(len(data)) {
+			return ErrInvalidData
+		}
+
+		tiles[i] = decode(data[:size])
+		data = data[size:]
+	}
+
+	if len(data) != 0 {
+		return ErrInvalidData
+	}
+
+	m.Width = int(width)
+	m.Height = int(height)
+	m.grid = tiles
+
+	return nil
+}
+
+// Map returns a new grid the same size as src, where every tile is the result
+// of calling f on the tile at the same position in src. For example, it can
+// turn a terrain grid into a grid of which tiles are passable.
+func Map[T, U any](src *Grid[T], f func(T) U) *Grid[U] {
+	dst := NewGrid[U](src.Width, src.Height)
+	for i, t := range src.grid {
+		dst.grid[i] = f(t)
+	}
+	return dst
+}
+
+// SubGrid returns a copy of the w by h rectangle of the grid with its top left
+// corner at x, y. The rectangle can stick out past the edges of the grid, and
+// those tiles are left as the zero value of the type, so it can also be used
+// to pad a grid out.
+func (m *Grid[T]) SubGrid(x, y, w, h int) *Grid[T] {
+	dst := NewGrid[T](w, h)
+	dst.Blit(m, -x, -y)
+	return dst
+}
+
+// Rotate90 returns a copy of the grid rotated 90 degrees clockwise, so the
+// width and height are swapped and the top left tile ends up in the top right.
+// Rotating four times gives back the original grid.
+func (m *Grid[T]) Rotate90() *Grid[T] {
+	dst := NewGrid[T](m.Height, m.Width)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			dst.grid[x*dst.Width+(m.Height-1-y)] = m.grid[y*m.Width+x]
+		}
+	}
+	return dst
+}
+
+// FlipH returns a copy of the grid mirrored left to right.
+func (m *Grid[T]) FlipH() *Grid[T] {
+	dst := NewGrid[T](m.Width, m.Height)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			dst.grid[y*m.Width+(m.Width-1-x)] = m.grid[y*m.Width+x]
+		}
+	}
+	return dst
+}
+
+// FlipV returns a copy of the grid mirrored top to bottom.
+func (m *Grid[T]) FlipV() *Grid[T] {
+	dst := NewGrid[T](m.Width, m.Height)
+	for y := 0; y < m.Height; y++ {
+		copy(dst.grid[(m.Height-1-y)*m.Width:(m.Height-y)*m.Width], m.grid[y*m.Width:(y+1)*m.Width])
+	}
+	return dst
+}
+
+// Equal returns true if other is the same size as the grid, and eq returns
+// true for every pair of tiles at the same position.
+func (m *Grid[T]) Equal(other *Grid[T], eq func(a, b T) bool) bool {
+	if other == nil || m.Width != other.Width || m.Height != other.Height {
+		return false
+	}
+
+	for i := range m.grid {
+		if !eq(m.grid[i], other.grid[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CountMatching returns the number of tiles that pred returns true for.
+func (m *Grid[T]) CountMatching(pred func(T) bool) int {
+	count := 0
+	for _, t := range m.grid {
+		if pred(t) {
+			count++
+		}
+	}
+	return count
+}
+
+// FindAll returns the position of every tile that pred returns true for, row
+// by row from the top left.
+func (m *Grid[T]) FindAll(pred func(T) bool) [][2]int {
+	var found [][2]int
+	for i, t := range m.grid {
+		if pred(t) {
+			found = append(found, [2]int{i % m.Width, i / m.Width})
+		}
+	}
+	return found
+}
+
+// FNV-1a constants, used by Hash.
+const (
+	hashOffset = 14695981039346656037
+	hashPrime  = 1099511628211
+)
+
+// Hash returns a hash of the size of the grid and every tile in it, using h
+// to hash each tile. Grids that are Equal have the same hash, so this is a
+// cheap way to tell whether a grid has changed.
+func (m *Grid[T]) Hash(h func(T) uint64) uint64 {
+	var hash uint64 = hashOffset
+
+	hash = (hash ^ uint64(m.Width)) * hashPrime
+	hash = (hash ^ uint64(m.Height)) * hashPrime
+
+	for _, t := range m.grid {
+		hash = (hash ^ h(t)) * hashPrime
+	}
+
+	return hash
+}
+
+// FloodFill visits every tile that can be reached from the start position by
+// moving north, south, east or west through tiles that match returns true
+// for, including the start tile itself. visit is called once for each of
+// those tiles, in breadth first order, so tiles closer to the start are
+// always visited first. visit may be nil if only the count is needed.
+//
+// It returns the number of tiles visited, which is 0 if the start is outside
+// the grid or doesn't match.
+func (m *Grid[T]) FloodFill(startX, startY int, match func(T) bool, visit func(x, y int)) int {
+	if startX < 0 || startX >= m.Width || startY < 0 || startY >= m.Height {
+		return 0
+	}
+
+	start := startY*m.Width + startX
+	if !match(m.grid[start]) {
+		return 0
 	}
 
-	for py := y; py < y+h; py++ {
-		for px := x; px < x+w; px++ {
-			m.Set(px, py, t)
+	seen := make([]bool, len(m.grid))
+	seen[start] = true
+	queue := []int{start}
+
+	count := 0
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+
+		x, y := i%m.Width, i/m.Width
+		if visit != nil {
+			visit(x, y)
+		}
+		count++
+
+		for _, n := range [][2]int{{x, y - 1}, {x, y + 1}, {x - 1, y}, {x + 1, y}} {
+			if n[0] < 0 || n[0] >= m.Width || n[1] < 0 || n[1] >= m.Height {
+				continue
+			}
+
+			j := n[1]*m.Width + n[0]
+			if seen[j] || !match(m.grid[j]) {
+				continue
+			}
+
+			seen[j] = true
+			queue = append(queue, j)
 		}
 	}
+
+	return count
 }
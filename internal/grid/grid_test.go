@@ -0,0 +1,342 @@
+package grid_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/matjam/sword/internal/grid"
+	"github.com/matjam/sword/internal/terrain"
+)
+
+func TestBlit(t *testing.T) {
+	dst := grid.NewGrid[int](5, 5)
+	src := grid.NewGrid[int](2, 2)
+	src.Clear(1)
+
+	dst.Blit(src, 1, 2)
+
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 5; x++ {
+			expected := 0
+			if x >= 1 && x < 3 && y >= 2 && y < 4 {
+				expected = 1
+			}
+			if dst.Get(x, y) != expected {
+				t.Errorf("expected %d at %d,%d, got %d", expected, x, y, dst.Get(x, y))
+			}
+		}
+	}
+}
+
+func TestBlitClipped(t *testing.T) {
+	src := grid.NewGrid[int](3, 3)
+	src.Clear(1)
+
+	tests := []struct {
+		name         string
+		destX, destY int
+		expected     int
+	}{
+		{"top left", -1, -1, 4},
+		{"bottom right", 3, 3, 4},
+		{"right edge", 4, 1, 3},
+		{"completely outside", 10, 10, 0},
+		{"completely outside negative", -3, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := grid.NewGrid[int](5, 5)
+			dst.Blit(src, tt.destX, tt.destY)
+
+			count := 0
+			for y := 0; y < 5; y++ {
+				for x := 0; x < 5; x++ {
+					count += dst.Get(x, y)
+				}
+			}
+
+			if count != tt.expected {
+				t.Errorf("expected %d cells to be copied, got %d", tt.expected, count)
+			}
+		})
+	}
+}
+
+func TestBlitFunc(t *testing.T) {
+	dst := grid.NewGrid[int](3, 3)
+	dst.Clear(7)
+
+	src := grid.NewGrid[int](3, 3)
+	src.Set(1, 1, 2)
+
+	// only copy cells that aren't zero, so the rest of dst is left alone
+	dst.BlitFunc(src, 0, 0, func(v int) bool { return v != 0 })
+
+	if dst.Get(1, 1) != 2 {
+		t.Errorf("expected 2 at 1,1, got %d", dst.Get(1, 1))
+	}
+	if dst.Get(0, 0) != 7 {
+		t.Errorf("expected 7 at 0,0, got %d", dst.Get(0, 0))
+	}
+}
+
+func TestEqual(t *testing.T) {
+	eq := func(a, b int) bool { return a == b }
+
+	a := grid.NewGrid[int](4, 3)
+	b := grid.NewGrid[int](4, 3)
+	a.Set(1, 2, 5)
+	b.Set(1, 2, 5)
+
+	if !a.Equal(b, eq) {
+		t.Error("expected identical grids to be equal")
+	}
+
+	b.Set(3, 0, 1)
+	if a.Equal(b, eq) {
+		t.Error("expected grids with one different cell to not be equal")
+	}
+
+	if a.Equal(grid.NewGrid[int](3, 4), eq) {
+		t.Error("expected grids with different sizes to not be equal")
+	}
+}
+
+func TestHash(t *testing.T) {
+	h := func(v int) uint64 { return uint64(v) }
+
+	a := grid.NewGrid[int](4, 3)
+	b := grid.NewGrid[int](4, 3)
+	a.Set(1, 2, 5)
+	b.Set(1, 2, 5)
+
+	if a.Hash(h) != b.Hash(h) {
+		t.Error("expected identical grids to have the same hash")
+	}
+
+	b.Set(3, 0, 1)
+	if a.Hash(h) == b.Hash(h) {
+		t.Error("expected grids with one different cell to have different hashes")
+	}
+
+	// the same tiles laid out in a different shape should still differ
+	if grid.NewGrid[int](4, 3).Hash(h) == grid.NewGrid[int](3, 4).Hash(h) {
+		t.Error("expected grids with different sizes to have different hashes")
+	}
+}
+
+func TestFloodFill(t *testing.T) {
+	// two areas of open tiles, split by a wall down the middle
+	//
+	//  ..#..
+	//  ..#..
+	//  ..#..
+	g := grid.NewGrid[bool](5, 3)
+	for y := 0; y < 3; y++ {
+		g.Set(2, y, true)
+	}
+
+	open := func(wall bool) bool { return !wall }
+
+	visited := grid.NewGrid[int](5, 3)
+	order := 0
+	count := g.FloodFill(0, 0, open, func(x, y int) {
+		order++
+		visited.Set(x, y, order)
+	})
+
+	if count != 6 {
+		t.Errorf("expected 6 tiles to be visited, got %d", count)
+	}
+
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 5; x++ {
+			if (x < 2) != (visited.Get(x, y) != 0) {
+				t.Errorf("tile %d,%d visited should be %v", x, y, x < 2)
+			}
+		}
+	}
+
+	// breadth first, so the far corner is visited last
+	if visited.Get(0, 0) != 1 || visited.Get(1, 2) != 6 {
+		t.Errorf("expected the start to be visited first and the far corner last")
+	}
+
+	if n := g.FloodFill(2, 1, open, nil); n != 0 {
+		t.Errorf("expected a start that doesn't match to visit nothing, got %d", n)
+	}
+
+	if n := g.FloodFill(-1, 0, open, nil); n != 0 {
+		t.Errorf("expected a start outside the grid to visit nothing, got %d", n)
+	}
+}
+
+func TestMap(t *testing.T) {
+	tr := terrain.NewTerrain(4, 3)
+	tr.SetRect(1, 1, 2, 1, terrain.Room)
+	tr.Set(3, 1, terrain.Rubble)
+
+	passable := grid.Map(tr.Grid, terrain.Type.IsPassable)
+
+	if passable.Width != 4 || passable.Height != 3 {
+		t.Fatalf("expected a 4x3 grid, got %dx%d", passable.Width, passable.Height)
+	}
+
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			expected := y == 1 && (x == 1 || x == 2)
+			if passable.Get(x, y) != expected {
+				t.Errorf("expected tile %d,%d passable to be %v", x, y, expected)
+			}
+		}
+	}
+}
+
+// pattern builds a grid from rows of ints, so expected results can be
+// written out the way they look.
+func pattern(rows [][]int) *grid.Grid[int] {
+	g := grid.NewGrid[int](len(rows[0]), len(rows))
+	for y, row := range rows {
+		for x, v := range row {
+			g.Set(x, y, v)
+		}
+	}
+	return g
+}
+
+func TestTransforms(t *testing.T) {
+	src := pattern([][]int{
+		{1, 2, 3},
+		{4, 5, 6},
+	})
+
+	tests := []struct {
+		name     string
+		got      *grid.Grid[int]
+		expected *grid.Grid[int]
+	}{
+		{"rotate 90", src.Rotate90(), pattern([][]int{
+			{4, 1},
+			{5, 2},
+			{6, 3},
+		})},
+		{"rotate 180", src.Rotate90().Rotate90(), pattern([][]int{
+			{6, 5, 4},
+			{3, 2, 1},
+		})},
+		{"rotate 360", src.Rotate90().Rotate90().Rotate90().Rotate90(), src},
+		{"flip h", src.FlipH(), pattern([][]int{
+			{3, 2, 1},
+			{6, 5, 4},
+		})},
+		{"flip v", src.FlipV(), pattern([][]int{
+			{4, 5, 6},
+			{1, 2, 3},
+		})},
+		{"180 is both flips", src.Rotate90().Rotate90(), src.FlipH().FlipV()},
+	}
+
+	eq := func(a, b int) bool { return a == b }
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.got.Equal(tt.expected, eq) {
+				t.Errorf("expected %v, got %v", tt.expected, tt.got)
+			}
+		})
+	}
+
+	if src.Get(0, 0) != 1 || src.Width != 3 {
+		t.Errorf("transforms should not modify the source grid")
+	}
+}
+
+func TestSubGrid(t *testing.T) {
+	src := pattern([][]int{
+		{1, 2, 3},
+		{4, 5, 6},
+	})
+
+	eq := func(a, b int) bool { return a == b }
+
+	if sub := src.SubGrid(1, 0, 2, 2); !sub.Equal(pattern([][]int{{2, 3}, {5, 6}}), eq) {
+		t.Errorf("expected the right two columns, got %v", sub)
+	}
+
+	// past the edges is padded with zeroes
+	if sub := src.SubGrid(-1, 1, 3, 2); !sub.Equal(pattern([][]int{{0, 4, 5}, {0, 0, 0}}), eq) {
+		t.Errorf("expected padding, got %v", sub)
+	}
+}
+
+func TestRowAndAt(t *testing.T) {
+	g := pattern([][]int{
+		{1, 2, 3},
+		{4, 5, 6},
+	})
+
+	if row := g.Row(1); !slices.Equal(row, []int{4, 5, 6}) {
+		t.Errorf("expected the second row, got %v", row)
+	}
+	if g.At(4) != 5 {
+		t.Errorf("expected 5 at index 4, got %d", g.At(4))
+	}
+
+	// the row is the grid's own tiles, but appending can't spill into the
+	// next row
+	row := g.Row(0)
+	row[1] = 9
+	_ = append(row, 7)
+	if g.Get(1, 0) != 9 || g.Get(0, 1) != 4 {
+		t.Errorf("expected writes to the row to change only that row, got %v", g)
+	}
+}
+
+func TestCountMatchingAndFindAll(t *testing.T) {
+	g := pattern([][]int{
+		{1, 0, 2},
+		{0, 1, 0},
+	})
+
+	isOne := func(v int) bool { return v == 1 }
+
+	if n := g.CountMatching(isOne); n != 2 {
+		t.Errorf("expected 2 matching tiles, got %d", n)
+	}
+
+	found := g.FindAll(isOne)
+	if len(found) != 2 || found[0] != [2]int{0, 0} || found[1] != [2]int{1, 1} {
+		t.Errorf("expected 0,0 and 1,1, got %v", found)
+	}
+
+	none := func(v int) bool { return v > 2 }
+	if g.CountMatching(none) != 0 || g.FindAll(none) != nil {
+		t.Error("expected nothing to match")
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		x1, y1, x2, y2               int
+		manhattan, chebyshev, euclid int
+	}{
+		{0, 0, 0, 0, 0, 0, 0},
+		{0, 0, 3, 4, 7, 4, 25},
+		{3, 4, 0, 0, 7, 4, 25},
+		{-2, 5, 1, 1, 7, 4, 25},
+		{2, 2, 3, 3, 2, 1, 2},
+		{0, 0, 5, 0, 5, 5, 25},
+	}
+
+	for _, tt := range tests {
+		if got := grid.Manhattan(tt.x1, tt.y1, tt.x2, tt.y2); got != tt.manhattan {
+			t.Errorf("Manhattan(%d, %d, %d, %d) = %d, want %d", tt.x1, tt.y1, tt.x2, tt.y2, got, tt.manhattan)
+		}
+		if got := grid.Chebyshev(tt.x1, tt.y1, tt.x2, tt.y2); got != tt.chebyshev {
+			t.Errorf("Chebyshev(%d, %d, %d, %d) = %d, want %d", tt.x1, tt.y1, tt.x2, tt.y2, got, tt.chebyshev)
+		}
+		if got := grid.EuclideanSquared(tt.x1, tt.y1, tt.x2, tt.y2); got != tt.euclid {
+			t.Errorf("EuclideanSquared(%d, %d, %d, %d) = %d, want %d", tt.x1, tt.y1, tt.x2, tt.y2, got, tt.euclid)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package mapgen
+
+import (
+	"runtime"
+	"sync"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Picking the best map
+
+// GenerateBest generates a map of the given size for each of the seeds, with
+// AutoRoomAttempts, and returns the one that score rates highest, along with
+// its seed. This is a way to pick a good seed automatically, by scoring the
+// maps on their Stats or RoomGraph. If several maps score the same, the one
+// whose seed comes first in seeds wins, so the result only depends on the
+// seeds and not on the order the maps happen to finish in. It returns nil and
+// 0 if there are no seeds.
+//
+// Every generator has its own random number generator and doesn't share
+// anything with the others, so the maps are generated in parallel, on as
+// many goroutines as GOMAXPROCS allows. That means score can be called from
+// several goroutines at once, and must be safe for that. Only the best map
+// is kept, so the rest can be garbage collected as soon as they are scored.
+func GenerateBest(width, height int, seeds []int64, score func(*MapGenerator) float64) (*MapGenerator, int64) {
+	type result struct {
+		index int
+		score float64
+		mg    *MapGenerator
+	}
+
+	// better returns true if a should be picked over b
+	better := func(a, b result) bool {
+		if b.mg == nil {
+			return true
+		}
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		return a.index < b.index
+	}
+
+	workers := min(runtime.GOMAXPROCS(0), len(seeds))
+	bests := make([]result, workers)
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			for i := range next {
+				mg := NewMapGenerator(width, height, seeds[i], AutoRoomAttempts)
+				mg.GenerateAll()
+
+				r := result{index: i, score: score(mg), mg: mg}
+				if better(r, bests[w]) {
+					bests[w] = r
+				}
+			}
+		}(w)
+	}
+
+	for i := range seeds {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var best result
+	for _, r := range bests {
+		if r.mg != nil && better(r, best) {
+			best = r
+		}
+	}
+
+	if best.mg == nil {
+		return nil, 0
+	}
+	return best.mg, seeds[best.index]
+}
@@ -0,0 +1,134 @@
+package mapgen
+
+import (
+	"log/slog"
+	"math/rand"
+
+	"github.com/matjam/sword/internal/rng"
+	"github.com/matjam/sword/internal/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Caves
+
+// CaveGenerator carves an organic cave level using a drunkard's walk. A walker
+// starts in the middle of the map and stumbles around at random, turning every
+// stone tile it steps on into a Room tile, until the requested percentage of
+// the map is floor.
+//
+// Because every carved tile was reached by the same walker, the resulting cave
+// is always a single connected region, so there's no need to generate
+// connectors or remove dead ends afterwards.
+type CaveGenerator struct {
+	Width  int
+	Height int
+
+	// FloorPercent is the fraction of the interior of the map (0.0 - 1.0) that
+	// should be carved out before the walker stops.
+	FloorPercent float64
+
+	// CenterBias is the chance (0.0 - 1.0) that each step is taken towards the
+	// center of the map rather than in a random direction. A small bias stops
+	// the walker from hugging the edges of the map.
+	CenterBias float64
+
+	seed        int64
+	terrainGrid *terrain.Terrain
+	rng         *rand.Rand
+}
+
+func NewCaveGenerator(width int, height int, seed int64) *CaveGenerator {
+	return &CaveGenerator{
+		Width:        width,
+		Height:       height,
+		FloorPercent: 0.4,
+		CenterBias:   0.1,
+		seed:         seed,
+	}
+}
+
+// Generate carves the cave and returns the resulting terrain. Every call
+// starts again from solid stone, with the rng reseeded, so calling it twice
+// gives the same map as long as the fields haven't been changed in between.
+func (cg *CaveGenerator) Generate() *terrain.Terrain {
+	cg.terrainGrid = terrain.NewTerrain(cg.Width, cg.Height)
+	cg.rng = rng.New(cg.seed)
+
+	// we keep a one tile border of stone around the map, the same as the
+	// rooms and mazes generator does.
+	minX, minY := 1, 1
+	maxX, maxY := cg.Width-2, cg.Height-2
+	if maxX < minX || maxY < minY {
+		slog.Error("map is too small to carve a cave", "width", cg.Width, "height", cg.Height)
+		return cg.terrainGrid
+	}
+
+	floorPercent := cg.FloorPercent
+	if floorPercent > 1 {
+		floorPercent = 1
+	}
+
+	interior := (maxX - minX + 1) * (maxY - minY + 1)
+	target := int(float64(interior) * floorPercent)
+
+	centerX := cg.Width / 2
+	centerY := cg.Height / 2
+	x, y := centerX, centerY
+
+	// the walker will always finish eventually on a bounded map, but we put a
+	// cap on the number of steps so a silly FloorPercent can't hang the game.
+	maxSteps := interior * 100
+
+	carved := 0
+	for step := 0; carved < target && step < maxSteps; step++ {
+		if cg.terrainGrid.Get(x, y) == terrain.Stone {
+			cg.terrainGrid.Set(x, y, terrain.Room)
+			carved++
+		}
+
+		dx, dy := cg.nextStep(x, y, centerX, centerY)
+
+		// we don't let the walker step into the border, it just stays where
+		// it is and tries again next step.
+		if x+dx >= minX && x+dx <= maxX && y+dy >= minY && y+dy <= maxY {
+			x += dx
+			y += dy
+		}
+	}
+
+	slog.Debug("cave generation finished", "carved", carved, "target", target)
+
+	return cg.terrainGrid
+}
+
+// nextStep picks the direction of the next step of the walker.
+func (cg *CaveGenerator) nextStep(x, y, centerX, centerY int) (dx, dy int) {
+	if cg.rng.Float64() < cg.CenterBias {
+		// step along whichever axis is furthest from the center.
+		distX := centerX - x
+		distY := centerY - y
+		if abs(distX) >= abs(distY) && distX != 0 {
+			return sign(distX), 0
+		}
+		if distY != 0 {
+			return 0, sign(distY)
+		}
+	}
+
+	switch Direction(cg.rng.Intn(4)) {
+	case North:
+		return 0, -1
+	case South:
+		return 0, 1
+	case East:
+		return 1, 0
+	default:
+		return -1, 0
+	}
+}
+
+// Terrain returns the terrain made by the last call to Generate, or nil if it
+// hasn't been called.
+func (cg *CaveGenerator) Terrain() *terrain.Terrain {
+	return cg.terrainGrid
+}
@@ -0,0 +1,70 @@
+package mapgen_test
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/matjam/sword/internal/mapgen"
+	"github.com/matjam/sword/internal/terrain"
+)
+
+func TestCaveGenerator(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	const width, height = 61, 41
+
+	for _, seed := range benchmarkSeeds {
+		cg := mapgen.NewCaveGenerator(width, height, seed)
+		tr := cg.Generate()
+
+		// the walk stops as soon as enough of the interior is floor
+		target := int(float64((width-2)*(height-2)) * cg.FloorPercent)
+		if floor := tr.Count(terrain.Room); floor != target {
+			t.Errorf("seed %d: expected %d floor tiles, got %d", seed, target, floor)
+		}
+
+		for x := 0; x < width; x++ {
+			if tr.Get(x, 0) != terrain.Stone || tr.Get(x, height-1) != terrain.Stone {
+				t.Errorf("seed %d: expected the border to be stone at column %d", seed, x)
+			}
+		}
+		for y := 0; y < height; y++ {
+			if tr.Get(0, y) != terrain.Stone || tr.Get(width-1, y) != terrain.Stone {
+				t.Errorf("seed %d: expected the border to be stone at row %d", seed, y)
+			}
+		}
+
+		// everything was carved by the one walker, so it's all connected
+		if reached := reachableTiles(tr, width/2, height/2); reached != target {
+			t.Errorf("seed %d: expected to reach all %d floor tiles from the middle, got %d", seed, target, reached)
+		}
+
+		// the same seed makes the same cave, even when generated again
+		if !mapgen.NewCaveGenerator(width, height, seed).Generate().Equal(tr) {
+			t.Errorf("seed %d: expected the same seed to make the same cave", seed)
+		}
+		first := tr.String()
+		if cg.Generate().String() != first {
+			t.Errorf("seed %d: expected generating again to make the same cave", seed)
+		}
+	}
+}
+
+func TestCaveGenerator_Resize(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// the size can be changed after the generator is made
+	cg := mapgen.NewCaveGenerator(21, 21, 1)
+	cg.Width, cg.Height = 41, 31
+
+	tr := cg.Generate()
+	if tr.Width != 41 || tr.Height != 31 {
+		t.Errorf("expected a 41x31 cave, got %dx%d", tr.Width, tr.Height)
+	}
+	if cg.Terrain() != tr {
+		t.Error("expected Terrain to return the cave that was generated")
+	}
+}
@@ -16,6 +16,7 @@ func (mg *MapGenerator) connectRegions() {
 
 	// if there's only one region, we're done.
 	if len(mg.regions) == 1 {
+		mg.openLoops()
 		mg.Phase = PhaseRemoveDeadEnds
 		return
 	}
@@ -28,12 +29,13 @@ func (mg *MapGenerator) connectRegions() {
 		mg.findRootConnectors()
 
 		if len(mg.rootConnectors) == 0 {
+			mg.openLoops()
 			mg.Phase = PhaseRemoveDeadEnds
 			return
 		}
 
-		// shuffle the list of root connectors
-		shuffleArray(mg.rng, mg.rootConnectors)
+		// doors look best in the walls of rooms, so try those connectors first
+		mg.preferRoomConnectors()
 	}
 
 	// The algorithm here is simple, we work through the list of root connectors,
@@ -70,20 +72,25 @@ func (mg *MapGenerator) connectRegions() {
 
 			// find the region that is not the root region
 			var otherRegion *Region
-			if c.region1.id == mg.rootRegion.id {
-				otherRegion = c.region2
+			if c.region1.find() == mg.rootRegion {
+				otherRegion = c.region2.find()
 			} else {
-				otherRegion = c.region1
+				otherRegion = c.region1.find()
 			}
 
-			// replace all instances of the region with the root region
-			mg.replaceRegion(otherRegion, mg.rootRegion)
+			// merge the region into the root region
+			mg.mergeRegions(otherRegion, mg.rootRegion)
 
-			// remove the region from the list of unconnected regions
-			delete(mg.regions, otherRegion.id)
+			// on symmetric maps, the door on the other side gets opened too
+			mg.openMirroredConnector(c)
 
 			// success!
 			success = true
+		} else {
+			// both sides are already connected to the root region, so
+			// opening this connector would make a loop. Keep it in case we
+			// need one. See openLoops().
+			mg.loopConnectors = append(mg.loopConnectors, c)
 		}
 	}
 }
@@ -104,11 +111,14 @@ func (mg *MapGenerator) connectorIsBesideDoor(c *Connector) bool {
 
 func (mg *MapGenerator) connectsRootToUnconnectedRegion(connector *Connector) bool {
 	// check if the connector connects the root region to an unconnected region
-	if connector.region1.id == mg.rootRegion.id && connector.region2.id != mg.rootRegion.id {
+	region1 := connector.region1.find()
+	region2 := connector.region2.find()
+
+	if region1 == mg.rootRegion && region2 != mg.rootRegion {
 		return true
 	}
 
-	if connector.region2.id == mg.rootRegion.id && connector.region1.id != mg.rootRegion.id {
+	if region2 == mg.rootRegion && region1 != mg.rootRegion {
 		return true
 	}
 
@@ -131,6 +141,7 @@ func (mg *MapGenerator) selectRootRegion() {
 	rootRoom := mg.unconnectedRooms[len(mg.unconnectedRooms)-1]
 	mg.unconnectedRooms = mg.unconnectedRooms[:len(mg.unconnectedRooms)-1]
 	mg.rootRegion = rootRoom.Region
+	mg.rootRoom = rootRoom
 
 	// set the color of the root region to black
 	mg.rootRegion.clr = color.RGBA{0x00, 0x00, 0x00, 0xff}
@@ -139,48 +150,73 @@ func (mg *MapGenerator) selectRootRegion() {
 }
 
 func (mg *MapGenerator) findRootConnectors() {
-	// shuffle the list of connectors
-	shuffleArray(mg.rng, mg.connectors)
-
+	// The connectors were shuffled once when they were generated, and we keep
+	// them in that order here rather than shuffling again, so the root
+	// connectors come out in a random order too.
 	otherConnectors := make([]*Connector, 0)
 	mg.rootConnectors = make([]*Connector, 0)
 
 	// find all the connectors that connect the root region to another region
 	for _, c := range mg.connectors {
-		if (c.region1.id == mg.rootRegion.id && c.region2.id != mg.rootRegion.id) ||
-			(c.region1.id != mg.rootRegion.id && c.region2.id == mg.rootRegion.id) {
+		region1 := c.region1.find()
+		region2 := c.region2.find()
+		if (region1 == mg.rootRegion && region2 != mg.rootRegion) ||
+			(region1 != mg.rootRegion && region2 == mg.rootRegion) {
 			mg.rootConnectors = append(mg.rootConnectors, c)
 		} else {
 			otherConnectors = append(otherConnectors, c)
 		}
 	}
 
-	shuffleArray(mg.rng, mg.rootConnectors)
-
 	mg.connectors = otherConnectors
 }
 
-func (mg *MapGenerator) replaceRegion(oldRegion *Region, newRegion *Region) {
-	// The replaceRegion() method is where we replace all instances of one region
-	// with another region. We do this by iterating over the Grid, and replacing
-	// all instances of the old region with the new region.
+func (mg *MapGenerator) preferRoomConnectors() {
+	// The preferRoomConnectors() method moves every root connector that lies on
+	// the perimeter of a room to the front of the list, keeping the shuffled
+	// order within each group. Connectors between two corridors are still in
+	// the list, so if a region can only be reached through one of those we'll
+	// get to it eventually, and the map is still fully connected.
 
-	for y := 0; y < mg.Height; y++ {
-		for x := 0; x < mg.Width; x++ {
-			r := mg.regionGrid.Get(x, y)
-			if r != nil && r.id == oldRegion.id {
-				mg.regionGrid.Set(x, y, newRegion)
-			}
+	roomConnectors := make([]*Connector, 0, len(mg.rootConnectors))
+	otherConnectors := make([]*Connector, 0, len(mg.rootConnectors))
 
-			c := mg.connectorGrid.Get(x, y)
-			if c != nil {
-				if c.region1.id == oldRegion.id {
-					c.region1 = newRegion
-				}
-				if c.region2.id == oldRegion.id {
-					c.region2 = newRegion
-				}
-			}
+	for _, c := range mg.rootConnectors {
+		if mg.isRoomConnector(c) {
+			roomConnectors = append(roomConnectors, c)
+		} else {
+			otherConnectors = append(otherConnectors, c)
 		}
 	}
+
+	mg.rootConnectors = append(roomConnectors, otherConnectors...)
+}
+
+func (mg *MapGenerator) isRoomConnector(c *Connector) bool {
+	// a connector is on the perimeter of a room if any of the tiles beside it
+	// are inside a room.
+	return mg.RoomAt(c.x+1, c.y) != nil ||
+		mg.RoomAt(c.x-1, c.y) != nil ||
+		mg.RoomAt(c.x, c.y-1) != nil ||
+		mg.RoomAt(c.x, c.y+1) != nil
+}
+
+func (mg *MapGenerator) mergeRegions(oldRegion *Region, newRegion *Region) {
+	// The mergeRegions() method is where we merge one region into another. We
+	// used to do this by scanning the whole grid and rewriting every tile and
+	// connector that pointed at the old region, which made connecting the map
+	// O(regions * area). Instead, the regions form a disjoint set (union-find),
+	// and we just point the old region at the new one. Anything that needs to
+	// know which region a tile is really in calls find() on it.
+
+	oldRoot := oldRegion.find()
+	newRoot := newRegion.find()
+	if oldRoot == newRoot {
+		return
+	}
+
+	oldRoot.parent = newRoot
+
+	// remove the region from the list of unconnected regions
+	delete(mg.regions, oldRoot.id)
 }
@@ -9,11 +9,22 @@ func (mg *MapGenerator) generateConnectors() {
 	// The generateConnectors() method is where we generate the connectors. We do
 	// this by finding all the tiles that are adjacent to a corridor, and then
 	// checking if they are adjacent to a room. If they are, we add them to the
-	// list of connectors. We then shuffle the list of connectors, and then we
-	// iterate over the list of connectors and try to connect them to a room.
+	// list of connectors, and shuffle it so that connectRegions() tries them in
+	// a random order.
+	//
+	// Scanning a big map takes a while, so we only scan one row each time
+	// we're called, and let Update() decide whether to carry on.
 
-	for y := 1; y < mg.Height-1; y += 1 {
-		for x := 1; x < mg.Width-1; x += 1 {
+	minX, minY, maxX, maxY := mg.bounds()
+	if mg.connectorRow < minY {
+		mg.connectorRow = minY
+	}
+
+	if mg.connectorRow <= maxY {
+		y := mg.connectorRow
+		mg.connectorRow++
+
+		for x := minX; x <= maxX; x += 1 {
 			ok, region1, region2 := mg.isConnectorTile(x, y)
 			if ok {
 				connector := &Connector{
@@ -28,8 +39,17 @@ func (mg *MapGenerator) generateConnectors() {
 				mg.connectors = append(mg.connectors, connector)
 			}
 		}
+
+		return
 	}
 
+	// This is the only time the connectors are shuffled. Every list of root
+	// connectors is picked out of this one in order, so each of them comes out
+	// shuffled too, and the connection phase doesn't need any more random
+	// numbers. Keeping the number of rng draws fixed means a seed keeps making
+	// the same map even if the code around it gets moved around.
+	shuffleArray(mg.rng, mg.connectors)
+
 	mg.Phase = PhaseConnectingRegions
 }
 
@@ -46,7 +66,7 @@ func (mg *MapGenerator) isConnectorTile(x, y int) (isConnector bool, region1, re
 		(e == terrain.Corridor && w == terrain.Room) {
 		eRegion := mg.regionGrid.Get(x+1, y)
 		wRegion := mg.regionGrid.Get(x-1, y)
-		if eRegion.id != wRegion.id {
+		if eRegion.find() != wRegion.find() {
 			return true, eRegion, wRegion
 		}
 	}
@@ -60,7 +80,7 @@ func (mg *MapGenerator) isConnectorTile(x, y int) (isConnector bool, region1, re
 		(n == terrain.Corridor && s == terrain.Room) {
 		nRegion := mg.regionGrid.Get(x, y-1)
 		sRegion := mg.regionGrid.Get(x, y+1)
-		if nRegion.id != sRegion.id {
+		if nRegion.find() != sRegion.find() {
 			return true, nRegion, sRegion
 		}
 	}
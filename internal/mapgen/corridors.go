@@ -24,23 +24,39 @@ func (mg *MapGenerator) generateMazes() {
 	// tile because we only want to start a corridor at the center of each wall,
 	// not at the corners.
 
+	if mg.CorridorStyle == CorridorDirect {
+		mg.generateTunnels()
+		return
+	}
+
 	if mg.walking {
 		mg.walk()
 	} else {
 		done := mg.carveMaze()
 		if done {
-			mg.Phase = PhaseConnectors
+			mg.convertPockets()
+			mg.Phase = PhaseMirror
 		}
 	}
 }
 
 func (mg *MapGenerator) carveMaze() (done bool) {
+	minX, minY, maxX, maxY := mg.bounds()
+
+	// the first time through, every row inside the border is incomplete
+	if !mg.mazePlanned {
+		mg.mazePlanned = true
+		for y := minY; y <= maxY; y += 2 {
+			mg.incompleteRows = append(mg.incompleteRows, y)
+		}
+	}
+
 	// while there are still rows that have not been fully populated with rooms,
 	// doors and corridors, keep carving.
 	if len(mg.incompleteRows) > 0 {
 		// for this row, we need to keep track of the columns that have not yet
 		// been fully populated with rooms, doors and corridors.
-		for x := 1; x < mg.Width-1; x += 2 {
+		for x := minX; x <= maxX; x += 2 {
 			mg.incompleteCols = append(mg.incompleteCols, x)
 		}
 
@@ -57,7 +73,7 @@ func (mg *MapGenerator) carveMaze() (done bool) {
 		for len(mg.incompleteCols) > 0 {
 			scanX := mg.incompleteCols[0]
 
-			if mg.terrainGrid.Get(scanX, scanY) == terrain.Stone {
+			if mg.isCarvable(scanX, scanY) {
 				mg.x = scanX
 				mg.y = scanY
 
@@ -202,30 +218,36 @@ func (mg *MapGenerator) canCarve(direction Direction) bool {
 		if mg.y-2 < 0 {
 			return false
 		}
-		return mg.terrainGrid.Get(mg.x, mg.y-2) == terrain.Stone
+		return mg.isCarvable(mg.x, mg.y-2)
 	case South:
 		// check if the tile two tiles south is still in the terrainGrid
 		if mg.y+2 >= mg.Height {
 			return false
 		}
-		return mg.terrainGrid.Get(mg.x, mg.y+2) == terrain.Stone
+		return mg.isCarvable(mg.x, mg.y+2)
 	case East:
 		// check if the tile two tiles east is still in the terrainGrid
 		if mg.x+2 >= mg.Width {
 			return false
 		}
-		return mg.terrainGrid.Get(mg.x+2, mg.y) == terrain.Stone
+		return mg.isCarvable(mg.x+2, mg.y)
 	case West:
 		// check if the tile two tiles west is still in the terrainGrid
 		if mg.x-2 < 0 {
 			return false
 		}
-		return mg.terrainGrid.Get(mg.x-2, mg.y) == terrain.Stone
+		return mg.isCarvable(mg.x-2, mg.y)
 	}
 
 	return false
 }
 
+func (mg *MapGenerator) isCarvable(x, y int) bool {
+	// a tile can be carved if it is stone, isn't part of a prefab or the
+	// border, and is on the source side of a symmetric map.
+	return mg.terrainGrid.Get(x, y) == terrain.Stone && !mg.protectedGrid.Get(x, y) && mg.inBounds(x, y) && mg.inSource(x, y)
+}
+
 func (mg *MapGenerator) doCarve(direction Direction) {
 	// The doCarve() method is where we carve in a given direction. We do this by
 	// setting the tile two tiles away in the given direction to the correct type,
@@ -1,6 +1,9 @@
 package mapgen
 
-import "github.com/matjam/sword/internal/terrain"
+import (
+	"github.com/matjam/sword/internal/grid"
+	"github.com/matjam/sword/internal/terrain"
+)
 
 ////////////////////////////////////////////////////////////////////////////
 // Remove Dead Ends
@@ -8,45 +11,32 @@ import "github.com/matjam/sword/internal/terrain"
 func (mg *MapGenerator) removeDeadEnds() {
 	// The removeDeadEnds() method is where we remove dead ends. We do this by
 	// iterating over the map, and for each tile we check if it is a dead end. If
-	// it is, we remove it.
+	// it is, we remove it. If DeadEndKeepChance is set, each dead end may
+	// be kept as an alcove instead, and it is never looked at again, so the
+	// passes still stop once there's nothing left to remove.
 
 	mg.deadEndsPreviouslyRemoved = mg.deadEndsRemoved
 
 	mg.findDeadEnds()
 	for _, deadEnd := range mg.deadEnds {
 		x, y := deadEnd[0], deadEnd[1]
+
+		// we only roll when it's enabled, so that maps made without it are
+		// the same as they always were.
+		if mg.DeadEndKeepChance > 0 && mg.rng.Float64() < mg.DeadEndKeepChance {
+			mg.keepAlcove(x, y)
+			continue
+		}
+
 		mg.terrainGrid.Set(x, y, terrain.Stone)
 		mg.regionGrid.Set(x, y, nil)
 		mg.deadEndsRemoved++
 	}
 	if mg.deadEndsPreviouslyRemoved == mg.deadEndsRemoved {
-		mg.Phase = PhaseDone
+		mg.Phase = PhaseFeatures
 	}
 }
 
-func (mg *MapGenerator) isDeadEnd(x, y int) bool {
-	// The isDeadEnd() method is where we check if a tile is a dead end. We do
-	// this by checking if the tile is a corridor, and if it has only one
-	// neighbouring corridor tile.
-
-	t := mg.terrainGrid.Get(x, y)
-	if t != terrain.Corridor && t != terrain.Door {
-		return false
-	}
-
-	neighbours := mg.getNeighbours(x, y)
-
-	// count the number of corridor neighbours
-	corridorNeighbours := 0
-	for _, n := range neighbours {
-		if n != terrain.Stone {
-			corridorNeighbours++
-		}
-	}
-
-	return corridorNeighbours == 1
-}
-
 func (mg *MapGenerator) getNeighbours(x, y int) []terrain.Type {
 	// The getNeighbours() method is where we get the neighbours of a tile. We do
 	// this by getting the tile to the north, south, east and west of the given
@@ -60,18 +50,80 @@ func (mg *MapGenerator) getNeighbours(x, y int) []terrain.Type {
 	return []terrain.Type{n, s, e, w}
 }
 
+// Alcoves returns the tips of the dead ends that were kept because of
+// DeadEndKeepChance, in the order they were picked, such as for placing
+// treasure or secrets at. Each one is a corridor or door tile with only one
+// open neighbour.
+func (mg *MapGenerator) Alcoves() [][2]int {
+	return mg.alcoves
+}
+
+// keepAlcove keeps the dead end at the given tile as an alcove.
+func (mg *MapGenerator) keepAlcove(x, y int) {
+	if mg.alcoveGrid == nil {
+		mg.alcoveGrid = grid.NewGrid[bool](mg.Width, mg.Height)
+	}
+
+	mg.alcoveGrid.Set(x, y, true)
+	mg.alcoves = append(mg.alcoves, [2]int{x, y})
+}
+
+// isAlcove returns true if the tile is the tip of a dead end that was kept.
+func (mg *MapGenerator) isAlcove(x, y int) bool {
+	return mg.alcoveGrid != nil && mg.alcoveGrid.Get(x, y)
+}
+
 func (mg *MapGenerator) findDeadEnds() {
-	// The findDeadEnds() method is where we find all the dead ends in the map. We
-	// do this by iterating over the map, and for each tile we check if it is a
-	// dead end. If it is, we add it to the list of dead ends.
+	// The findDeadEnds() method is where we find all the dead ends in the map. A
+	// dead end is a corridor or door tile with only one open tile next to it.
+	// This runs over the whole map on every pass, so it walks along the rows
+	// instead of looking up each tile and its neighbours one at a time.
 
 	mg.deadEnds = make([][2]int, 0)
 
-	for y := 0; y < mg.Height; y++ {
-		for x := 0; x < mg.Width; x++ {
-			if mg.isDeadEnd(x, y) {
-				mg.deadEnds = append(mg.deadEnds, [2]int{x, y})
+	height := mg.terrainGrid.Height
+	for y := 0; y < height; y++ {
+		row := mg.terrainGrid.Row(y)
+
+		// the rows above and below, if there are any, for counting the
+		// neighbours. Anything outside the map is stone.
+		var above, below []terrain.Type
+		if y > 0 {
+			above = mg.terrainGrid.Row(y - 1)
+		}
+		if y < height-1 {
+			below = mg.terrainGrid.Row(y + 1)
+		}
+
+		for x, t := range row {
+			if t != terrain.Corridor && t != terrain.Door {
+				continue
+			}
+
+			open := 0
+			if x > 0 && row[x-1] != terrain.Stone {
+				open++
+			}
+			if x < len(row)-1 && row[x+1] != terrain.Stone {
+				open++
+			}
+			if above != nil && above[x] != terrain.Stone {
+				open++
+			}
+			if below != nil && below[x] != terrain.Stone {
+				open++
+			}
+			if open != 1 {
+				continue
+			}
+
+			// prefabs are left exactly as they were designed, and alcoves
+			// were already picked to be kept
+			if mg.protectedGrid.Get(x, y) || mg.isAlcove(x, y) {
+				continue
 			}
+
+			mg.deadEnds = append(mg.deadEnds, [2]int{x, y})
 		}
 	}
 }
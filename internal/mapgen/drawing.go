@@ -19,7 +19,7 @@ func (mg *MapGenerator) DrawDebug(screen *ebiten.Image) {
 
 			clr := color.Color(color.RGBA{0x50, 0x50, 0x50, 0xff})
 			if r != nil {
-				clr = r.clr
+				clr = r.find().clr
 			}
 
 			switch t {
@@ -31,6 +31,12 @@ func (mg *MapGenerator) DrawDebug(screen *ebiten.Image) {
 				mg.drawTile(screen, x, y, clr)
 			case terrain.Door:
 				mg.drawTile(screen, x, y, color.RGBA{0x70, 0x30, 0x30, 0xff})
+			case terrain.SecretDoor:
+				mg.drawTile(screen, x, y, color.RGBA{0x70, 0x30, 0x70, 0xff})
+			case terrain.Rubble:
+				mg.drawTile(screen, x, y, color.RGBA{0x60, 0x60, 0x40, 0xff})
+			case terrain.Water:
+				mg.drawTile(screen, x, y, color.RGBA{0x20, 0x40, 0xa0, 0xff})
 			}
 		}
 	}
@@ -0,0 +1,97 @@
+package mapgen
+
+import (
+	"github.com/matjam/sword/internal/rng"
+	"github.com/matjam/sword/internal/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Features
+
+// Feature is a terrain type that is scattered over the interior of rooms once
+// the map has been generated, such as rubble or pools of water. Chance is the
+// probability (0.0 - 1.0) that any given interior tile becomes the feature. If
+// the chances add up to more than 1, every interior tile gets a feature, split
+// between them in proportion to their chances.
+type Feature struct {
+	Type   terrain.Type
+	Chance float64
+}
+
+func (mg *MapGenerator) scatterFeatures() {
+	// The scatterFeatures() method is where we sprinkle features over the rooms.
+	// We only ever touch the interior of a room, never the ring of tiles along
+	// its walls. Every connector into a room is beside one of those edge tiles,
+	// so the ring always joins every door to every other door, no matter how
+	// much impassable rubble ends up in the middle of the room.
+
+	mg.placeTraps()
+	mg.placeSecretDoors()
+
+	// one roll decides whether a tile gets a feature at all, and the picker
+	// decides which one, so each feature comes up as often as its Chance says
+	var features rng.WeightedPicker[terrain.Type]
+	for _, feature := range mg.Features {
+		features.Add(feature.Type, feature.Chance)
+	}
+
+	if features.Len() == 0 {
+		mg.Phase = PhaseDone
+		return
+	}
+	chance := min(features.Total(), 1)
+
+	for _, room := range mg.roomList {
+		// mirrored rooms get their features from the room they're a copy of
+		if room.Prefab != nil || room.mirrored {
+			continue
+		}
+
+		for y := room.Y + 1; y < room.Y+room.Height-1; y++ {
+			for x := room.X + 1; x < room.X+room.Width-1; x++ {
+				if mg.terrainGrid.Get(x, y) != terrain.Room {
+					continue
+				}
+
+				if mg.rng.Float64() >= chance {
+					continue
+				}
+
+				feature, _ := features.Pick(mg.rng)
+				mx, my := mg.mirror(x, y)
+				mg.terrainGrid.Set(x, y, feature)
+				mg.terrainGrid.Set(mx, my, feature)
+			}
+		}
+	}
+
+	mg.Phase = PhaseDone
+}
+
+func (mg *MapGenerator) placeTraps() {
+	// The placeTraps() method turns some of the corridor tiles into traps.
+	// Traps are passable, so they never cut a corridor off, even when they
+	// land on the only way through; they just make it painful to walk.
+	//
+	// We only roll for tiles in the source area and copy the result across
+	// the axis, so symmetric maps get symmetric traps. For ordinary maps the
+	// source area is the whole map and mirror() does nothing.
+
+	if mg.TrapChance <= 0 {
+		return
+	}
+
+	for y := 0; y < mg.Height; y++ {
+		for x := 0; x < mg.Width; x++ {
+			if !mg.inSource(x, y) || mg.terrainGrid.Get(x, y) != terrain.Corridor {
+				continue
+			}
+
+			if mg.rng.Float64() < mg.TrapChance {
+				mx, my := mg.mirror(x, y)
+				mg.terrainGrid.Set(x, y, terrain.Trap)
+				mg.terrainGrid.Set(mx, my, terrain.Trap)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package mapgen
+
+import (
+	"github.com/matjam/sword/internal/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Loops
+
+func (mg *MapGenerator) openLoops() {
+	// The openLoops() method is called once every region has been connected to
+	// the root region. At that point there's only one way between any two
+	// places on the map, which makes for a lot of backtracking, so we open
+	// more doors until there are at least MinLoops loops.
+	//
+	// A connector makes a loop if the regions on both sides of it have
+	// already been merged, which the union-find tells us directly. The
+	// candidates are the connectors we skipped for that reason while
+	// connecting the map, and whatever is left over in mg.connectors. Both
+	// lists are still in the order the connectors were shuffled in when they
+	// were generated, so this doesn't draw any more random numbers, and maps
+	// without MinLoops come out exactly the same as before.
+
+	if mg.loops >= mg.MinLoops {
+		return
+	}
+
+	candidates := make([]*Connector, 0, len(mg.loopConnectors)+len(mg.connectors))
+	candidates = append(candidates, mg.loopConnectors...)
+	candidates = append(candidates, mg.connectors...)
+
+	// as with the doors that connect the map, doors look best in the walls
+	// of rooms, so try those first.
+	roomConnectors := make([]*Connector, 0, len(candidates))
+	otherConnectors := make([]*Connector, 0, len(candidates))
+	for _, c := range candidates {
+		if mg.isRoomConnector(c) {
+			roomConnectors = append(roomConnectors, c)
+		} else {
+			otherConnectors = append(otherConnectors, c)
+		}
+	}
+	candidates = append(roomConnectors, otherConnectors...)
+
+	for _, c := range candidates {
+		if mg.loops >= mg.MinLoops {
+			break
+		}
+
+		if mg.terrainGrid.Get(c.x, c.y) == terrain.Door || mg.connectorIsBesideDoor(c) {
+			continue
+		}
+
+		region := c.region1.find()
+		if region != c.region2.find() {
+			continue
+		}
+
+		mg.terrainGrid.Set(c.x, c.y, terrain.Door)
+		mg.regionGrid.Set(c.x, c.y, region)
+		mg.loops++
+
+		// on symmetric maps, the door on the other side gets opened too,
+		// which counts as another loop.
+		mg.openMirroredConnector(c)
+	}
+
+	mg.loopConnectors = nil
+}
@@ -1,12 +1,14 @@
 package mapgen
 
 import (
+	"fmt"
 	"image/color"
 	"log/slog"
 	"math/rand"
 	"time"
 
 	"github.com/matjam/sword/internal/grid"
+	"github.com/matjam/sword/internal/rng"
 	"github.com/matjam/sword/internal/terrain"
 )
 
@@ -32,6 +34,14 @@ type Room struct {
 	Height int
 
 	Region *Region
+
+	// Prefab is the prefab that was stamped into this room, or nil if this is
+	// an ordinary room.
+	Prefab *Prefab
+
+	// mirrored is true if this room is the mirrored copy of another room on a
+	// symmetric map.
+	mirrored bool
 }
 
 type Direction int
@@ -47,6 +57,28 @@ type RegionID int
 type Region struct {
 	id  RegionID
 	clr color.Color
+
+	// parent is the region that this region has been merged into, or nil if
+	// it hasn't been merged. See find().
+	parent *Region
+}
+
+// find returns the region that this region has ultimately been merged into,
+// which is the region itself if it has never been merged. The path to the
+// root is compressed as we go, so repeated lookups are cheap.
+func (r *Region) find() *Region {
+	root := r
+	for root.parent != nil {
+		root = root.parent
+	}
+
+	for r != root {
+		next := r.parent
+		r.parent = root
+		r = next
+	}
+
+	return root
 }
 
 type Connector struct {
@@ -59,31 +91,148 @@ type GenerationPhase int
 const (
 	PhaseRooms GenerationPhase = iota
 	PhaseMazes
+	PhaseMirror
 	PhaseConnectors
 	PhaseConnectingRegions
 	PhaseRemoveDeadEnds
+	PhaseFeatures
 	PhaseDone
 )
 
+var phaseNames = map[GenerationPhase]string{
+	PhaseRooms:             "rooms",
+	PhaseMazes:             "mazes",
+	PhaseMirror:            "mirror",
+	PhaseConnectors:        "connectors",
+	PhaseConnectingRegions: "connecting_regions",
+	PhaseRemoveDeadEnds:    "remove_dead_ends",
+	PhaseFeatures:          "features",
+	PhaseDone:              "done",
+}
+
+// String implements the Stringer interface.
+func (p GenerationPhase) String() string {
+	if name, ok := phaseNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("GenerationPhase(%d)", p)
+}
+
 type MapGenerator struct {
 	Width  int
 	Height int
 
 	Phase GenerationPhase
 
-	maxRoomAttempts int
-	curRoomAttempts int
+	// Features are scattered over the interior of rooms once the map has been
+	// generated. See scatterFeatures().
+	Features []Feature
+
+	// TrapChance is the probability (0.0 - 1.0) that any given corridor tile
+	// becomes a trap once the map has been generated. See placeTraps().
+	TrapChance float64
+
+	// Prefabs are placed into the map before any of the random rooms. See
+	// PlacedPrefabs() for where they ended up.
+	Prefabs []*Prefab
+
+	// BorderThickness is the number of tiles of solid stone left around the
+	// edge of the map, which nothing is carved into. Zero means the default
+	// of a single tile. See bounds().
+	BorderThickness int
+
+	// Symmetry makes the map symmetric across one or both axes. See
+	// symmetry.go for how this works.
+	Symmetry Symmetry
+
+	// CorridorStyle picks between filling the map with mazes and digging
+	// tunnels directly between rooms. See tunnels.go for the latter.
+	CorridorStyle CorridorStyle
+
+	// RoomPlacementBias makes rooms (and prefabs) more likely to be placed in
+	// the middle of the map or around its edges. The default is uniform.
+	RoomPlacementBias RoomPlacementBias
+
+	// OnPhaseChange, if set, is called whenever generation moves on to a new
+	// phase, including exactly once when it reaches PhaseDone.
+	OnPhaseChange func(oldPhase, newPhase GenerationPhase)
+
+	// Timing enables tracking how long each phase of generation takes. The
+	// results are available from Stats().
+	Timing bool
+
+	// UpdateBudget is roughly how long each call to Update() spends
+	// generating the map before it returns to the game loop, so that drawing
+	// the map while it's generated doesn't make the frame rate stutter. Zero
+	// means DefaultUpdateBudget, and a negative budget makes Update()
+	// generate the whole map at once. GenerateAll() ignores it.
+	UpdateBudget time.Duration
+
+	// MaxFailedRoomAttempts is how many random rooms in a row can fail to fit
+	// before the map is assumed to be full of rooms, and generation moves on
+	// to the corridors without using up the rest of the attempts. Zero means
+	// DefaultMaxFailedRoomAttempts.
+	MaxFailedRoomAttempts int
+
+	// MinLoops is the least number of loops the map should have. Once every
+	// region is connected, the map is a tree with exactly one way between any
+	// two places, so extra doors are opened between regions that are already
+	// connected until there are at least this many loops. There can be fewer
+	// if the map runs out of places to put a door. See openLoops().
+	MinLoops int
+
+	// PocketRoomChance is the probability (0.0 - 1.0) that a tiny maze left
+	// in a gap between rooms, with nothing else it can reach, is turned into
+	// a room instead. MaxPocketRoomSize is the largest width or height such a
+	// room can have; zero means DefaultMaxPocketRoomSize. See
+	// convertPockets().
+	PocketRoomChance  float64
+	MaxPocketRoomSize int
+
+	// MinRoomSpacing is the least number of tiles of stone between any two
+	// rooms, including prefabs and the mirrored rooms on a symmetric map, so
+	// that mazes don't squeeze awkwardly between them and there's space for
+	// decorating the walls. Zero means DefaultMinRoomSpacing. Rooms are
+	// always on odd coordinates with odd sizes, so the gaps between them are
+	// always odd, and an even spacing works just like the odd number above
+	// it. See roomFits().
+	MinRoomSpacing int
+
+	// DeadEndKeepChance is the probability (0.0 - 1.0) that the end of a
+	// dead end corridor is kept as an alcove instead of being filled in, each
+	// time dead ends are removed, so that the map keeps a few nooks to
+	// explore. The longer the dead end, the more chances it has to be kept.
+	// See removeDeadEnds() and Alcoves().
+	DeadEndKeepChance float64
+
+	// SecretDoorChance is the probability (0.0 - 1.0) that a wall between two
+	// regions becomes a secret door once the map has been generated. Every
+	// region is already connected by then, so the map never needs them. See
+	// placeSecretDoors() and SecretDoors().
+	SecretDoorChance float64
+
+	maxRoomAttempts    int
+	curRoomAttempts    int
+	failedRoomAttempts int
 
 	terrainGrid   *terrain.Terrain
 	connectorGrid *grid.Grid[*Connector]
 	regionGrid    *grid.Grid[*Region]
 
+	// protectedGrid marks tiles that must not be carved or filled in, such as
+	// the walls of prefabs.
+	protectedGrid *grid.Grid[bool]
+
 	roomList         []*Room
 	unconnectedRooms []*Room
 
+	prefabsPlaced bool
+	placedPrefabs []*PlacedPrefab
+
 	// state for maze generator
-	x int
-	y int
+	mazePlanned bool
+	x           int
+	y           int
 
 	// rows that have not yet been fully populated
 	incompleteRows []int
@@ -98,24 +247,89 @@ type MapGenerator struct {
 	// unvisited neighbour.
 	visitedMazeLocations [][2]int
 
+	// state for direct tunnels
+	tunnelsPlanned bool
+	tunnels        [][2]*Room
+
 	walking    bool
 	connecting bool
 
 	rng *rand.Rand
 
+	// seed is the seed the generator was created with. Anything worked out
+	// after the map is done, such as the themes, makes its own random numbers
+	// from it, so that it doesn't matter what else has used rng.
+	seed int64
+
 	curRegionID   RegionID
 	regions       map[RegionID]*Region
 	currentRegion *Region
 	rootRegion    *Region
+	rootRoom      *Room
 
 	connectors     []*Connector
 	rootConnectors []*Connector
 
+	// connectorRow is the next row generateConnectors() will scan
+	connectorRow int
+
+	// loopConnectors are connectors that were skipped while connecting the
+	// map because both sides were already connected, and loops is the number
+	// of doors opened that made a loop.
+	loopConnectors []*Connector
+	loops          int
+
+	// spawnable is the cached result of SpawnableTiles()
+	spawnable *grid.Grid[bool]
+
+	// regionInfo is the cached result of Regions(), keyed by region
+	regionInfo map[RegionID]*RegionInfo
+
+	// roomGraph is the cached result of RoomGraph()
+	roomGraph *RoomGraph
+
+	// themeGrid is the result of AssignThemes(), and roomThemes are the
+	// themes set with SetRoomTheme().
+	themeGrid  *grid.Grid[terrain.ThemeID]
+	roomThemes map[*Room]terrain.ThemeID
+
 	deadEnds                  [][2]int
 	deadEndsRemoved           int
 	deadEndsPreviouslyRemoved int
+
+	// alcoves are the tips of the dead ends that were kept, and alcoveGrid
+	// marks them so that they aren't found as dead ends again.
+	alcoves    [][2]int
+	alcoveGrid *grid.Grid[bool]
+
+	// secretDoors are the secret doors placed by placeSecretDoors()
+	secretDoors [][2]int
+
+	totalDuration  time.Duration
+	phaseDurations map[GenerationPhase]time.Duration
 }
 
+// AutoRoomAttempts can be passed to NewMapGenerator as the number of attempts
+// to have it worked out from the size of the map. See autoRoomAttempts().
+const AutoRoomAttempts = 0
+
+// DefaultMaxFailedRoomAttempts is the MaxFailedRoomAttempts used if it isn't
+// set. It is well above the longest run of failures on a map that still has
+// space for rooms, so it only stops generation early once the map is full.
+const DefaultMaxFailedRoomAttempts = 500
+
+// DefaultMinRoomSpacing is the MinRoomSpacing used if it isn't set. Rooms on
+// odd coordinates are always at least this far apart anyway.
+const DefaultMinRoomSpacing = 1
+
+// DefaultUpdateBudget is the UpdateBudget used if it isn't set. It leaves most
+// of a 60fps frame for everything else.
+const DefaultUpdateBudget = 4 * time.Millisecond
+
+// NewMapGenerator creates a generator for a map of the given size. attempts
+// is the number of times it tries to place a random room. The more attempts,
+// the more tightly packed the rooms are, but the right number depends on the
+// size of the map, so pass AutoRoomAttempts to have it worked out.
 func NewMapGenerator(width int, height int, seed int64, attempts int) *MapGenerator {
 	mg := &MapGenerator{
 		Phase:                PhaseRooms,
@@ -126,6 +340,7 @@ func NewMapGenerator(width int, height int, seed int64, attempts int) *MapGenera
 		terrainGrid:          terrain.NewTerrain(width, height),
 		regionGrid:           grid.NewGrid[*Region](width, height),
 		connectorGrid:        grid.NewGrid[*Connector](width, height),
+		protectedGrid:        grid.NewGrid[bool](width, height),
 		roomList:             make([]*Room, 0),
 		unconnectedRooms:     make([]*Room, 0),
 		incompleteRows:       make([]int, 0),
@@ -133,45 +348,93 @@ func NewMapGenerator(width int, height int, seed int64, attempts int) *MapGenera
 		visitedMazeLocations: make([][2]int, 0),
 		regions:              make(map[RegionID]*Region),
 		connectors:           make([]*Connector, 0),
+		phaseDurations:       make(map[GenerationPhase]time.Duration),
 	}
 
-	for y := 1; y < mg.Height-1; y += 2 {
-		mg.incompleteRows = append(mg.incompleteRows, y)
-	}
-
-	mg.rng = rand.New(rand.NewSource(seed))
+	mg.rng = rng.New(seed)
+	mg.seed = seed
 
 	return mg
 }
 
+// SetRand replaces the generator's random number generator, for callers that
+// would rather use one of their own, such as one from math/rand. It must be
+// called before generation starts. By default the generator uses rng.New, so
+// that a seed makes the same map with every version of Go.
+func (mg *MapGenerator) SetRand(r *rand.Rand) {
+	mg.rng = r
+}
+
 func (mg *MapGenerator) Update() {
 	// This generate algorithm uses the "rooms and corridors" method as described
 	// in this article: https://journal.stuffwithstuff.com/2014/12/21/rooms-and-mazes/
 	//
 	// This function is intended to be called in the Update() method of a game
 	// loop. It will generate the map incrementally, so that you can draw the
-	// map as it is being generated.
+	// map as it is being generated. Each call does as many steps as fit in
+	// the UpdateBudget, and then returns; the map is done once Phase is
+	// PhaseDone.
+
+	budget := mg.UpdateBudget
+	if budget == 0 {
+		budget = DefaultUpdateBudget
+	}
 
 	startTime := time.Now()
 	for mg.Phase != PhaseDone {
-		switch mg.Phase {
-		case PhaseRooms:
-			mg.generateRooms()
-		case PhaseMazes:
-			mg.generateMazes()
-		case PhaseConnectors:
-			mg.generateConnectors()
-		case PhaseConnectingRegions:
-			mg.connectRegions()
-		case PhaseRemoveDeadEnds:
-			mg.removeDeadEnds()
-		default:
+		mg.step()
+
+		if budget > 0 && time.Since(startTime) >= budget {
 			return
 		}
 	}
-	endTime := time.Now()
 
-	slog.Debug("Map generation finished", "time", endTime.Sub(startTime))
+	slog.Debug("Map generation finished")
+}
+
+// GenerateAll runs every phase of generation until the map is done. Use this
+// when you don't need to draw the map while it is being generated.
+func (mg *MapGenerator) GenerateAll() {
+	for mg.Phase != PhaseDone {
+		mg.step()
+	}
+}
+
+// step runs a single tick of the current generation phase. If Timing is
+// enabled, the time taken is added to the phase's total in Stats.
+func (mg *MapGenerator) step() {
+	var startTime time.Time
+	phase := mg.Phase
+	if mg.Timing {
+		startTime = time.Now()
+	}
+
+	switch mg.Phase {
+	case PhaseRooms:
+		mg.generateRooms()
+	case PhaseMazes:
+		mg.generateMazes()
+	case PhaseMirror:
+		mg.mirrorMap()
+	case PhaseConnectors:
+		mg.generateConnectors()
+	case PhaseConnectingRegions:
+		mg.connectRegions()
+	case PhaseRemoveDeadEnds:
+		mg.removeDeadEnds()
+	case PhaseFeatures:
+		mg.scatterFeatures()
+	}
+
+	if mg.Timing {
+		elapsed := time.Since(startTime)
+		mg.phaseDurations[phase] += elapsed
+		mg.totalDuration += elapsed
+	}
+
+	if mg.Phase != phase && mg.OnPhaseChange != nil {
+		mg.OnPhaseChange(phase, mg.Phase)
+	}
 }
 
 func (mg *MapGenerator) Terrain() *terrain.Terrain {
@@ -0,0 +1,1262 @@
+package mapgen_test
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matjam/sword/internal/mapgen"
+	"github.com/matjam/sword/internal/terrain"
+)
+
+var benchmarkSeeds = []int64{1, 42, 1337, 8675309}
+
+// update rewrites the golden files in testdata instead of comparing against
+// them. Run "go test ./internal/mapgen -run TestGolden -update" after a change
+// that is meant to change the maps a seed makes.
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestOnPhaseChange(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	mg := mapgen.NewMapGenerator(41, 31, 1, 100)
+
+	var changes [][2]mapgen.GenerationPhase
+	mg.OnPhaseChange = func(oldPhase, newPhase mapgen.GenerationPhase) {
+		changes = append(changes, [2]mapgen.GenerationPhase{oldPhase, newPhase})
+	}
+
+	for mg.Phase != mapgen.PhaseDone {
+		mg.Update()
+	}
+
+	if len(changes) == 0 || changes[0][0] != mapgen.PhaseRooms {
+		t.Fatalf("expected the first change to be from the rooms phase, got %v", changes)
+	}
+
+	done := 0
+	for i, change := range changes {
+		if change[0] == change[1] {
+			t.Errorf("change %d doesn't change phase: %v", i, change)
+		}
+		if i > 0 && change[0] != changes[i-1][1] {
+			t.Errorf("change %d doesn't follow on from the last one: %v then %v", i, changes[i-1], change)
+		}
+		if change[1] == mapgen.PhaseDone {
+			done++
+		}
+	}
+
+	if done != 1 {
+		t.Errorf("expected exactly one change to PhaseDone, got %d", done)
+	}
+}
+
+func TestUpdateBudget(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	all := mapgen.NewMapGenerator(61, 41, 1, 200)
+	all.UpdateBudget = time.Nanosecond // ignored
+	all.GenerateAll()
+
+	// with a tiny budget, each Update only does a single step
+	mg := mapgen.NewMapGenerator(61, 41, 1, 200)
+	mg.UpdateBudget = time.Nanosecond
+
+	updates := 0
+	for mg.Phase != mapgen.PhaseDone {
+		mg.Update()
+		updates++
+	}
+
+	if updates < 100 {
+		t.Errorf("expected generation to be spread over many updates, got %d", updates)
+	}
+	if !mg.Terrain().Equal(all.Terrain()) {
+		t.Error("expected the map to be the same however it was generated")
+	}
+
+	// without a budget, a single Update generates the whole map
+	mg = mapgen.NewMapGenerator(61, 41, 1, 200)
+	mg.UpdateBudget = -1
+	mg.Update()
+	if mg.Phase != mapgen.PhaseDone {
+		t.Errorf("expected a negative budget to generate the whole map, got phase %v", mg.Phase)
+	}
+}
+
+func TestSpawnableTiles(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	mg := mapgen.NewMapGenerator(41, 31, 1, 100)
+
+	if mg.SpawnableTiles() != nil {
+		t.Fatal("expected no spawnable tiles before the map is generated")
+	}
+
+	mg.GenerateAll()
+
+	spawnable := mg.SpawnableTiles()
+	if spawnable != mg.SpawnableTiles() {
+		t.Error("expected the spawnable tiles to be cached")
+	}
+
+	// the map is fully connected, so every room and corridor tile should be
+	// spawnable, and nothing else.
+	tr := mg.Terrain()
+	count := 0
+	for y := 0; y < tr.Height; y++ {
+		for x := 0; x < tr.Width; x++ {
+			tt := tr.Get(x, y)
+			open := tt == terrain.Room || tt == terrain.Corridor
+
+			if spawnable.Get(x, y) != open {
+				t.Errorf("tile %d,%d is %v, but spawnable is %v", x, y, tt, spawnable.Get(x, y))
+			}
+			if spawnable.Get(x, y) {
+				count++
+			}
+		}
+	}
+
+	if count == 0 {
+		t.Error("expected some spawnable tiles")
+	}
+}
+
+func TestTraps(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	generate := func(trapChance float64) *terrain.Terrain {
+		mg := mapgen.NewMapGenerator(41, 31, 1, 100)
+		mg.TrapChance = trapChance
+		mg.GenerateAll()
+		return mg.Terrain()
+	}
+
+	plain := generate(0)
+	trapped := generate(0.2)
+
+	if !trapped.Equal(generate(0.2)) {
+		t.Error("expected the same seed to place the same traps")
+	}
+
+	traps := 0
+	for y := 0; y < plain.Height; y++ {
+		for x := 0; x < plain.Width; x++ {
+			if trapped.Get(x, y) != terrain.Trap {
+				continue
+			}
+
+			traps++
+			if plain.Get(x, y) != terrain.Corridor {
+				t.Errorf("trap at %d,%d should be on a corridor, not %v", x, y, plain.Get(x, y))
+			}
+		}
+	}
+
+	if traps == 0 {
+		t.Error("expected some traps")
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	generate := func(features ...mapgen.Feature) *mapgen.MapGenerator {
+		mg := mapgen.NewMapGenerator(127, 127, 1, 500)
+		mg.Features = features
+		mg.GenerateAll()
+		return mg
+	}
+
+	plain := generate()
+	mg := generate(mapgen.Feature{Type: terrain.Rubble, Chance: 0.2}, mapgen.Feature{Type: terrain.Water, Chance: 0.1})
+
+	// features only go on the floor inside the rooms, never on the ring of
+	// tiles along the walls
+	interior := 0
+	counts := make(map[terrain.Type]int)
+	for y := 0; y < mg.Height; y++ {
+		for x := 0; x < mg.Width; x++ {
+			got, was := mg.Terrain().Get(x, y), plain.Terrain().Get(x, y)
+			room := plain.RoomAt(x, y)
+			inside := room != nil && x > room.X && y > room.Y && x < room.X+room.Width-1 && y < room.Y+room.Height-1
+
+			if inside && was == terrain.Room {
+				interior++
+			}
+			if got == was {
+				continue
+			}
+
+			counts[got]++
+			if !inside || was != terrain.Room {
+				t.Errorf("expected no feature at %d,%d, got %v", x, y, got)
+			}
+		}
+	}
+
+	// each feature covers about its Chance of the floor
+	for _, feature := range mg.Features {
+		share := float64(counts[feature.Type]) / float64(interior)
+		if math.Abs(share-feature.Chance) > 0.015 {
+			t.Errorf("expected about %.0f%% of the floor to be %v, got %.1f%%", feature.Chance*100, feature.Type, share*100)
+		}
+	}
+
+	if !mg.Terrain().Equal(generate(mg.Features...).Terrain()) {
+		t.Error("expected the same seed to place the same features")
+	}
+}
+
+func TestBorderThickness(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for _, border := range []int{1, 2, 3, 4} {
+		for _, symmetry := range []mapgen.Symmetry{mapgen.SymmetryNone, mapgen.SymmetryRotational} {
+			t.Run(fmt.Sprintf("border %d symmetry %d", border, symmetry), func(t *testing.T) {
+				mg := mapgen.NewMapGenerator(41, 31, 1, 100)
+				mg.BorderThickness = border
+				mg.Symmetry = symmetry
+				mg.GenerateAll()
+
+				tr := mg.Terrain()
+				open := 0
+				for y := 0; y < tr.Height; y++ {
+					for x := 0; x < tr.Width; x++ {
+						if tr.Get(x, y) == terrain.Stone {
+							continue
+						}
+
+						open++
+						if x < border || y < border || x >= tr.Width-border || y >= tr.Height-border {
+							t.Errorf("tile %d,%d is inside the border", x, y)
+						}
+					}
+				}
+
+				if open == 0 {
+					t.Error("expected something to be carved")
+				}
+			})
+		}
+	}
+
+	// a border that leaves no room for even the smallest room gives up
+	// rather than hanging.
+	mg := mapgen.NewMapGenerator(11, 11, 1, 100)
+	mg.BorderThickness = 4
+	mg.GenerateAll()
+
+	if mg.Phase != mapgen.PhaseDone {
+		t.Errorf("expected generation to finish, got %v", mg.Phase)
+	}
+}
+
+func TestPlaceRoom(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	mg := mapgen.NewMapGenerator(41, 31, 1, 100)
+
+	tests := []struct {
+		name       string
+		x, y, w, h int
+		expected   bool
+	}{
+		{"fits", 5, 5, 7, 5, true},
+		{"overlaps", 9, 7, 5, 5, false},
+		{"even position", 20, 5, 5, 5, false},
+		{"even size", 21, 5, 4, 5, false},
+		{"too small", 21, 5, 1, 5, false},
+		{"in the border", 0, 5, 5, 5, false},
+		{"past the edge", 37, 5, 5, 5, false},
+		{"fits beside the first", 21, 5, 3, 3, true},
+	}
+
+	for _, tt := range tests {
+		if placed := mg.PlaceRoom(tt.x, tt.y, tt.w, tt.h); placed != tt.expected {
+			t.Errorf("%s: expected PlaceRoom(%d, %d, %d, %d) to be %v, got %v", tt.name, tt.x, tt.y, tt.w, tt.h, tt.expected, placed)
+		}
+	}
+
+	mg.GenerateAll()
+
+	// the pinned rooms are still there, and connected to the rest of the map
+	spawnable := mg.SpawnableTiles()
+	for _, tt := range tests {
+		if !tt.expected {
+			continue
+		}
+
+		room := mg.RoomAt(tt.x, tt.y)
+		if room == nil || room.X != tt.x || room.Y != tt.y || room.Width != tt.w || room.Height != tt.h {
+			t.Errorf("%s: expected the room at %d,%d to be kept, got %+v", tt.name, tt.x, tt.y, room)
+		}
+		if !spawnable.Get(tt.x, tt.y) {
+			t.Errorf("%s: expected the room to be connected to the rest of the map", tt.name)
+		}
+	}
+
+	if mg.PlaceRoom(29, 21, 3, 3) {
+		t.Error("expected PlaceRoom to fail once the map has been generated")
+	}
+}
+
+func TestRoomPerimeter(t *testing.T) {
+	room := &mapgen.Room{X: 5, Y: 7, Width: 5, Height: 3}
+
+	perimeter := room.Perimeter()
+	if len(perimeter) != 12 {
+		t.Fatalf("expected 12 perimeter tiles, got %d", len(perimeter))
+	}
+
+	for _, p := range perimeter {
+		if !room.Contains(p[0], p[1]) {
+			t.Errorf("perimeter tile %v is outside the room", p)
+		}
+		if p == [2]int{7, 8} {
+			t.Errorf("the middle of the room shouldn't be on the perimeter")
+		}
+	}
+}
+
+func TestCorridorStats(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	mg := mapgen.NewMapGenerator(41, 31, 1, 100)
+
+	if stats := mg.Stats(); stats.CorridorTurns != 0 || stats.LongestStraightCorridor != 0 {
+		t.Errorf("expected no corridors before generation, got %+v", stats)
+	}
+
+	mg.GenerateAll()
+	stats := mg.Stats()
+
+	// the maze is carved two tiles at a time, so its corridors both turn and
+	// run straight for a while somewhere on a map this size.
+	if stats.CorridorTurns == 0 {
+		t.Error("expected the corridors to turn")
+	}
+	if stats.LongestStraightCorridor < 3 || stats.LongestStraightCorridor > 39 {
+		t.Errorf("expected the longest straight corridor to fit inside the border, got %d", stats.LongestStraightCorridor)
+	}
+
+	if again := mg.Stats(); again.CorridorTurns != stats.CorridorTurns || again.LongestStraightCorridor != stats.LongestStraightCorridor {
+		t.Errorf("expected the stats to be the same every time, got %+v then %+v", stats, again)
+	}
+}
+
+func TestCorridorDirect(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for _, seed := range benchmarkSeeds {
+		mg := mapgen.NewMapGenerator(61, 41, seed, 200)
+		mg.CorridorStyle = mapgen.CorridorDirect
+		mg.GenerateAll()
+
+		// the tunnels and doors join every room into a single region
+		regions := mg.Regions()
+		if len(regions) != 1 {
+			t.Errorf("seed %d: expected the map to be one region, got %d", seed, len(regions))
+			continue
+		}
+
+		rooms := mg.RoomsIn(regions[0].ID)
+		if len(rooms) < 2 || len(rooms) != mg.Stats().Rooms {
+			t.Errorf("seed %d: expected all %d rooms in the region, got %d", seed, mg.Stats().Rooms, len(rooms))
+		}
+
+		tr := mg.Terrain()
+		if tr.Count(terrain.Corridor) == 0 {
+			t.Errorf("seed %d: expected some tunnels", seed)
+		}
+
+		open := tr.CountMatching(func(t terrain.Type) bool { return t != terrain.Stone })
+		x, y := rooms[0].X, rooms[0].Y
+		if reached := reachableTiles(tr, x, y); reached != open {
+			t.Errorf("seed %d: expected to reach all %d open tiles from %d,%d, got %d", seed, open, x, y, reached)
+		}
+
+		// tunnels stop at the walls, so they never cut through a room
+		for _, room := range rooms {
+			for ry := room.Y; ry < room.Y+room.Height; ry++ {
+				for rx := room.X; rx < room.X+room.Width; rx++ {
+					if tr.Get(rx, ry) == terrain.Corridor {
+						t.Errorf("seed %d: expected no tunnel inside the room at %d,%d, found one at %d,%d", seed, room.X, room.Y, rx, ry)
+					}
+				}
+			}
+		}
+	}
+}
+
+// reachableTiles returns how many open tiles can be reached from x, y by
+// walking between neighbouring tiles that aren't stone.
+func reachableTiles(tr *terrain.Terrain, x, y int) int {
+	seen := make(map[[2]int]bool)
+	queue := [][2]int{{x, y}}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+
+		if seen[p] || p[0] < 0 || p[1] < 0 || p[0] >= tr.Width || p[1] >= tr.Height || tr.Get(p[0], p[1]) == terrain.Stone {
+			continue
+		}
+		seen[p] = true
+
+		queue = append(queue, [2]int{p[0] - 1, p[1]}, [2]int{p[0] + 1, p[1]}, [2]int{p[0], p[1] - 1}, [2]int{p[0], p[1] + 1})
+	}
+	return len(seen)
+}
+
+func TestPrefabs(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	tr, err := terrain.FromString(`.......
+.~...~.
+...#...
+.~...~.
+.......
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefab := &mapgen.Prefab{Name: "fountains", Terrain: tr, AnchorX: 3, AnchorY: 2}
+
+	mg := mapgen.NewMapGenerator(61, 41, 42, 200)
+	mg.Prefabs = []*mapgen.Prefab{prefab}
+	mg.GenerateAll()
+
+	placed := mg.PlacedPrefabs()
+	if len(placed) != 1 || placed[0].Prefab != prefab {
+		t.Fatalf("expected the prefab to be placed, got %v", placed)
+	}
+
+	room := placed[0].Room
+	if room.Prefab != prefab || room.Width != tr.Width || room.Height != tr.Height {
+		t.Errorf("expected the prefab's room to be %dx%d, got %+v", tr.Width, tr.Height, room)
+	}
+	if x, y := placed[0].Anchor(); x != room.X+3 || y != room.Y+2 {
+		t.Errorf("expected the anchor at %d,%d, got %d,%d", room.X+3, room.Y+2, x, y)
+	}
+
+	// nothing later in generation touches the prefab's tiles, not even its
+	// stone
+	for y := 0; y < tr.Height; y++ {
+		for x := 0; x < tr.Width; x++ {
+			if got := mg.Terrain().Get(room.X+x, room.Y+y); got != tr.Get(x, y) {
+				t.Errorf("expected the prefab's tile at %d,%d to be %v, got %v", x, y, tr.Get(x, y), got)
+			}
+		}
+	}
+}
+
+func TestSymmetry(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	const width, height = 61, 41
+
+	// the map is mirrored across the middle row and column, which are both
+	// even for a map this size
+	axisX, axisY := (width-1)/2, (height-1)/2
+
+	for _, tt := range []struct {
+		name     string
+		symmetry mapgen.Symmetry
+		mirror   func(x, y int) (int, int)
+	}{
+		{"horizontal", mapgen.SymmetryHorizontal, func(x, y int) (int, int) { return axisX*2 - x, y }},
+		{"vertical", mapgen.SymmetryVertical, func(x, y int) (int, int) { return x, axisY*2 - y }},
+		{"rotational", mapgen.SymmetryRotational, func(x, y int) (int, int) { return axisX*2 - x, axisY*2 - y }},
+	} {
+		for _, seed := range benchmarkSeeds {
+			mg := mapgen.NewMapGenerator(width, height, seed, 200)
+			mg.Symmetry = tt.symmetry
+
+			// the doors and dead ends are picked at random after the map is
+			// mirrored, so only the map as it is straight after mirroring is
+			// exactly symmetric
+			var mirrored *terrain.Terrain
+			mg.OnPhaseChange = func(oldPhase, newPhase mapgen.GenerationPhase) {
+				if newPhase == mapgen.PhaseConnectors {
+					mirrored, _ = terrain.FromString(mg.Terrain().String())
+				}
+			}
+			mg.GenerateAll()
+
+			if mirrored == nil {
+				t.Fatalf("%s seed %d: expected the map to be mirrored", tt.name, seed)
+			}
+			if mirrored.Count(terrain.Room) == 0 {
+				t.Errorf("%s seed %d: expected some rooms", tt.name, seed)
+			}
+
+			asymmetric := 0
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					mx, my := tt.mirror(x, y)
+					if mirrored.Get(x, y) != mirrored.Get(mx, my) {
+						asymmetric++
+					}
+				}
+			}
+			if asymmetric > 0 {
+				t.Errorf("%s seed %d: expected the mirrored map to be symmetric, %d tiles aren't", tt.name, seed, asymmetric)
+			}
+
+			// the two halves are joined up by the connectors
+			regions := mg.Regions()
+			if len(regions) != 1 {
+				t.Errorf("%s seed %d: expected the map to be one region, got %d", tt.name, seed, len(regions))
+				continue
+			}
+
+			tr := mg.Terrain()
+			room := mg.RoomsIn(regions[0].ID)[0]
+			open := tr.CountMatching(func(t terrain.Type) bool { return t != terrain.Stone })
+			if reached := reachableTiles(tr, room.X, room.Y); reached != open {
+				t.Errorf("%s seed %d: expected to reach all %d open tiles, got %d", tt.name, seed, open, reached)
+			}
+		}
+	}
+}
+
+func TestRegions(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	mg := mapgen.NewMapGenerator(61, 41, 3, 300)
+	if !mg.PlaceRoom(3, 3, 5, 5) {
+		t.Fatal("failed to place the room")
+	}
+
+	if mg.Regions() != nil {
+		t.Fatal("expected no regions before the map is generated")
+	}
+
+	mg.GenerateAll()
+
+	// the connector phase joins everything into a single region
+	regions := mg.Regions()
+	if len(regions) != 1 {
+		t.Fatalf("expected the map to be one region, got %d", len(regions))
+	}
+
+	tiles := mg.Terrain().CountMatching(func(t terrain.Type) bool { return t != terrain.Stone })
+
+	if regions[0].Tiles != tiles {
+		t.Errorf("expected the region to have all %d open tiles, got %d", tiles, regions[0].Tiles)
+	}
+	if regions[0].Rooms != mg.Stats().Rooms {
+		t.Errorf("expected the region to have all %d rooms, got %d", mg.Stats().Rooms, regions[0].Rooms)
+	}
+
+	region, ok := mg.RegionAt(5, 5)
+	if !ok || region.ID != regions[0].ID {
+		t.Errorf("expected the room at 5,5 to be in region %d, got %v %v", regions[0].ID, region, ok)
+	}
+
+	if _, ok := mg.RegionAt(0, 0); ok {
+		t.Error("expected the border to have no region")
+	}
+}
+
+func TestRoomGraph(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	mg := mapgen.NewMapGenerator(61, 41, 42, 200)
+	mg.MinLoops = 3
+
+	if mg.RoomGraph() != nil {
+		t.Fatal("expected no room graph before the map is generated")
+	}
+
+	mg.GenerateAll()
+
+	graph := mg.RoomGraph()
+	if len(graph.Rooms) != mg.Stats().Rooms {
+		t.Fatalf("expected all %d rooms in the graph, got %d", mg.Stats().Rooms, len(graph.Rooms))
+	}
+
+	region := mg.Regions()[0]
+	if rooms := mg.RoomsIn(region.ID); len(rooms) != len(graph.Rooms) {
+		t.Errorf("expected every room to be in region %d, got %d", region.ID, len(rooms))
+	}
+
+	tr := mg.Terrain()
+	isOutside := func(room *mapgen.Room, door [2]int) bool {
+		if room.Contains(door[0], door[1]) || !tr.Get(door[0], door[1]).IsPassable() {
+			return false
+		}
+		for _, d := range [][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+			if room.Contains(door[0]+d[0], door[1]+d[1]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, room := range graph.Rooms {
+		for _, link := range graph.Neighbors(room) {
+			if !isOutside(room, link.Door) {
+				t.Errorf("expected %v to be just outside the room at %d,%d", link.Door, room.X, room.Y)
+			}
+			if !isOutside(link.Room, link.NeighborDoor) {
+				t.Errorf("expected %v to be just outside the room at %d,%d", link.NeighborDoor, link.Room.X, link.Room.Y)
+			}
+			if link.Door == link.NeighborDoor && link.Length != 0 {
+				t.Errorf("expected a shared door to have no length, got %d", link.Length)
+			}
+
+			// the rooms are neighbours both ways
+			back := false
+			for _, other := range graph.Neighbors(link.Room) {
+				back = back || other.Room == room
+			}
+			if !back {
+				t.Errorf("expected the room at %d,%d to link back to %d,%d", link.Room.X, link.Room.Y, room.X, room.Y)
+			}
+		}
+	}
+
+	// the map is connected, so every room can be reached from the first
+	entrance := graph.Rooms[0]
+	distances := graph.Distances(entrance)
+	if len(distances) != len(graph.Rooms) {
+		t.Errorf("expected to reach all %d rooms, got %d", len(graph.Rooms), len(distances))
+	}
+
+	if graph.Distance(entrance, entrance) != 0 {
+		t.Errorf("expected a room to be 0 hops from itself, got %d", graph.Distance(entrance, entrance))
+	}
+
+	for _, link := range graph.Neighbors(entrance) {
+		if distance := graph.Distance(entrance, link.Room); distance != 1 {
+			t.Errorf("expected a neighbour to be 1 hop away, got %d", distance)
+		}
+	}
+
+	farthest, hops := graph.Farthest(entrance)
+	if hops < 2 || graph.Distance(farthest, entrance) != hops {
+		t.Errorf("expected the farthest room to be at least 2 hops away both ways, got %d", hops)
+	}
+	for room, distance := range distances {
+		if distance > hops {
+			t.Errorf("expected no room farther than %d hops, got the room at %d,%d at %d", hops, room.X, room.Y, distance)
+		}
+	}
+
+	if room, hops := graph.Farthest(&mapgen.Room{}); room != nil || hops != -1 {
+		t.Errorf("expected a room that isn't in the map to have no farthest room, got %v %d", room, hops)
+	}
+}
+
+func TestRoomClusters(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// the first two rooms only have a wall between them, so the only way into
+	// the first is a door straight into the second. There's just enough
+	// space between the second and third for a corridor.
+	mg := mapgen.NewMapGenerator(21, 7, 1, 0)
+	for _, x := range []int{1, 7, 15} {
+		if !mg.PlaceRoom(x, 1, 5, 5) {
+			t.Fatalf("failed to place the room at %d,1", x)
+		}
+	}
+
+	if mg.RoomClusters() != nil {
+		t.Fatal("expected no clusters before the map is generated")
+	}
+
+	mg.GenerateAll()
+
+	clusters := mg.RoomClusters()
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(clusters))
+	}
+
+	if len(clusters[0]) != 2 || clusters[0][0].X != 1 || clusters[0][1].X != 7 {
+		t.Errorf("expected the first two rooms to be a cluster, got %v", clusters[0])
+	}
+
+	if len(clusters[1]) != 1 || clusters[1][0].X != 15 {
+		t.Errorf("expected the corridor-linked room on its own, got %v", clusters[1])
+	}
+}
+
+func TestGenerateBest(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	seeds := []int64{1, 2, 3, 4, 5, 6}
+	rooms := func(mg *mapgen.MapGenerator) float64 {
+		return float64(mg.Stats().Rooms)
+	}
+
+	// work out the answer one map at a time
+	var expectedSeed int64
+	most := -1
+	for _, seed := range seeds {
+		mg := mapgen.NewMapGenerator(41, 31, seed, mapgen.AutoRoomAttempts)
+		mg.GenerateAll()
+		if mg.Stats().Rooms > most {
+			expectedSeed, most = seed, mg.Stats().Rooms
+		}
+	}
+
+	best, seed := mapgen.GenerateBest(41, 31, seeds, rooms)
+	if seed != expectedSeed || best.Stats().Rooms != most {
+		t.Errorf("expected seed %d with %d rooms, got seed %d with %d", expectedSeed, most, seed, best.Stats().Rooms)
+	}
+
+	if best.Phase != mapgen.PhaseDone {
+		t.Errorf("expected the best map to be finished, got %v", best.Phase)
+	}
+
+	// when every map scores the same, the first seed wins
+	if _, seed := mapgen.GenerateBest(41, 31, seeds, func(*mapgen.MapGenerator) float64 { return 1 }); seed != seeds[0] {
+		t.Errorf("expected a tie to go to seed %d, got %d", seeds[0], seed)
+	}
+
+	if best, seed := mapgen.GenerateBest(41, 31, nil, rooms); best != nil || seed != 0 {
+		t.Errorf("expected nothing without any seeds, got %v %d", best, seed)
+	}
+}
+
+func TestAssignThemes(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	generate := func() *mapgen.MapGenerator {
+		mg := mapgen.NewMapGenerator(41, 31, 42, mapgen.AutoRoomAttempts)
+		if mg.AssignThemes(3) != nil {
+			t.Fatal("expected no themes before the map is generated")
+		}
+		mg.GenerateAll()
+		return mg
+	}
+
+	mg := generate()
+	themes := mg.AssignThemes(3)
+	if themes == nil || mg.Themes() != themes {
+		t.Fatal("expected the themes to be assigned")
+	}
+
+	// every theme is used, and every room is all one theme
+	used := make(map[terrain.ThemeID]bool)
+	for _, room := range mg.RoomGraph().Rooms {
+		theme := themes.Get(room.X, room.Y)
+		used[theme] = true
+
+		for y := room.Y; y < room.Y+room.Height; y++ {
+			for x := room.X; x < room.X+room.Width; x++ {
+				if mg.Terrain().Get(x, y) != terrain.Stone && themes.Get(x, y) != theme {
+					t.Fatalf("expected the room at %d,%d to be all theme %d, got %d at %d,%d", room.X, room.Y, theme, themes.Get(x, y), x, y)
+				}
+			}
+		}
+	}
+	if len(used) != 3 {
+		t.Errorf("expected the rooms to use all 3 themes, got %v", used)
+	}
+
+	// the same seed gets the same themes
+	again := generate().AssignThemes(3)
+	for y := 0; y < mg.Height; y++ {
+		for x := 0; x < mg.Width; x++ {
+			if again.Get(x, y) != themes.Get(x, y) {
+				t.Fatalf("expected the same themes for the same seed, differ at %d,%d", x, y)
+			}
+		}
+	}
+
+	// a room with a theme of its own keeps it
+	room := mg.RoomGraph().Rooms[0]
+	mg.SetRoomTheme(room, 7)
+	if themes := mg.AssignThemes(3); themes.Get(room.X, room.Y) != 7 {
+		t.Errorf("expected the room to keep theme 7, got %d", themes.Get(room.X, room.Y))
+	}
+}
+
+func TestMinRoomSpacing(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// gap returns the number of tiles between two rooms, going by the axis
+	// they're furthest apart on.
+	gap := func(a, b *mapgen.Room) int {
+		gapX := max(b.X-(a.X+a.Width), a.X-(b.X+b.Width))
+		gapY := max(b.Y-(a.Y+a.Height), a.Y-(b.Y+b.Height))
+		return max(gapX, gapY)
+	}
+
+	for _, tt := range []struct {
+		spacing  int
+		symmetry mapgen.Symmetry
+	}{
+		{1, mapgen.SymmetryNone},
+		{2, mapgen.SymmetryNone},
+		{2, mapgen.SymmetryHorizontal},
+		{2, mapgen.SymmetryRotational},
+	} {
+		for _, seed := range benchmarkSeeds {
+			mg := mapgen.NewMapGenerator(61, 41, seed, mapgen.AutoRoomAttempts)
+			mg.MinRoomSpacing = tt.spacing
+			mg.Symmetry = tt.symmetry
+			mg.GenerateAll()
+
+			rooms := mg.RoomGraph().Rooms
+			if len(rooms) < 2 {
+				t.Fatalf("spacing %d, seed %d: expected several rooms, got %d", tt.spacing, seed, len(rooms))
+			}
+
+			closest := math.MaxInt
+			for i, a := range rooms {
+				if a.X%2 == 0 || a.Y%2 == 0 {
+					t.Errorf("spacing %d, seed %d: expected the room at %d,%d to be on odd coordinates", tt.spacing, seed, a.X, a.Y)
+				}
+				for _, b := range rooms[i+1:] {
+					closest = min(closest, gap(a, b))
+				}
+			}
+
+			if closest < tt.spacing {
+				t.Errorf("spacing %d, symmetry %d, seed %d: expected rooms at least %d apart, got %d", tt.spacing, tt.symmetry, seed, tt.spacing, closest)
+			}
+
+			// gaps are always odd, so a spacing of 2 keeps rooms 3 apart
+			if closest%2 == 0 {
+				t.Errorf("spacing %d, seed %d: expected an odd gap, got %d", tt.spacing, seed, closest)
+			}
+		}
+	}
+}
+
+func TestMinLoops(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	tree := mapgen.NewMapGenerator(61, 41, 42, 200)
+	tree.GenerateAll()
+	if loops := tree.Stats().Loops; loops != 0 {
+		t.Errorf("expected no loops by default, got %d", loops)
+	}
+
+	mg := mapgen.NewMapGenerator(61, 41, 42, 200)
+	mg.MinLoops = 5
+	mg.GenerateAll()
+
+	loops := mg.Stats().Loops
+	if loops < mg.MinLoops {
+		t.Fatalf("expected at least %d loops, got %d", mg.MinLoops, loops)
+	}
+
+	// opening the loops doesn't use the random number generator, so the map
+	// is the same apart from the extra doors.
+	if got, want := mg.Terrain().Count(terrain.Door), tree.Terrain().Count(terrain.Door)+loops; got != want {
+		t.Errorf("expected %d doors, got %d", want, got)
+	}
+
+	if regions := mg.Regions(); len(regions) != 1 {
+		t.Errorf("expected the map to still be one region, got %d", len(regions))
+	}
+}
+
+func TestPocketRooms(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// seed 2 leaves little mazes in gaps between rooms
+	plain := mapgen.NewMapGenerator(61, 41, 2, 200)
+	plain.GenerateAll()
+
+	mg := mapgen.NewMapGenerator(61, 41, 2, 200)
+	mg.PocketRoomChance = 1
+	mg.GenerateAll()
+
+	if got, want := mg.Stats().Rooms, plain.Stats().Rooms; got <= want {
+		t.Errorf("expected more than %d rooms, got %d", want, got)
+	}
+
+	// the new room is a proper room, connected to the rest of the map
+	tr := mg.Terrain()
+	for y := 0; y < tr.Height; y++ {
+		for x := 0; x < tr.Width; x++ {
+			if tr.Get(x, y) == terrain.Room && mg.RoomAt(x, y) == nil {
+				t.Errorf("room tile %d,%d isn't inside a room", x, y)
+			}
+		}
+	}
+
+	if regions := mg.Regions(); len(regions) != 1 {
+		t.Errorf("expected the map to be one region, got %d", len(regions))
+	}
+}
+
+func TestDeadEndKeepChance(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	generate := func(keepChance float64) *mapgen.MapGenerator {
+		mg := mapgen.NewMapGenerator(61, 41, 1, 200)
+		mg.DeadEndKeepChance = keepChance
+		mg.GenerateAll()
+		return mg
+	}
+
+	plain := generate(0)
+	if len(plain.Alcoves()) != 0 || plain.Stats().Alcoves != 0 {
+		t.Errorf("expected no alcoves without DeadEndKeepChance, got %d", len(plain.Alcoves()))
+	}
+
+	mg := generate(0.05)
+	alcoves := mg.Alcoves()
+	if len(alcoves) == 0 {
+		t.Fatal("expected some alcoves")
+	}
+	if mg.Stats().Alcoves != len(alcoves) {
+		t.Errorf("expected Stats to count %d alcoves, got %d", len(alcoves), mg.Stats().Alcoves)
+	}
+	if mg.Stats().DeadEndsRemoved >= plain.Stats().DeadEndsRemoved {
+		t.Errorf("expected fewer dead ends to be removed than %d, got %d", plain.Stats().DeadEndsRemoved, mg.Stats().DeadEndsRemoved)
+	}
+	if !mg.Terrain().Equal(generate(0.05).Terrain()) {
+		t.Error("expected the same seed to keep the same alcoves")
+	}
+
+	// every alcove is still the tip of a dead end
+	tr := mg.Terrain()
+	for _, alcove := range alcoves {
+		x, y := alcove[0], alcove[1]
+		if tile := tr.Get(x, y); tile != terrain.Corridor && tile != terrain.Door {
+			t.Errorf("expected the alcove at %d,%d to be a corridor or door, got %v", x, y, tile)
+		}
+
+		open := 0
+		for _, n := range [][2]int{{x, y - 1}, {x, y + 1}, {x - 1, y}, {x + 1, y}} {
+			if tr.Get(n[0], n[1]) != terrain.Stone {
+				open++
+			}
+		}
+		if open != 1 {
+			t.Errorf("expected the alcove at %d,%d to have one open neighbour, got %d", x, y, open)
+		}
+	}
+
+	if regions := mg.Regions(); len(regions) != 1 {
+		t.Errorf("expected the map to be one region, got %d", len(regions))
+	}
+}
+
+func TestSecretDoors(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	generate := func(chance float64) *mapgen.MapGenerator {
+		mg := mapgen.NewMapGenerator(61, 41, 1, 200)
+		mg.SecretDoorChance = chance
+		mg.GenerateAll()
+		return mg
+	}
+
+	plain := generate(0)
+	if len(plain.SecretDoors()) != 0 || plain.Terrain().Count(terrain.SecretDoor) != 0 {
+		t.Error("expected no secret doors without SecretDoorChance")
+	}
+
+	mg := generate(0.5)
+	doors := mg.SecretDoors()
+	if len(doors) == 0 {
+		t.Fatal("expected some secret doors")
+	}
+	if mg.Stats().SecretDoors != len(doors) || mg.Terrain().Count(terrain.SecretDoor) != len(doors) {
+		t.Errorf("expected %d secret doors in the stats and the terrain, got %d and %d",
+			len(doors), mg.Stats().SecretDoors, mg.Terrain().Count(terrain.SecretDoor))
+	}
+	if !mg.Terrain().Equal(generate(0.5).Terrain()) {
+		t.Error("expected the same seed to place the same secret doors")
+	}
+
+	// apart from the secret doors, the map is the same, and everywhere that
+	// could be reached before still can be without going through them
+	tr := mg.Terrain()
+	for _, door := range doors {
+		x, y := door[0], door[1]
+		if plain.Terrain().Get(x, y) != terrain.Stone {
+			t.Errorf("expected the secret door at %d,%d to be in a wall", x, y)
+		}
+
+		// a secret door leads from one floor to another, straight through
+		// the wall
+		horizontal := tr.Get(x-1, y).IsPassable() && tr.Get(x+1, y).IsPassable()
+		vertical := tr.Get(x, y-1).IsPassable() && tr.Get(x, y+1).IsPassable()
+		if horizontal == vertical {
+			t.Errorf("expected the secret door at %d,%d to join two floors across the wall", x, y)
+		}
+	}
+	if !mg.SpawnableTiles().Equal(plain.SpawnableTiles(), func(a, b bool) bool { return a == b }) {
+		t.Error("expected the secret doors not to change which tiles can be reached")
+	}
+
+	// secret doors are themed like the walls they're hidden in, so they
+	// don't join up the wings on either side of them
+	if !mg.AssignThemes(3).Equal(plain.AssignThemes(3), func(a, b terrain.ThemeID) bool { return a == b }) {
+		t.Error("expected the secret doors not to change the themes")
+	}
+
+	x, y := doors[0][0], doors[0][1]
+	if !tr.RevealSecretDoor(x, y) || tr.Get(x, y) != terrain.Door {
+		t.Error("expected revealing a secret door to turn it into a door")
+	}
+	if tr.RevealSecretDoor(x, y) {
+		t.Error("expected a secret door to only be revealed once")
+	}
+}
+
+func TestSecretDoors_Symmetry(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// a 61 wide map is mirrored across column 30
+	for _, seed := range benchmarkSeeds {
+		mg := mapgen.NewMapGenerator(61, 41, seed, 200)
+		mg.Symmetry = mapgen.SymmetryHorizontal
+		mg.SecretDoorChance = 0.5
+		mg.GenerateAll()
+
+		doors := mg.SecretDoors()
+		if len(doors) == 0 {
+			t.Errorf("seed %d: expected some secret doors", seed)
+		}
+
+		tr := mg.Terrain()
+		for _, door := range doors {
+			if mx := 60 - door[0]; tr.Get(mx, door[1]) != terrain.SecretDoor {
+				t.Errorf("seed %d: expected the secret door at %d,%d to have a mirror at %d,%d", seed, door[0], door[1], mx, door[1])
+			}
+		}
+
+		for i := 1; i < len(doors); i++ {
+			if doors[i][1] < doors[i-1][1] || (doors[i][1] == doors[i-1][1] && doors[i][0] < doors[i-1][0]) {
+				t.Errorf("seed %d: expected the secret doors row by row, got %v before %v", seed, doors[i-1], doors[i])
+			}
+		}
+	}
+}
+
+func TestRoomAttempts(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// the automatic number of attempts grows with the size of the map
+	small := mapgen.NewMapGenerator(41, 31, 1, mapgen.AutoRoomAttempts)
+	small.GenerateAll()
+	large := mapgen.NewMapGenerator(119, 67, 1, mapgen.AutoRoomAttempts)
+	large.GenerateAll()
+
+	smallStats, largeStats := small.Stats(), large.Stats()
+	if smallStats.MaxRoomAttempts <= 0 || largeStats.MaxRoomAttempts <= smallStats.MaxRoomAttempts {
+		t.Errorf("expected more attempts for a larger map, got %d and %d",
+			smallStats.MaxRoomAttempts, largeStats.MaxRoomAttempts)
+	}
+	if smallStats.Rooms == 0 || largeStats.Rooms <= smallStats.Rooms {
+		t.Errorf("expected more rooms on a larger map, got %d and %d", smallStats.Rooms, largeStats.Rooms)
+	}
+
+	// far too many attempts for a tiny map stops once it is full
+	mg := mapgen.NewMapGenerator(21, 21, 1, 1000000)
+	mg.MaxFailedRoomAttempts = 50
+	mg.GenerateAll()
+
+	stats := mg.Stats()
+	if stats.RoomAttempts >= 1000000 {
+		t.Errorf("expected generation to stop early, but it made %d attempts", stats.RoomAttempts)
+	}
+	if stats.Rooms == 0 {
+		t.Error("expected some rooms to be placed")
+	}
+}
+
+func TestRoomPlacementBias(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// spread is the average distance of the room tiles from the center of
+	// the map, over a few seeds. Only a few rooms are placed, so that the map
+	// doesn't fill up and hide where they were aimed.
+	spread := func(bias mapgen.RoomPlacementBias) float64 {
+		total, count := 0.0, 0
+		for _, seed := range benchmarkSeeds {
+			mg := mapgen.NewMapGenerator(81, 81, seed, 15)
+			mg.RoomPlacementBias = bias
+			mg.GenerateAll()
+
+			tr := mg.Terrain()
+			for y := 0; y < tr.Height; y++ {
+				for x := 0; x < tr.Width; x++ {
+					if tr.Get(x, y) == terrain.Room {
+						total += math.Hypot(float64(x-tr.Width/2), float64(y-tr.Height/2))
+						count++
+					}
+				}
+			}
+		}
+		return total / float64(count)
+	}
+
+	uniform := spread(mapgen.RoomPlacementUniform)
+	center := spread(mapgen.RoomPlacementCenterWeighted)
+	edge := spread(mapgen.RoomPlacementEdgeWeighted)
+
+	if !(center < uniform && uniform < edge) {
+		t.Errorf("expected center weighted rooms to be closest to the center and edge weighted furthest, got center %.1f, uniform %.1f, edge %.1f", center, uniform, edge)
+	}
+}
+
+// BenchmarkGenerate runs a full map generation for a few realistic map sizes.
+// The per-phase timings are reported as extra metrics so that it's easy to see
+// which phase dominates as the map gets bigger.
+func BenchmarkGenerate(b *testing.B) {
+	// the generator logs a few lines at Info level, which would drown out the
+	// benchmark output.
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	sizes := [][2]int{
+		{1920/16 - 1, 1080 / 16},
+		{255, 255},
+	}
+
+	for _, size := range sizes {
+		b.Run(fmt.Sprintf("%dx%d", size[0], size[1]), func(b *testing.B) {
+			phases := make(map[mapgen.GenerationPhase]float64)
+
+			for i := 0; i < b.N; i++ {
+				seed := benchmarkSeeds[i%len(benchmarkSeeds)]
+				mg := mapgen.NewMapGenerator(size[0], size[1], seed, 1000)
+				mg.Timing = true
+				mg.GenerateAll()
+
+				for phase, duration := range mg.Stats().PhaseDurations {
+					phases[phase] += float64(duration.Nanoseconds())
+				}
+			}
+
+			for phase, total := range phases {
+				b.ReportMetric(total/float64(b.N), "ns/"+phase.String())
+			}
+		})
+	}
+}
+
+// BenchmarkConnectRegions reports only the time spent connecting regions, for
+// increasingly large maps. Merging regions is near-constant time, so this
+// should grow roughly in line with the number of connectors rather than with
+// regions * area.
+func BenchmarkConnectRegions(b *testing.B) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for _, size := range []int{63, 127, 255, 511} {
+		b.Run(fmt.Sprintf("%dx%d", size, size), func(b *testing.B) {
+			var total time.Duration
+
+			for i := 0; i < b.N; i++ {
+				seed := benchmarkSeeds[i%len(benchmarkSeeds)]
+				mg := mapgen.NewMapGenerator(size, size, seed, size*4)
+				mg.Timing = true
+				mg.GenerateAll()
+
+				total += mg.Stats().PhaseDurations[mapgen.PhaseConnectingRegions]
+			}
+
+			b.ReportMetric(float64(total.Nanoseconds())/float64(b.N), "ns/connect")
+		})
+	}
+}
+
+// BenchmarkRemoveDeadEnds reports only the time spent removing dead ends,
+// which scans the whole map once for every pass.
+func BenchmarkRemoveDeadEnds(b *testing.B) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for _, size := range []int{63, 127, 255} {
+		b.Run(fmt.Sprintf("%dx%d", size, size), func(b *testing.B) {
+			var total time.Duration
+
+			for i := 0; i < b.N; i++ {
+				seed := benchmarkSeeds[i%len(benchmarkSeeds)]
+				mg := mapgen.NewMapGenerator(size, size, seed, size*4)
+				mg.Timing = true
+				mg.GenerateAll()
+
+				total += mg.Stats().PhaseDurations[mapgen.PhaseRemoveDeadEnds]
+			}
+
+			b.ReportMetric(float64(total.Nanoseconds())/float64(b.N), "ns/dead_ends")
+		})
+	}
+}
+
+// TestGolden checks that a fixed seed still makes exactly the same map. Shared
+// seeds and recorded games both rely on this, so if it fails, either the
+// change wasn't meant to affect the maps and something is drawing from the rng
+// in a different order, or it was and the golden file needs updating.
+func TestGolden(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	generate := func() []byte {
+		mg := mapgen.NewMapGenerator(61, 41, 42, 200)
+		mg.GenerateAll()
+
+		data, err := mg.Terrain().MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	data := generate()
+	if !bytes.Equal(data, generate()) {
+		t.Fatal("expected the same seed to make the same map twice")
+	}
+
+	path := filepath.Join("testdata", "seed42.golden")
+	if *update {
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	golden, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(data, golden) {
+		t.Errorf("the map for seed 42 doesn't match %s", path)
+	}
+}
@@ -0,0 +1,104 @@
+package mapgen
+
+import (
+	"github.com/matjam/sword/internal/grid"
+	"github.com/matjam/sword/internal/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Pocket rooms
+
+// DefaultMaxPocketRoomSize is the MaxPocketRoomSize used if it isn't set.
+const DefaultMaxPocketRoomSize = 5
+
+func (mg *MapGenerator) convertPockets() {
+	// The convertPockets() method runs once the mazes are done. Most of the
+	// map ends up as one big maze, but when the maze generator finds a little
+	// gap left between rooms, it fills it with a tiny maze of its own that
+	// can't reach anything else. Those are usually just dead ends waiting to
+	// be removed, but some of them make a nice little chamber, so we give
+	// each one a PocketRoomChance of being turned into a room instead.
+	//
+	// We find the mazes by flood filling the corridor tiles, so each flood
+	// is one maze, and the rectangle around it is the room it would become.
+
+	if mg.PocketRoomChance <= 0 {
+		return
+	}
+
+	maxSize := mg.MaxPocketRoomSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxPocketRoomSize
+	}
+
+	isCorridor := func(t terrain.Type) bool { return t == terrain.Corridor }
+	seen := grid.NewGrid[bool](mg.Width, mg.Height)
+
+	for y := 0; y < mg.Height; y++ {
+		for x := 0; x < mg.Width; x++ {
+			if seen.Get(x, y) || !isCorridor(mg.terrainGrid.Get(x, y)) {
+				continue
+			}
+
+			minX, minY, maxX, maxY := x, y, x, y
+			mg.terrainGrid.FloodFill(x, y, isCorridor, func(x, y int) {
+				seen.Set(x, y, true)
+				minX, minY = min(minX, x), min(minY, y)
+				maxX, maxY = max(maxX, x), max(maxY, y)
+			})
+
+			room := Room{
+				X:      minX,
+				Y:      minY,
+				Width:  maxX - minX + 1,
+				Height: maxY - minY + 1,
+				Region: mg.regionGrid.Get(x, y),
+			}
+
+			if !mg.isPocket(room, maxSize) {
+				continue
+			}
+
+			if mg.rng.Float64() < mg.PocketRoomChance {
+				mg.addRoom(room)
+			}
+		}
+	}
+}
+
+func (mg *MapGenerator) isPocket(room Room, maxSize int) bool {
+	// a pocket has to make a room that follows the same rules as any other
+	// room. The maze is carved on odd coordinates, so the rectangle around it
+	// always starts on an odd tile and has an odd size, but it needs to be at
+	// least 3x3 to be a room rather than a short corridor.
+	if room.Width < minRoomSize || room.Height < minRoomSize ||
+		room.Width > maxSize || room.Height > maxSize {
+		return false
+	}
+
+	// everything inside the rectangle has to be either part of this maze or
+	// the stone between its corridors. Anything else means the maze wraps
+	// around something it would swallow up.
+	for y := room.Y; y < room.Y+room.Height; y++ {
+		for x := room.X; x < room.X+room.Width; x++ {
+			if mg.protectedGrid.Get(x, y) {
+				return false
+			}
+
+			switch mg.terrainGrid.Get(x, y) {
+			case terrain.Corridor:
+				if mg.regionGrid.Get(x, y) != room.Region {
+					return false
+				}
+			case terrain.Stone:
+				if mg.regionGrid.Get(x, y) != nil {
+					return false
+				}
+			default:
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,120 @@
+package mapgen
+
+import (
+	"log/slog"
+
+	"github.com/matjam/sword/internal/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Prefabs
+
+// prefabAttempts is the number of random positions we try for each prefab
+// before giving up on it.
+const prefabAttempts = 100
+
+// Prefab is a handcrafted set-piece, such as a throne room or a treasure vault,
+// that is stamped into the map as-is during room generation.
+//
+// Like rooms, prefabs should have an odd width and height so that they line
+// up with the mazes carved around them, and should be open along their edges
+// so that the connector phase can find somewhere to put doors into them. Stone
+// tiles inside the prefab are left as walls, and are never carved into by the
+// maze generator. Every non-stone tile in the prefab becomes part of a single
+// region, so the open parts of a prefab should be connected to each other.
+type Prefab struct {
+	Name    string
+	Terrain *terrain.Terrain
+
+	// AnchorX and AnchorY mark a point of interest inside the prefab, relative
+	// to its top left corner, such as where the throne or the treasure goes.
+	AnchorX int
+	AnchorY int
+
+	// Metadata is free-form data for whoever populates the prefab.
+	Metadata map[string]string
+}
+
+// PlacedPrefab is a prefab that has been stamped into the map.
+type PlacedPrefab struct {
+	Prefab *Prefab
+	Room   *Room
+}
+
+// Anchor returns the map location of the prefab's anchor.
+func (p *PlacedPrefab) Anchor() (x, y int) {
+	return p.Room.X + p.Prefab.AnchorX, p.Room.Y + p.Prefab.AnchorY
+}
+
+// PlacedPrefabs returns the prefabs that were successfully placed in the map.
+func (mg *MapGenerator) PlacedPrefabs() []*PlacedPrefab {
+	return mg.placedPrefabs
+}
+
+func (mg *MapGenerator) placePrefabs() {
+	// The placePrefabs() method is where we place the prefabs, before any of the
+	// random rooms. We treat each prefab as a room the size of the prefab and
+	// try random positions until it fits, exactly like generateRooms() does.
+
+	mg.prefabsPlaced = true
+
+	for _, prefab := range mg.Prefabs {
+		width := prefab.Terrain.Width
+		height := prefab.Terrain.Height
+
+		if width%2 == 0 || height%2 == 0 {
+			slog.Warn("prefab must have an odd width and height", "prefab", prefab.Name, "width", width, "height", height)
+			continue
+		}
+
+		placed := false
+		for attempt := 0; attempt < prefabAttempts && !placed; attempt++ {
+			minX, minY, _, _ := mg.bounds()
+			room := Room{
+				X:      minX + mg.roomSlot(mg.Width/2)*2,
+				Y:      minY + mg.roomSlot(mg.Height/2)*2,
+				Width:  width,
+				Height: height,
+				Prefab: prefab,
+			}
+
+			if mg.roomFits(room) {
+				mg.addPrefab(room)
+				placed = true
+			}
+		}
+
+		if !placed {
+			slog.Warn("could not find a place for prefab", "prefab", prefab.Name)
+		}
+	}
+}
+
+func (mg *MapGenerator) addPrefab(room Room) {
+	// The addPrefab() method stamps the prefab's terrain into the map, and gives
+	// all of its open tiles a region of their own so they get connected to the
+	// rest of the map.
+	room.Region = mg.nextRegion()
+	prefab := room.Prefab
+
+	mg.terrainGrid.BlitFunc(prefab.Terrain.Grid, room.X, room.Y, func(t terrain.Type) bool {
+		return t != terrain.Stone
+	})
+
+	for y := 0; y < prefab.Terrain.Height; y++ {
+		for x := 0; x < prefab.Terrain.Width; x++ {
+			if prefab.Terrain.Get(x, y) != terrain.Stone {
+				mg.regionGrid.Set(room.X+x, room.Y+y, room.Region)
+			}
+		}
+	}
+
+	// stop the maze generator from carving through the prefab's walls
+	mg.protectedGrid.SetRect(room.X, room.Y, room.Width, room.Height, true)
+
+	mg.roomList = append(mg.roomList, &room)
+	mg.placedPrefabs = append(mg.placedPrefabs, &PlacedPrefab{
+		Prefab: prefab,
+		Room:   &room,
+	})
+}
@@ -0,0 +1,118 @@
+package mapgen
+
+import (
+	"image/color"
+	"slices"
+
+	"github.com/matjam/sword/internal/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Regions
+
+// RegionInfo describes one of the regions of a finished map: a part of the
+// map that can be walked around without going through stone. Once the
+// connector phase is done, every region it joined together has been merged
+// into one, so a fully connected map has a single region.
+type RegionInfo struct {
+	ID RegionID
+
+	// Color is the color the region is drawn in by DrawDebug.
+	Color color.Color
+
+	// Rooms is the number of rooms in the region, including prefabs, and
+	// Tiles is the number of tiles in it that aren't stone.
+	Rooms int
+	Tiles int
+}
+
+// Regions returns the regions of the finished map, sorted by ID. Like
+// SpawnableTiles, it is only worked out once generation is done, and returns
+// nil before that.
+func (mg *MapGenerator) Regions() []RegionInfo {
+	if mg.Phase != PhaseDone {
+		return nil
+	}
+
+	regions := make([]RegionInfo, 0, len(mg.regionInfos()))
+	for _, info := range mg.regionInfos() {
+		regions = append(regions, *info)
+	}
+	slices.SortFunc(regions, func(a, b RegionInfo) int {
+		return int(a.ID - b.ID)
+	})
+
+	return regions
+}
+
+// RegionAt returns the region the given tile is in, once generation is done.
+// It returns false for stone, tiles outside the map, and if the map isn't
+// finished yet.
+func (mg *MapGenerator) RegionAt(x, y int) (RegionInfo, bool) {
+	if mg.Phase != PhaseDone || mg.terrainGrid.Get(x, y) == terrain.Stone {
+		return RegionInfo{}, false
+	}
+
+	region := mg.regionGrid.Get(x, y)
+	if region == nil {
+		return RegionInfo{}, false
+	}
+
+	info, ok := mg.regionInfos()[region.find().id]
+	if !ok {
+		return RegionInfo{}, false
+	}
+
+	return *info, true
+}
+
+// RoomsIn returns the rooms that are in the given region, in the order they
+// were placed. Rooms are in the region they have ended up in after all of the
+// merging so far, so once the map is done every room in a fully connected map
+// is in the same region.
+func (mg *MapGenerator) RoomsIn(id RegionID) []*Room {
+	rooms := make([]*Room, 0)
+	for _, room := range mg.roomList {
+		if room.Region != nil && room.Region.find().id == id {
+			rooms = append(rooms, room)
+		}
+	}
+	return rooms
+}
+
+// regionInfos counts the rooms and tiles in every region the first time it
+// is called, going by the regions the tiles and rooms have ended up in after
+// all of the merging. Regions that no tiles are left in, such as corridors
+// that were removed as dead ends, aren't included.
+func (mg *MapGenerator) regionInfos() map[RegionID]*RegionInfo {
+	if mg.regionInfo != nil {
+		return mg.regionInfo
+	}
+
+	mg.regionInfo = make(map[RegionID]*RegionInfo)
+
+	for y := 0; y < mg.Height; y++ {
+		for x := 0; x < mg.Width; x++ {
+			region := mg.regionGrid.Get(x, y)
+			if region == nil || mg.terrainGrid.Get(x, y) == terrain.Stone {
+				continue
+			}
+
+			root := region.find()
+			info, ok := mg.regionInfo[root.id]
+			if !ok {
+				info = &RegionInfo{ID: root.id, Color: root.clr}
+				mg.regionInfo[root.id] = info
+			}
+			info.Tiles++
+		}
+	}
+
+	for _, room := range mg.roomList {
+		if info, ok := mg.regionInfo[room.Region.find().id]; ok {
+			info.Rooms++
+		}
+	}
+
+	return mg.regionInfo
+}
@@ -0,0 +1,259 @@
+package mapgen
+
+import (
+	"slices"
+
+	"github.com/matjam/sword/internal/grid"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Room graph
+
+// RoomLink is a way from one room to a neighbouring room that doesn't go
+// through any other room on the way.
+type RoomLink struct {
+	// Room is the neighbouring room.
+	Room *Room
+
+	// Door is the tile just outside the room that the way leads out of, and
+	// NeighborDoor is the tile just outside Room that it leads into. They are
+	// usually doors, but where a tunnel runs straight into a room they are the
+	// ends of the tunnel. When the two rooms share a door, they are the same
+	// tile.
+	Door         [2]int
+	NeighborDoor [2]int
+
+	// Length is the number of steps from Door to NeighborDoor.
+	Length int
+}
+
+// RoomGraph is how the rooms of a finished map are joined together. Two rooms
+// are neighbours if there is a way from one to the other through doors and
+// corridors that doesn't go through any other room, so the number of hops
+// between two rooms is how many rooms have to be crossed to get from one to
+// the other. Since the mazes on a map join up almost every room, most rooms
+// on a maze map are neighbours; the hops mean more on maps with direct
+// tunnels.
+type RoomGraph struct {
+	// Rooms is every room in the map, including prefabs, in the order they
+	// were placed.
+	Rooms []*Room
+
+	links map[*Room][]RoomLink
+}
+
+// RoomGraph returns the graph of how the rooms of the finished map are joined
+// together. Like Regions, it is only worked out once generation is done, and
+// returns nil before that.
+//
+// The graph is worked out from the finished terrain rather than from the
+// connectors that were opened, so it takes into account the loops, the dead
+// ends that were removed, and maps made with direct tunnels.
+func (mg *MapGenerator) RoomGraph() *RoomGraph {
+	if mg.Phase != PhaseDone {
+		return nil
+	}
+
+	if mg.roomGraph != nil {
+		return mg.roomGraph
+	}
+
+	rooms := grid.NewGrid[*Room](mg.Width, mg.Height)
+	for _, room := range mg.roomList {
+		rooms.SetRect(room.X, room.Y, room.Width, room.Height, room)
+	}
+
+	mg.roomGraph = &RoomGraph{
+		Rooms: slices.Clone(mg.roomList),
+		links: make(map[*Room][]RoomLink, len(mg.roomList)),
+	}
+	for _, room := range mg.roomList {
+		mg.roomGraph.links[room] = mg.roomLinks(room, rooms)
+	}
+
+	return mg.roomGraph
+}
+
+// roomLinks finds the rooms that can be reached from the given room without
+// going through another one. It searches outwards from every door of the room
+// at once, and each tile remembers which door it was reached from, so the
+// link to each neighbour goes out of the door that is nearest to it. The
+// links are in order of how far away the neighbours are.
+func (mg *MapGenerator) roomLinks(room *Room, rooms *grid.Grid[*Room]) []RoomLink {
+	type reached struct {
+		door  [2]int
+		steps int
+	}
+
+	links := make([]RoomLink, 0)
+	linked := make(map[*Room]bool)
+
+	// reachedFrom is the door each tile was reached from, and how many steps
+	// from the door it is
+	reachedFrom := make(map[[2]int]reached)
+	queue := make([][2]int, 0)
+
+	passable := func(x, y int) bool {
+		return mg.terrainGrid.Get(x, y).IsPassable()
+	}
+
+	// the doors are the open tiles just outside the room's walls
+	for _, p := range room.Perimeter() {
+		for _, d := range [][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+			door := [2]int{p[0] + d[0], p[1] + d[1]}
+			if room.Contains(door[0], door[1]) || !passable(door[0], door[1]) {
+				continue
+			}
+			if _, ok := reachedFrom[door]; ok {
+				continue
+			}
+
+			reachedFrom[door] = reached{door: door}
+			queue = append(queue, door)
+		}
+	}
+
+	for len(queue) > 0 {
+		tile := queue[0]
+		queue = queue[1:]
+
+		for _, d := range [][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+			x, y := tile[0]+d[0], tile[1]+d[1]
+			if room.Contains(x, y) || !passable(x, y) {
+				continue
+			}
+
+			// we stop at other rooms rather than going through them
+			if other := rooms.Get(x, y); other != nil {
+				if !linked[other] {
+					linked[other] = true
+					links = append(links, RoomLink{
+						Room:         other,
+						Door:         reachedFrom[tile].door,
+						NeighborDoor: tile,
+						Length:       reachedFrom[tile].steps,
+					})
+				}
+				continue
+			}
+
+			next := [2]int{x, y}
+			if _, ok := reachedFrom[next]; ok {
+				continue
+			}
+
+			reachedFrom[next] = reached{door: reachedFrom[tile].door, steps: reachedFrom[tile].steps + 1}
+			queue = append(queue, next)
+		}
+	}
+
+	return links
+}
+
+// Neighbors returns the ways out of the given room to each of its neighbours,
+// nearest first.
+func (g *RoomGraph) Neighbors(room *Room) []RoomLink {
+	return g.links[room]
+}
+
+// Distances returns the number of hops from the given room to every room that
+// can be reached from it, including itself at zero hops. Rooms that can't be
+// reached aren't included.
+func (g *RoomGraph) Distances(from *Room) map[*Room]int {
+	distances := make(map[*Room]int)
+	if _, ok := g.links[from]; !ok {
+		return distances
+	}
+
+	distances[from] = 0
+	queue := []*Room{from}
+	for len(queue) > 0 {
+		room := queue[0]
+		queue = queue[1:]
+
+		for _, link := range g.links[room] {
+			if _, ok := distances[link.Room]; ok {
+				continue
+			}
+			distances[link.Room] = distances[room] + 1
+			queue = append(queue, link.Room)
+		}
+	}
+
+	return distances
+}
+
+// Distance returns the number of hops between two rooms, or -1 if there is no
+// way from one to the other.
+func (g *RoomGraph) Distance(from, to *Room) int {
+	if distance, ok := g.Distances(from)[to]; ok {
+		return distance
+	}
+	return -1
+}
+
+// Farthest returns the room that is the most hops away from the given room,
+// and how many hops away it is, such as for putting the exit as far from the
+// entrance as it can be. If several rooms are as far away as each other, the
+// one placed first is returned. It returns nil and -1 if the room isn't in
+// the graph.
+func (g *RoomGraph) Farthest(from *Room) (*Room, int) {
+	distances := g.Distances(from)
+
+	var farthest *Room
+	most := -1
+	for _, room := range g.Rooms {
+		if distance, ok := distances[room]; ok && distance > most {
+			farthest, most = room, distance
+		}
+	}
+
+	return farthest, most
+}
+
+// RoomClusters groups the rooms of the finished map into complexes of rooms
+// that open straight into each other through a shared door, without any
+// corridor in between. Rooms that are only linked to others through
+// corridors are in a cluster of their own. Every room is in exactly one
+// cluster; the rooms in each are in the order they were placed, and the
+// clusters are in order of their first room. Like RoomGraph, it returns nil
+// until the map is done.
+func (mg *MapGenerator) RoomClusters() [][]*Room {
+	graph := mg.RoomGraph()
+	if graph == nil {
+		return nil
+	}
+
+	// index is where each room is in the list of rooms, for sorting
+	index := make(map[*Room]int, len(graph.Rooms))
+	for i, room := range graph.Rooms {
+		index[room] = i
+	}
+
+	clusters := make([][]*Room, 0)
+	clustered := make(map[*Room]bool, len(graph.Rooms))
+	for _, room := range graph.Rooms {
+		if clustered[room] {
+			continue
+		}
+
+		clustered[room] = true
+		cluster := []*Room{room}
+		for i := 0; i < len(cluster); i++ {
+			for _, link := range graph.Neighbors(cluster[i]) {
+				if link.Door == link.NeighborDoor && !clustered[link.Room] {
+					clustered[link.Room] = true
+					cluster = append(cluster, link.Room)
+				}
+			}
+		}
+
+		slices.SortFunc(cluster, func(a, b *Room) int {
+			return index[a] - index[b]
+		})
+
+		clusters = append(clusters, cluster)
+	}
+
+	return clusters
+}
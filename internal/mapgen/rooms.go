@@ -1,10 +1,30 @@
 package mapgen
 
-import "github.com/matjam/sword/internal/terrain"
+import (
+	"log/slog"
+
+	"github.com/matjam/sword/internal/shape"
+	"github.com/matjam/sword/internal/terrain"
+)
 
 ////////////////////////////////////////////////////////////////////////////////
 // Room generation
 
+// RoomPlacementBias controls where on the map the random rooms tend to be
+// placed.
+type RoomPlacementBias int
+
+const (
+	// RoomPlacementUniform spreads the rooms evenly over the whole map.
+	RoomPlacementUniform RoomPlacementBias = iota
+	// RoomPlacementCenterWeighted packs the rooms into the middle of the
+	// map, leaving the outskirts sparse.
+	RoomPlacementCenterWeighted
+	// RoomPlacementEdgeWeighted pushes the rooms out towards the edges of
+	// the map, leaving the middle sparse.
+	RoomPlacementEdgeWeighted
+)
+
 func (mg *MapGenerator) generateRooms() {
 	// The generateRooms() method is where we generate the rooms. We do this by
 	// picking a random room size and position, and checking if it fits. If it
@@ -12,13 +32,48 @@ func (mg *MapGenerator) generateRooms() {
 	// a different random room size and position. We keep doing this until we
 	// can't fit any more rooms into the map.
 
+	if !mg.prefabsPlaced {
+		// make sure there's room for at least the smallest room inside the
+		// border, or we'd never manage to place one.
+		minX, minY, maxX, maxY := mg.bounds()
+		if maxX-minX+1 < minRoomSize || maxY-minY+1 < minRoomSize {
+			slog.Error("map is too small for its border",
+				"width", mg.Width, "height", mg.Height, "border", mg.BorderThickness)
+			mg.Phase = PhaseDone
+			return
+		}
+
+		if mg.MinRoomSpacing < 0 {
+			slog.Error("MinRoomSpacing can't be negative, using the default",
+				"spacing", mg.MinRoomSpacing, "default", DefaultMinRoomSpacing)
+			mg.MinRoomSpacing = 0
+		}
+		if spacing := mg.roomSpacing(); spacing+2*minRoomSize > max(maxX-minX+1, maxY-minY+1) {
+			slog.Warn("MinRoomSpacing is too big for more than one room to fit",
+				"spacing", spacing, "width", mg.Width, "height", mg.Height)
+		}
+
+		if mg.maxRoomAttempts <= 0 {
+			mg.maxRoomAttempts = mg.autoRoomAttempts()
+		}
+
+		mg.placePrefabs()
+	}
+
 	successfullyPlacedRoom := false
 
+	maxFailed := mg.MaxFailedRoomAttempts
+	if maxFailed <= 0 {
+		maxFailed = DefaultMaxFailedRoomAttempts
+	}
+
 	if mg.curRoomAttempts < mg.maxRoomAttempts {
 
 		mg.currentRegion = mg.nextRegion()
 
-		for !successfullyPlacedRoom {
+		// We keep trying until a room fits, even if that takes us past
+		// maxRoomAttempts, but if too many fail in a row the map is full.
+		for !successfullyPlacedRoom && mg.failedRoomAttempts < maxFailed {
 			var room Room
 
 			// We generate a random room size from the list of possible room sizes.
@@ -26,11 +81,12 @@ func (mg *MapGenerator) generateRooms() {
 			roomWidth := roomSize[0]
 			roomHeight := roomSize[1]
 
-			// We generate a random room position between 0 and the map width/height,
-			// with an odd x and y coordinate so that rooms won't end up touching each
-			// other.
-			roomX := 1 + mg.rng.Intn(mg.Width/2)*2
-			roomY := 1 + mg.rng.Intn(mg.Height/2)*2
+			// We generate a random room position between the border and the map
+			// width/height, with an odd x and y coordinate so that rooms won't end up
+			// touching each other.
+			minX, minY, _, _ := mg.bounds()
+			roomX := minX + mg.roomSlot(mg.Width/2)*2
+			roomY := minY + mg.roomSlot(mg.Height/2)*2
 
 			//
 
@@ -48,10 +104,24 @@ func (mg *MapGenerator) generateRooms() {
 				mg.addRoom(room)
 
 				successfullyPlacedRoom = true
+				mg.failedRoomAttempts = 0
+			} else {
+				mg.failedRoomAttempts++
 			}
 
 			mg.curRoomAttempts++
 		}
+
+		// the region was never used, so it mustn't be left around for the
+		// connector phase to try to connect.
+		if !successfullyPlacedRoom {
+			delete(mg.regions, mg.currentRegion.id)
+		}
+	}
+
+	if mg.failedRoomAttempts >= maxFailed {
+		slog.Debug("map is full of rooms", "rooms", len(mg.roomList), "attempts", mg.curRoomAttempts)
+		mg.Phase = PhaseMazes
 	}
 
 	if mg.curRoomAttempts >= mg.maxRoomAttempts {
@@ -59,25 +129,160 @@ func (mg *MapGenerator) generateRooms() {
 	}
 }
 
+// autoRoomAttemptsPerRoom is how many attempts autoRoomAttempts() allows for
+// every room that could fit in the map. Most attempts fail once the map starts
+// filling up, so it takes several for each room that is placed.
+const autoRoomAttemptsPerRoom = 10
+
+// autoRoomAttempts works out how many attempts to make at placing random
+// rooms, from how many average sized rooms, with a wall around them, would
+// fit in the area rooms can go in. It comes to roughly 1000 for a map the
+// size of the screen.
+func (mg *MapGenerator) autoRoomAttempts() int {
+	minX, minY, maxX, maxY := mg.bounds()
+	area := (maxX - minX + 1) * (maxY - minY + 1)
+	if mg.Symmetry != SymmetryNone {
+		// rooms only go in the half of the map that is mirrored
+		area /= 2
+	}
+
+	roomArea := 0
+	for _, size := range roomSizes {
+		roomArea += (size[0] + 1) * (size[1] + 1)
+	}
+	roomArea /= len(roomSizes)
+
+	return max(area*autoRoomAttemptsPerRoom/roomArea, 1)
+}
+
+// PlaceRoom adds a room at a fixed location, before any of the random rooms
+// are placed, so that tests and designers can pin rooms where they want them.
+// The room has to follow the same rules as the random rooms: its position and
+// size must be odd, it must be at least 3x3, and it must fit inside the border
+// without overlapping another room. The room gets a region of its own and is
+// connected to the rest of the map like any other room.
+//
+// PlaceRoom returns whether the room was placed. It can only be called before
+// the rooms phase has finished; after that it logs an error and returns false.
+func (mg *MapGenerator) PlaceRoom(x, y, w, h int) bool {
+	if mg.Phase != PhaseRooms {
+		slog.Error("rooms can't be placed after the rooms phase",
+			"phase", mg.Phase, "x", x, "y", y, "width", w, "height", h)
+		return false
+	}
+
+	if x%2 == 0 || y%2 == 0 || w%2 == 0 || h%2 == 0 || w < minRoomSize || h < minRoomSize {
+		return false
+	}
+
+	room := Room{
+		X:      x,
+		Y:      y,
+		Width:  w,
+		Height: h,
+	}
+
+	if !mg.roomFits(room) {
+		return false
+	}
+
+	room.Region = mg.nextRegion()
+	mg.addRoom(room)
+
+	return true
+}
+
+// roomSlot picks a random number from 0 to n-1, to be turned into an odd
+// coordinate for a room, spread according to RoomPlacementBias.
+func (mg *MapGenerator) roomSlot(n int) int {
+	switch mg.RoomPlacementBias {
+	case RoomPlacementCenterWeighted:
+		// the average of two uniform picks is a triangular distribution,
+		// which peaks in the middle.
+		return (mg.rng.Intn(n) + mg.rng.Intn(n)) / 2
+	case RoomPlacementEdgeWeighted:
+		// shifting the triangular distribution along by half, and wrapping
+		// it around, moves the peak to the edges and the tails to the middle.
+		return ((mg.rng.Intn(n)+mg.rng.Intn(n))/2 + n/2) % n
+	}
+
+	return mg.rng.Intn(n)
+}
+
 func (mg *MapGenerator) roomFits(room Room) bool {
 	// The roomFits() method is where we check if a room fits in the map. We do
 	// this by checking if the room overlaps with any other rooms.
 
-	// We check if the room is inside the map.
-	if room.X < 1 || room.Y < 1 || room.X+room.Width > mg.Width-1 || room.Y+room.Height > mg.Height-1 {
+	// We check if the room is inside the map, and clear of the border.
+	if !mg.inBounds(room.X, room.Y) || !mg.inBounds(room.X+room.Width-1, room.Y+room.Height-1) {
 		return false
 	}
 
-	// We check if the room overlaps with any other rooms.
+	// On symmetric maps, rooms are only placed on the source side of the axis.
+	if !mg.inSource(room.X+room.Width-1, room.Y+room.Height-1) {
+		return false
+	}
+
+	// We check if the room overlaps with any other rooms, once it has been
+	// grown by the spacing on every side so that it also has to be far
+	// enough away from them.
+	spacing := mg.roomSpacing()
+	grown := Room{
+		X:      room.X - spacing,
+		Y:      room.Y - spacing,
+		Width:  room.Width + spacing*2,
+		Height: room.Height + spacing*2,
+	}
+
 	for _, r := range mg.roomList {
-		if room.Overlaps(r) {
+		if grown.Overlaps(r) {
+			return false
+		}
+	}
+
+	// On symmetric maps, the room and all of the rooms already placed will
+	// be copied across the axis, and the copies have to keep their distance
+	// too. The axis is always stone, so with the default spacing they can't
+	// get too close.
+	if mg.Symmetry != SymmetryNone && spacing > DefaultMinRoomSpacing {
+		mirrored := mg.mirroredRoom(&room)
+		if grown.Overlaps(&mirrored) {
 			return false
 		}
+
+		for _, r := range mg.roomList {
+			mirrored := mg.mirroredRoom(r)
+			if grown.Overlaps(&mirrored) {
+				return false
+			}
+		}
 	}
 
 	return true
 }
 
+// roomSpacing returns MinRoomSpacing, or DefaultMinRoomSpacing if it isn't
+// set.
+func (mg *MapGenerator) roomSpacing() int {
+	if mg.MinRoomSpacing <= 0 {
+		return DefaultMinRoomSpacing
+	}
+	return mg.MinRoomSpacing
+}
+
+// mirroredRoom returns the room that the given room will be copied to on a
+// symmetric map.
+func (mg *MapGenerator) mirroredRoom(room *Room) Room {
+	x1, y1 := mg.mirror(room.X, room.Y)
+	x2, y2 := mg.mirror(room.X+room.Width-1, room.Y+room.Height-1)
+	return Room{
+		X:      min(x1, x2),
+		Y:      min(y1, y2),
+		Width:  room.Width,
+		Height: room.Height,
+	}
+}
+
 func (r *Room) Overlaps(other *Room) bool {
 	// The overlaps() method is where we check if a room overlaps with another
 	// room. We do this by checking if the rooms overlap on the x axis and the
@@ -93,9 +298,35 @@ func (r *Room) Overlaps(other *Room) bool {
 	return xOverlap && yOverlap
 }
 
+// Contains returns true if the given location is inside the room.
+func (r *Room) Contains(x, y int) bool {
+	return x >= r.X && x < r.X+r.Width && y >= r.Y && y < r.Y+r.Height
+}
+
+// Perimeter returns the floor tiles around the edge of the room, the ones
+// right next to its walls, going clockwise from the top left corner. This is
+// where doors open into the room and where torches or guards can go. In a
+// room one tile wide every tile is on the perimeter.
+func (r *Room) Perimeter() [][2]int {
+	return shape.NewRect(r.X, r.Y, r.Width, r.Height).BorderPoints()
+}
+
+// RoomAt returns the room that contains the given location, or nil if the
+// location isn't inside a room.
+func (mg *MapGenerator) RoomAt(x, y int) *Room {
+	for _, room := range mg.roomList {
+		if room.Contains(x, y) {
+			return room
+		}
+	}
+
+	return nil
+}
+
 func (mg *MapGenerator) addRoom(room Room) {
 	// The addRoom() method is where we add a room to the map. We do this by
-	// setting the tiles in the room to the correct type.
+	// setting the tiles in the room to the correct type. SetRect clips to the
+	// map, so a room that pokes past the edge only changes the tiles inside it.
 	mg.terrainGrid.SetRect(room.X, room.Y, room.Width, room.Height, terrain.Room)
 
 	// We add the room to the list of rooms.
@@ -119,6 +350,10 @@ func (mg *MapGenerator) Print() {
 				print("  ")
 			case terrain.Door:
 				print("++")
+			case terrain.Rubble:
+				print("▒▒")
+			case terrain.Water:
+				print("~~")
 			}
 		}
 		println()
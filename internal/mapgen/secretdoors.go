@@ -0,0 +1,111 @@
+package mapgen
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/matjam/sword/internal/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Secret Doors
+
+// SecretDoors returns the position of every secret door, row by row from the
+// top left. They are terrain.SecretDoor tiles until they're found; see
+// terrain.Terrain.RevealSecretDoor.
+func (mg *MapGenerator) SecretDoors() [][2]int {
+	return mg.secretDoors
+}
+
+func (mg *MapGenerator) placeSecretDoors() {
+	// The placeSecretDoors() method turns some of the walls between two
+	// regions into secret doors. It runs once the map is finished, when
+	// every region is already joined to every other, so a secret door only
+	// ever makes a shortcut and the map can be finished without finding any
+	// of them.
+	//
+	// A wall can hold a secret door if it's one tile thick, with floor on
+	// two opposite sides that belong to different regions, and wall on the
+	// other two. Each secret door is wall for the walls beside it, so they
+	// never end up next to each other.
+	//
+	// Like traps, we only roll for walls in the source area and put the same
+	// door in the mirrored wall, so symmetric maps get symmetric secret
+	// doors. The doors and dead ends picked after mirroring can differ
+	// between the two halves, so a wall only gets a secret door if its
+	// mirror can hold one too.
+
+	if mg.SecretDoorChance <= 0 {
+		return
+	}
+
+	for y := 0; y < mg.Height; y++ {
+		for x := 0; x < mg.Width; x++ {
+			if !mg.inSource(x, y) || !mg.canHoldSecretDoor(x, y) {
+				continue
+			}
+
+			mx, my := mg.mirror(x, y)
+			if (mx != x || my != y) && !mg.canHoldSecretDoor(mx, my) {
+				continue
+			}
+
+			if mg.rng.Float64() >= mg.SecretDoorChance {
+				continue
+			}
+
+			mg.terrainGrid.Set(x, y, terrain.SecretDoor)
+			mg.secretDoors = append(mg.secretDoors, [2]int{x, y})
+
+			if mx != x || my != y {
+				mg.terrainGrid.Set(mx, my, terrain.SecretDoor)
+				mg.secretDoors = append(mg.secretDoors, [2]int{mx, my})
+			}
+		}
+	}
+
+	// the mirrored doors were added out of order
+	slices.SortFunc(mg.secretDoors, func(a, b [2]int) int {
+		if a[1] != b[1] {
+			return cmp.Compare(a[1], b[1])
+		}
+		return cmp.Compare(a[0], b[0])
+	})
+}
+
+// canHoldSecretDoor returns true if the tile is a wall between two different
+// regions that a secret door can be put in.
+func (mg *MapGenerator) canHoldSecretDoor(x, y int) bool {
+	if mg.terrainGrid.Get(x, y) != terrain.Stone || mg.protectedGrid.Get(x, y) {
+		return false
+	}
+
+	isStone := func(x, y int) bool {
+		return mg.terrainGrid.Get(x, y) == terrain.Stone
+	}
+
+	// the floor tiles either side of the wall, if there are any
+	var ax, ay, bx, by int
+	switch {
+	case isStone(x, y-1) && isStone(x, y+1):
+		ax, ay, bx, by = x-1, y, x+1, y
+	case isStone(x-1, y) && isStone(x+1, y):
+		ax, ay, bx, by = x, y-1, x, y+1
+	default:
+		return false
+	}
+
+	if !isFloor(mg.terrainGrid.Get(ax, ay)) || !isFloor(mg.terrainGrid.Get(bx, by)) {
+		return false
+	}
+
+	a, b := mg.regionGrid.Get(ax, ay), mg.regionGrid.Get(bx, by)
+	return a != nil && b != nil && a != b
+}
+
+// isFloor returns true if the terrain type is somewhere a secret door can
+// lead to. Doors are left out, so that a secret door is never right beside
+// an ordinary one.
+func isFloor(t terrain.Type) bool {
+	return t == terrain.Room || t == terrain.Corridor || t == terrain.Trap
+}
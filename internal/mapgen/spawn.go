@@ -0,0 +1,57 @@
+package mapgen
+
+import (
+	"github.com/matjam/sword/internal/grid"
+	"github.com/matjam/sword/internal/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Spawning
+
+// SpawnableTiles returns a mask of the tiles that it's safe to spawn things
+// on: every Room or Corridor tile that can be walked to from the root room,
+// which is where the connector phase started joining the map together. Doors
+// and features are never spawnable, but doors and passable features such as
+// water can still be walked through to reach the tiles beyond them.
+//
+// The mask is only computed once generation is done, and is cached after the
+// first call. It returns nil if the map isn't finished yet.
+func (mg *MapGenerator) SpawnableTiles() *grid.Grid[bool] {
+	if mg.Phase != PhaseDone {
+		return nil
+	}
+
+	if mg.spawnable != nil {
+		return mg.spawnable
+	}
+
+	mg.spawnable = grid.NewGrid[bool](mg.Width, mg.Height)
+
+	if mg.rootRoom == nil {
+		return mg.spawnable
+	}
+
+	// the walls of an ordinary room are never given features, so the first
+	// passable tile we find in the root room is joined to the whole map. For
+	// a prefab it's just the first open tile, which the prefab's own rules
+	// say is joined to all of the others.
+	room := mg.rootRoom
+	for y := room.Y; y < room.Y+room.Height; y++ {
+		for x := room.X; x < room.X+room.Width; x++ {
+			if !mg.terrainGrid.Get(x, y).IsPassable() {
+				continue
+			}
+
+			mg.terrainGrid.FloodFill(x, y, terrain.Type.IsPassable, func(x, y int) {
+				switch mg.terrainGrid.Get(x, y) {
+				case terrain.Room, terrain.Corridor:
+					mg.spawnable.Set(x, y, true)
+				}
+			})
+
+			return mg.spawnable
+		}
+	}
+
+	return mg.spawnable
+}
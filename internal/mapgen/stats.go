@@ -0,0 +1,136 @@
+package mapgen
+
+import (
+	"time"
+
+	"github.com/matjam/sword/internal/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Stats
+
+// Stats holds information about a generated map, and how long it took to
+// generate. The durations are only collected when the generator's Timing
+// field is set.
+type Stats struct {
+	Rooms           int
+	DeadEndsRemoved int
+
+	// Alcoves is the number of dead ends that were kept because of
+	// DeadEndKeepChance. See Alcoves().
+	Alcoves int
+
+	// SecretDoors is the number of secret doors placed because of
+	// SecretDoorChance. See SecretDoors().
+	SecretDoors int
+
+	// RoomAttempts is the number of attempts made at placing a random room,
+	// out of MaxRoomAttempts. It can be fewer if the map filled up early, or
+	// a few more, since the last room is tried until it fits or the map is
+	// full. Rooms includes prefabs and rooms placed with PlaceRoom, which
+	// don't count as attempts.
+	RoomAttempts    int
+	MaxRoomAttempts int
+
+	// CorridorTurns is the number of corridor tiles where the corridor
+	// changes direction, and LongestStraightCorridor is the length of the
+	// longest unbroken run of corridor tiles along a row or column. Together
+	// they show how twisty the corridors came out, so maps that are too
+	// maze-like or too grid-like can be spotted and rejected.
+	CorridorTurns           int
+	LongestStraightCorridor int
+
+	// Loops is the number of doors that were opened between two regions that
+	// were already connected, each of which makes one more loop in the map.
+	// It is at least MinLoops unless there was nowhere left to put a door.
+	Loops int
+
+	TotalDuration  time.Duration
+	PhaseDurations map[GenerationPhase]time.Duration
+}
+
+// Stats returns statistics about the map generated so far.
+func (mg *MapGenerator) Stats() Stats {
+	phaseDurations := make(map[GenerationPhase]time.Duration, len(mg.phaseDurations))
+	for phase, duration := range mg.phaseDurations {
+		phaseDurations[phase] = duration
+	}
+
+	turns, longest := mg.corridorShape()
+
+	return Stats{
+		Rooms:                   len(mg.roomList),
+		DeadEndsRemoved:         mg.deadEndsRemoved,
+		Alcoves:                 len(mg.alcoves),
+		SecretDoors:             len(mg.secretDoors),
+		RoomAttempts:            mg.curRoomAttempts,
+		MaxRoomAttempts:         mg.maxRoomAttempts,
+		CorridorTurns:           turns,
+		LongestStraightCorridor: longest,
+		Loops:                   mg.loops,
+		TotalDuration:           mg.totalDuration,
+		PhaseDurations:          phaseDurations,
+	}
+}
+
+// corridorShape scans the terrain for the CorridorTurns and
+// LongestStraightCorridor stats. Traps count as corridor, since they're
+// placed on corridor tiles, and doors count as somewhere a corridor can lead
+// to, so a corridor that bends to go through a door has turned.
+func (mg *MapGenerator) corridorShape() (turns int, longest int) {
+	tr := mg.terrainGrid
+	isCorridor := func(x, y int) bool {
+		t := tr.Get(x, y)
+		return t == terrain.Corridor || t == terrain.Trap
+	}
+	leadsTo := func(x, y int) bool {
+		return isCorridor(x, y) || tr.Get(x, y) == terrain.Door
+	}
+
+	for y := 0; y < tr.Height; y++ {
+		for x := 0; x < tr.Width; x++ {
+			if !isCorridor(x, y) {
+				continue
+			}
+
+			// a turn is a corridor tile that leads exactly one way
+			// horizontally and one way vertically. Anything else is a
+			// straight, a dead end or a junction.
+			horizontal, vertical := 0, 0
+			if leadsTo(x-1, y) {
+				horizontal++
+			}
+			if leadsTo(x+1, y) {
+				horizontal++
+			}
+			if leadsTo(x, y-1) {
+				vertical++
+			}
+			if leadsTo(x, y+1) {
+				vertical++
+			}
+			if horizontal == 1 && vertical == 1 {
+				turns++
+			}
+
+			// only count each run from its first tile, so that we don't
+			// walk along it again from every tile in it.
+			if !isCorridor(x-1, y) {
+				run := 0
+				for isCorridor(x+run, y) {
+					run++
+				}
+				longest = max(longest, run)
+			}
+			if !isCorridor(x, y-1) {
+				run := 0
+				for isCorridor(x, y+run) {
+					run++
+				}
+				longest = max(longest, run)
+			}
+		}
+	}
+
+	return turns, longest
+}
@@ -0,0 +1,175 @@
+package mapgen
+
+import "github.com/matjam/sword/internal/terrain"
+
+////////////////////////////////////////////////////////////////////////////////
+// Symmetry
+
+// Symmetry controls whether the generated map is symmetric.
+type Symmetry int
+
+const (
+	// SymmetryNone generates an ordinary map.
+	SymmetryNone Symmetry = iota
+	// SymmetryHorizontal mirrors the left half of the map onto the right half.
+	SymmetryHorizontal
+	// SymmetryVertical mirrors the top half of the map onto the bottom half.
+	SymmetryVertical
+	// SymmetryRotational rotates the top half of the map by 180 degrees onto
+	// the bottom half.
+	SymmetryRotational
+)
+
+// The way symmetric maps work is that the rooms and mazes are only generated
+// inside a source area covering one half of the map, and then copied across
+// the axis once the mazes are done. The connector and dead end phases then
+// run over the whole map as usual, except that whenever a door is opened, the
+// mirrored door is opened as well.
+//
+// The axis is always placed on an even row or column, so that the source and
+// the mirrored copy, which are both carved on odd coordinates, are separated
+// by a single column (or row) of stone. That's exactly where the connector
+// phase will look for places to join the two halves together. If the center
+// of the map is on an odd row or column, the axis is moved one tile up or
+// left, leaving a slightly thicker border on the other side of the map.
+
+// axis returns the column and row that the map is mirrored across.
+func (mg *MapGenerator) axis() (x, y int) {
+	x = (mg.Width - 1) / 2
+	y = (mg.Height - 1) / 2
+	return x - x%2, y - y%2
+}
+
+// inSource returns true if the given location is inside the area that rooms
+// and mazes are generated in. For ordinary maps this is the whole map.
+func (mg *MapGenerator) inSource(x, y int) bool {
+	axisX, axisY := mg.axis()
+
+	switch mg.Symmetry {
+	case SymmetryHorizontal:
+		return x < axisX
+	case SymmetryVertical:
+		return y < axisY
+	case SymmetryRotational:
+		// the source is as wide as the map, less the border on both sides,
+		// so that nothing gets rotated into the border on the other side.
+		minX, _, _, _ := mg.bounds()
+		return y < axisY && x <= axisX*2-minX
+	}
+
+	return true
+}
+
+// mirror returns the location that the given location is copied to.
+func (mg *MapGenerator) mirror(x, y int) (int, int) {
+	axisX, axisY := mg.axis()
+
+	switch mg.Symmetry {
+	case SymmetryHorizontal:
+		return axisX*2 - x, y
+	case SymmetryVertical:
+		return x, axisY*2 - y
+	case SymmetryRotational:
+		return axisX*2 - x, axisY*2 - y
+	}
+
+	return x, y
+}
+
+func (mg *MapGenerator) mirrorMap() {
+	// The mirrorMap() method is where we copy the source area across the axis.
+	// Every region in the source gets a new region for its copy, so that the
+	// two halves are only joined together by the connector phase.
+
+	mg.Phase = PhaseConnectors
+
+	if mg.Symmetry == SymmetryNone {
+		return
+	}
+
+	mirroredRegions := make(map[*Region]*Region)
+	mirroredRegion := func(r *Region) *Region {
+		if r == nil {
+			return nil
+		}
+		if _, ok := mirroredRegions[r]; !ok {
+			mirroredRegions[r] = mg.nextRegion()
+		}
+		return mirroredRegions[r]
+	}
+
+	for y := 0; y < mg.Height; y++ {
+		for x := 0; x < mg.Width; x++ {
+			if !mg.inSource(x, y) {
+				continue
+			}
+
+			t := mg.terrainGrid.Get(x, y)
+			if t == terrain.Stone && !mg.protectedGrid.Get(x, y) {
+				continue
+			}
+
+			mx, my := mg.mirror(x, y)
+			mg.terrainGrid.Set(mx, my, t)
+			mg.regionGrid.Set(mx, my, mirroredRegion(mg.regionGrid.Get(x, y)))
+			mg.protectedGrid.Set(mx, my, mg.protectedGrid.Get(x, y))
+		}
+	}
+
+	// every room gets a mirrored copy, so that anything that looks at the list
+	// of rooms sees both halves of the map.
+	for _, room := range mg.roomList {
+		x1, y1 := mg.mirror(room.X, room.Y)
+		x2, y2 := mg.mirror(room.X+room.Width-1, room.Y+room.Height-1)
+
+		mg.roomList = append(mg.roomList, &Room{
+			X:        min(x1, x2),
+			Y:        min(y1, y2),
+			Width:    room.Width,
+			Height:   room.Height,
+			Region:   mirroredRegion(room.Region),
+			Prefab:   room.Prefab,
+			mirrored: true,
+		})
+	}
+}
+
+func (mg *MapGenerator) openMirroredConnector(c *Connector) {
+	// The openMirroredConnector() method opens the door on the other side of the
+	// axis from the given connector. Connectors that lie on the axis are their
+	// own mirror, so we never end up with two doors on top of each other.
+
+	if mg.Symmetry == SymmetryNone {
+		return
+	}
+
+	mx, my := mg.mirror(c.x, c.y)
+	if mx == c.x && my == c.y {
+		return
+	}
+
+	m := mg.connectorGrid.Get(mx, my)
+	if m == nil || mg.terrainGrid.Get(mx, my) == terrain.Door || mg.connectorIsBesideDoor(m) {
+		return
+	}
+
+	mg.terrainGrid.Set(mx, my, terrain.Door)
+
+	// if both sides of the mirrored door are already connected, we've just
+	// made a loop.
+	if m.region1.find() == m.region2.find() {
+		mg.loops++
+	}
+
+	// the mirrored door might join two regions that haven't been connected to
+	// the root region yet, so make sure the root region stays the root of its
+	// set when merging.
+	region1 := m.region1.find()
+	region2 := m.region2.find()
+	if region2 == mg.rootRegion {
+		region1, region2 = region2, region1
+	}
+
+	mg.regionGrid.Set(mx, my, region1)
+	mg.mergeRegions(region2, region1)
+}
@@ -0,0 +1,166 @@
+package mapgen
+
+import (
+	"github.com/matjam/sword/internal/grid"
+	"github.com/matjam/sword/internal/rng"
+	"github.com/matjam/sword/internal/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Themes
+//
+// A theme changes how part of the map looks, so that one dungeon can have,
+// say, a crypt wing and a cave wing. The map is split into wings by growing
+// them outwards from a few rooms picked at random, one room for each theme,
+// through the doors and corridors, until they meet. A room always belongs
+// to a single wing, the one that reached it first, so the edge between two
+// wings runs along a corridor rather than through the middle of a room.
+
+// themeSeedSalt is mixed into the generator's seed for the themes, so that
+// the rooms the wings start from don't line up with anything else that was
+// picked at random.
+const themeSeedSalt = 0x7e3e5
+
+// AssignThemes splits the finished map into count wings, numbered from
+// terrain.DefaultTheme, and returns a grid with the theme of every tile. The
+// walls take the theme of the open tiles next to them, and so does any solid
+// rock on the edge of a wing; rock that isn't next to anything gets
+// terrain.DefaultTheme. The wings are picked from the seed the generator was
+// created with, so a seed always gets the same themes.
+//
+// Rooms given a theme with SetRoomTheme keep it, and the wings grow out from
+// them as well as from the rooms picked at random. It returns nil if the map
+// isn't finished yet. Calling it again works the themes out afresh, so call
+// it again after changing a room's theme.
+func (mg *MapGenerator) AssignThemes(count int) *grid.Grid[terrain.ThemeID] {
+	if mg.Phase != PhaseDone {
+		return nil
+	}
+
+	count = max(count, 1)
+
+	themes := grid.NewGrid[terrain.ThemeID](mg.Width, mg.Height)
+	visited := grid.NewGrid[bool](mg.Width, mg.Height)
+
+	rooms := grid.NewGrid[*Room](mg.Width, mg.Height)
+	for _, room := range mg.roomList {
+		rooms.SetRect(room.X, room.Y, room.Width, room.Height, room)
+	}
+
+	roomThemes := make(map[*Room]terrain.ThemeID, len(mg.roomThemes))
+	for room, theme := range mg.roomThemes {
+		roomThemes[room] = theme
+	}
+
+	queue := make([][2]int, 0)
+
+	// fill claims every open tile of the room for the theme, unless it has
+	// already been claimed.
+	fill := func(room *Room, theme terrain.ThemeID) {
+		if t, ok := roomThemes[room]; ok {
+			theme = t
+		}
+		roomThemes[room] = theme
+
+		for y := room.Y; y < room.Y+room.Height; y++ {
+			for x := room.X; x < room.X+room.Width; x++ {
+				if visited.Get(x, y) || mg.isWallTile(x, y) {
+					continue
+				}
+				visited.Set(x, y, true)
+				themes.Set(x, y, theme)
+				queue = append(queue, [2]int{x, y})
+			}
+		}
+	}
+
+	// the wings start from the rooms with a theme of their own, and then
+	// from a room picked at random for each theme.
+	for _, room := range mg.roomList {
+		if theme, ok := mg.roomThemes[room]; ok {
+			fill(room, theme)
+		}
+	}
+
+	picked := 0
+	r := rng.New(mg.seed ^ themeSeedSalt)
+	for _, i := range r.Perm(len(mg.roomList)) {
+		if picked >= count {
+			break
+		}
+		if _, ok := mg.roomThemes[mg.roomList[i]]; ok {
+			continue
+		}
+		fill(mg.roomList[i], terrain.ThemeID(picked))
+		picked++
+	}
+
+	for len(queue) > 0 {
+		x, y := queue[0][0], queue[0][1]
+		queue = queue[1:]
+		theme := themes.Get(x, y)
+
+		for _, d := range [][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+			nx, ny := x+d[0], y+d[1]
+			// anything outside the map is stone
+			if visited.Get(nx, ny) || mg.isWallTile(nx, ny) {
+				continue
+			}
+
+			if room := rooms.Get(nx, ny); room != nil && mg.terrainGrid.Get(nx, ny) != terrain.Door {
+				fill(room, theme)
+				continue
+			}
+
+			visited.Set(nx, ny, true)
+			themes.Set(nx, ny, theme)
+			queue = append(queue, [2]int{nx, ny})
+		}
+	}
+
+	// the stone takes the theme of the first open tile next to it
+	for y := 0; y < mg.Height; y++ {
+		for x := 0; x < mg.Width; x++ {
+			if !mg.isWallTile(x, y) {
+				continue
+			}
+
+		neighbors:
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if visited.Get(x+dx, y+dy) {
+						themes.Set(x, y, themes.Get(x+dx, y+dy))
+						break neighbors
+					}
+				}
+			}
+		}
+	}
+
+	mg.themeGrid = themes
+	return themes
+}
+
+// isWallTile returns true if the tile is stone, or a secret door, which looks
+// just like the stone around it and so takes its theme.
+func (mg *MapGenerator) isWallTile(x, y int) bool {
+	t := mg.terrainGrid.Get(x, y)
+	return t == terrain.Stone || t == terrain.SecretDoor
+}
+
+// Themes returns the grid made by the last call to AssignThemes, or nil if
+// it hasn't been called.
+func (mg *MapGenerator) Themes() *grid.Grid[terrain.ThemeID] {
+	return mg.themeGrid
+}
+
+// SetRoomTheme overrides the theme of the room, such as to make sure the
+// room with the stairs down looks like the level below. It takes effect the
+// next time AssignThemes is called, and the wing around the room grows out
+// from it.
+func (mg *MapGenerator) SetRoomTheme(room *Room, theme terrain.ThemeID) {
+	if mg.roomThemes == nil {
+		mg.roomThemes = make(map[*Room]terrain.ThemeID)
+	}
+	mg.roomThemes[room] = theme
+}
@@ -0,0 +1,238 @@
+package mapgen
+
+import (
+	"github.com/matjam/sword/internal/grid"
+	"github.com/matjam/sword/internal/terrain"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Tunnels
+
+// CorridorStyle controls how the space between the rooms is filled in.
+type CorridorStyle int
+
+const (
+	// CorridorMaze fills all of the stone between the rooms with mazes, and
+	// relies on dead end removal to prune them back.
+	CorridorMaze CorridorStyle = iota
+	// CorridorDirect digs straight or L-shaped tunnels between the centers of
+	// nearby rooms, like a classic NetHack level.
+	CorridorDirect
+)
+
+// The way direct corridors work is that we build a minimum spanning tree over
+// the rooms, using the distance between their centers, and dig a tunnel along
+// each edge of the tree. Tunnels run along odd rows and columns, just like the
+// mazes do, so they line up with the rooms.
+//
+// Tunnels never dig into the ring of stone around a room, they stop one tile
+// short. That leaves a single stone tile between the tunnel and the room,
+// which the connector phase will find and turn into a door. When a tunnel
+// passes right through a room, the part on the far side is a separate piece
+// of tunnel and gets a region of its own, since it isn't actually joined to
+// the first part. When a tunnel runs into another tunnel, they are joined, so
+// their regions are merged.
+
+func (mg *MapGenerator) generateTunnels() {
+	// The generateTunnels() method digs one tunnel per tick, so that you can
+	// watch the tunnels being dug. The first tick plans all of the tunnels.
+
+	if !mg.tunnelsPlanned {
+		mg.planTunnels()
+	}
+
+	if len(mg.tunnels) == 0 {
+		mg.digBridge()
+		mg.Phase = PhaseMirror
+		return
+	}
+
+	tunnel := mg.tunnels[0]
+	mg.tunnels = mg.tunnels[1:]
+
+	mg.digTunnel(tunnel[0], tunnel[1])
+}
+
+func (mg *MapGenerator) planTunnels() {
+	// The planTunnels() method uses Prim's algorithm to find the shortest set of
+	// tunnels that joins every room to every other room.
+
+	mg.tunnelsPlanned = true
+
+	if len(mg.roomList) < 2 {
+		return
+	}
+
+	// distance[i] is how far room i is from the closest room that's already
+	// in the tree, and closest[i] is that room.
+	inTree := make([]bool, len(mg.roomList))
+	distance := make([]int, len(mg.roomList))
+	closest := make([]int, len(mg.roomList))
+
+	current := 0
+	inTree[current] = true
+	for i := range distance {
+		distance[i] = -1
+	}
+
+	for added := 1; added < len(mg.roomList); added++ {
+		cx, cy := mg.roomList[current].center()
+
+		next := -1
+		for i, room := range mg.roomList {
+			if inTree[i] {
+				continue
+			}
+
+			x, y := room.center()
+			d := grid.Manhattan(cx, cy, x, y)
+			if distance[i] < 0 || d < distance[i] {
+				distance[i] = d
+				closest[i] = current
+			}
+
+			if next < 0 || distance[i] < distance[next] {
+				next = i
+			}
+		}
+
+		inTree[next] = true
+		mg.tunnels = append(mg.tunnels, [2]*Room{mg.roomList[closest[next]], mg.roomList[next]})
+		current = next
+	}
+}
+
+func (mg *MapGenerator) digTunnel(from, to *Room) {
+	// The digTunnel() method digs an L-shaped tunnel between the centers of the
+	// two rooms. Which way the tunnel turns is picked at random.
+
+	x1, y1 := from.center()
+	x2, y2 := to.center()
+
+	mg.currentRegion = nil
+
+	if mg.rng.Intn(2) == 0 {
+		mg.digLine(x1, y1, x2, y1)
+		mg.digLine(x2, y1, x2, y2)
+	} else {
+		mg.digLine(x1, y1, x1, y2)
+		mg.digLine(x1, y2, x2, y2)
+	}
+}
+
+func (mg *MapGenerator) digLine(x1, y1, x2, y2 int) {
+	// The digLine() method digs a straight line of tunnel, one tile at a time.
+	// Only one of the coordinates changes.
+
+	dx, dy := sign(x2-x1), sign(y2-y1)
+
+	for x, y := x1, y1; ; x, y = x+dx, y+dy {
+		mg.digTunnelTile(x, y)
+
+		if x == x2 && y == y2 {
+			return
+		}
+	}
+}
+
+func (mg *MapGenerator) digTunnelTile(x, y int) {
+	t := mg.terrainGrid.Get(x, y)
+
+	switch {
+	case t == terrain.Corridor:
+		// we've run into another tunnel, so join it
+		if mg.currentRegion == nil {
+			mg.currentRegion = mg.regionGrid.Get(x, y)
+		}
+		mg.mergeRegions(mg.regionGrid.Get(x, y), mg.currentRegion)
+
+	case mg.isCarvable(x, y) && !mg.isBesideRoom(x, y):
+		if mg.currentRegion == nil {
+			mg.currentRegion = mg.nextRegion()
+		}
+
+		mg.terrainGrid.Set(x, y, terrain.Corridor)
+		mg.regionGrid.Set(x, y, mg.currentRegion)
+
+		// join up with any tunnel that runs right alongside this one
+		for _, n := range [][2]int{{x, y - 1}, {x, y + 1}, {x - 1, y}, {x + 1, y}} {
+			if mg.terrainGrid.Get(n[0], n[1]) == terrain.Corridor {
+				mg.mergeRegions(mg.regionGrid.Get(n[0], n[1]), mg.currentRegion)
+			}
+		}
+
+	default:
+		// the tunnel is interrupted by a room or its walls. Whatever we dig
+		// next isn't joined to what we dug before, so it gets a new region.
+		mg.currentRegion = nil
+	}
+}
+
+func (mg *MapGenerator) digBridge() {
+	// The digBridge() method joins the two halves of a symmetric map. Mazes
+	// fill the source area right up to the axis, so there is always somewhere
+	// to put a door across it, but tunnels only go between rooms. So we dig
+	// one more tunnel, from the room closest to the axis up to the axis. Once
+	// it's mirrored, the two ends meet with a single stone tile between them.
+	//
+	// Because we start from the closest room, there can't be another room in
+	// the way of the tunnel as it heads towards the axis. The tiles at the end
+	// are on odd rows and columns, so they are always either dug out or inside
+	// a room, never left as a wall.
+
+	if mg.Symmetry == SymmetryNone || len(mg.roomList) == 0 {
+		return
+	}
+
+	axisX, axisY := mg.axis()
+
+	closest := mg.roomList[0]
+	for _, room := range mg.roomList {
+		x, y := room.center()
+		cx, cy := closest.center()
+
+		if (mg.Symmetry == SymmetryHorizontal && x > cx) || (mg.Symmetry != SymmetryHorizontal && y > cy) {
+			closest = room
+		}
+	}
+
+	x, y := closest.center()
+	mg.currentRegion = nil
+
+	switch mg.Symmetry {
+	case SymmetryHorizontal:
+		mg.digLine(x, y, axisX-1, y)
+	case SymmetryVertical:
+		mg.digLine(x, y, x, axisY-1)
+	case SymmetryRotational:
+		// the rotated copy of the tunnel ends up on the other side of the
+		// center, so we also dig along the row next to the axis until we're
+		// past the center. The tiles either side of the center then line up
+		// with each other's copies.
+		endX := axisX + 1
+		if x > axisX {
+			endX = axisX - 1
+		}
+
+		mg.digLine(x, y, x, axisY-1)
+		mg.digLine(x, axisY-1, endX, axisY-1)
+	}
+}
+
+func (mg *MapGenerator) isBesideRoom(x, y int) bool {
+	// a tile is beside a room if any of its neighbours is open, but isn't a
+	// tunnel.
+	for _, t := range mg.getNeighbours(x, y) {
+		if t != terrain.Stone && t != terrain.Corridor {
+			return true
+		}
+	}
+
+	return false
+}
+
+// center returns the tile nearest the middle of the room that has odd
+// coordinates, so that tunnels dug from it line up with everything else.
+func (r *Room) center() (x, y int) {
+	return r.X + (r.Width/2)&^1, r.Y + (r.Height/2)&^1
+}
@@ -33,6 +33,40 @@ func (mg *MapGenerator) nextRegion() *Region {
 	return &r
 }
 
+// minRoomSize is the width and height of the smallest room in roomSizes.
+const minRoomSize = 3
+
+// bounds returns the first and last tiles, inclusive, that can be carved into
+// once the border has been left around the edge of the map. Everything is
+// carved on odd coordinates, so the first tile is always odd, which makes an
+// even border one tile thicker than asked for along the top and left.
+func (mg *MapGenerator) bounds() (minX, minY, maxX, maxY int) {
+	border := max(mg.BorderThickness, 1)
+	return border | 1, border | 1, mg.Width - 1 - border, mg.Height - 1 - border
+}
+
+// inBounds returns true if the given location is inside bounds().
+func (mg *MapGenerator) inBounds(x, y int) bool {
+	minX, minY, maxX, maxY := mg.bounds()
+	return x >= minX && x <= maxX && y >= minY && y <= maxY
+}
+
 func removeIndex[T any](s []T, index int) []T {
 	return append(s[:index], s[index+1:]...)
 }
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func sign(x int) int {
+	if x < 0 {
+		return -1
+	} else if x > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,88 @@
+package rng_test
+
+import (
+	"testing"
+
+	"github.com/matjam/sword/internal/rng"
+)
+
+func TestSource(t *testing.T) {
+	// the first numbers from the SplitMix64 reference implementation, seeded
+	// with 0. If these ever change, every seed makes a different map.
+	expected := []uint64{
+		0xe220a8397b1dcdaf,
+		0x6e789e6aa1b965f4,
+		0x06c45d188009454f,
+		0xf88bb8a8724c81ec,
+		0x1b39896a51a8749b,
+	}
+
+	src := rng.NewSource(0)
+	for i, want := range expected {
+		if got := src.Uint64(); got != want {
+			t.Errorf("number %d: expected %#x, got %#x", i, want, got)
+		}
+	}
+
+	// reseeding starts the sequence again
+	src.Seed(0)
+	if got := src.Int63(); got != int64(expected[0]>>1) {
+		t.Errorf("expected reseeding to start again, got %#x", got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	// the rolls from rand.Rand are pinned too, as long as the source is
+	expected := []int{51, 53, 71, 35, 65}
+
+	r := rng.New(42)
+	for i, want := range expected {
+		if got := r.Intn(100); got != want {
+			t.Errorf("roll %d: expected %d, got %d", i, want, got)
+		}
+	}
+}
+
+func TestWeightedPicker(t *testing.T) {
+	var picker rng.WeightedPicker[string]
+	if _, ok := picker.Pick(rng.New(1)); ok {
+		t.Error("expected an empty picker not to pick anything")
+	}
+
+	picker.Add("common", 6)
+	picker.Add("never", 0)
+	picker.Add("uncommon", 3)
+	picker.Add("rare", 1)
+
+	if picker.Len() != 3 || picker.Total() != 10 {
+		t.Errorf("expected 3 items weighing 10, got %d weighing %v", picker.Len(), picker.Total())
+	}
+
+	const draws = 10000
+	counts := make(map[string]int)
+	r := rng.New(42)
+	for i := 0; i < draws; i++ {
+		item, _ := picker.Pick(r)
+		counts[item]++
+	}
+
+	// each item should come up in proportion to its weight, give or take a
+	// few percent
+	for item, weight := range map[string]float64{"common": 6, "uncommon": 3, "rare": 1, "never": 0} {
+		got := float64(counts[item]) / draws
+		want := weight / 10
+		if got < want-0.02 || got > want+0.02 {
+			t.Errorf("expected %q about %.0f%% of the time, got %.1f%%", item, want*100, got*100)
+		}
+	}
+
+	// the same seed gives the same picks
+	a, b := rng.New(7), rng.New(7)
+	for i := 0; i < 100; i++ {
+		x, _ := picker.Pick(a)
+		y, _ := picker.Pick(b)
+		if x != y {
+			t.Fatalf("pick %d: expected the same seed to give the same picks, got %q and %q", i, x, y)
+		}
+	}
+}
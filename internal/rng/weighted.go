@@ -0,0 +1,63 @@
+package rng
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// WeightedPicker picks items at random, with each item's chance of being
+// picked in proportion to its weight: an item with a weight of 2 comes up
+// twice as often as one with a weight of 1. The zero value is an empty
+// picker, ready for items to be added.
+//
+// The picks only depend on the rolls from the *rand.Rand, so a picker filled
+// in the same order and given a rand from New with the same seed always picks
+// the same items.
+type WeightedPicker[T any] struct {
+	items []T
+
+	// cumulative is the running total of the weights, so that item i is
+	// picked by any roll from cumulative[i-1] up to cumulative[i].
+	cumulative []float64
+}
+
+// Add adds an item with the given weight. Items with a weight of zero or less
+// can never be picked, so they are left out.
+func (p *WeightedPicker[T]) Add(item T, weight float64) {
+	if weight <= 0 {
+		return
+	}
+
+	p.items = append(p.items, item)
+	p.cumulative = append(p.cumulative, p.Total()+weight)
+}
+
+// Len returns the number of items that can be picked.
+func (p *WeightedPicker[T]) Len() int {
+	return len(p.items)
+}
+
+// Total returns the sum of the weights of all of the items.
+func (p *WeightedPicker[T]) Total() float64 {
+	if len(p.cumulative) == 0 {
+		return 0
+	}
+	return p.cumulative[len(p.cumulative)-1]
+}
+
+// Pick returns a random item, using one roll of r. If there are no items, it
+// returns the zero value and false, without rolling.
+func (p *WeightedPicker[T]) Pick(r *rand.Rand) (T, bool) {
+	if len(p.items) == 0 {
+		var zero T
+		return zero, false
+	}
+
+	roll := r.Float64() * p.Total()
+	i := sort.Search(len(p.cumulative), func(i int) bool {
+		return p.cumulative[i] > roll
+	})
+
+	// rounding can leave the roll a hair past the last total
+	return p.items[min(i, len(p.items)-1)], true
+}
@@ -0,0 +1,124 @@
+package savegame
+
+// package savegame saves and loads a whole game: the world with every entity
+// in it, the map, and which entity is the player.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/tilemap"
+)
+
+// Version is the version of the save game format that Save writes. It goes up
+// whenever the format changes in a way that older saves can't be loaded
+// properly, and Load refuses any other version. Adding a new field doesn't
+// need a new version: fields Load doesn't know about are ignored, and fields
+// missing from an old save are left as they were.
+//
+// Version 2 moved the world's random numbers to rng.Source, so the draws
+// saved by version 1 no longer pick up where they left off.
+const Version = 2
+
+// ErrVersion is returned by Load when the save game was written with a
+// different Version of the format.
+var ErrVersion = errors.New("unsupported save game version")
+
+// Game is everything that makes up a game in progress.
+type Game struct {
+	// World is the world with every entity in it. When loading, it should
+	// already have its systems added, and every type of entity registered;
+	// see ecs.World.UnmarshalJSON.
+	World *ecs.World
+
+	// Tilemap is the map the player is on.
+	Tilemap *tilemap.Grid
+
+	// Player is the player's entity. Once a game has been loaded, anything
+	// that keeps track of the player, such as the Input system, needs to be
+	// given the new one.
+	Player ecs.EntityID
+}
+
+// saveFile is the format of a save game. The tilemap is saved in its binary
+// format, which encoding/json turns into base64, since as JSON it would take
+// up several bytes for every field of every tile.
+type saveFile struct {
+	Version int          `json:"version"`
+	Player  ecs.EntityID `json:"player"`
+	Tilemap []byte       `json:"tilemap"`
+	World   *ecs.World   `json:"world"`
+}
+
+// Save writes the game to the file at path. The file is written next to path
+// first and then renamed over it, so a save that fails part of the way
+// through doesn't destroy the last one.
+func Save(path string, game *Game) error {
+	tiles, err := game.Tilemap.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("saving the tilemap: %w", err)
+	}
+
+	data, err := json.Marshal(saveFile{
+		Version: Version,
+		Player:  game.Player,
+		Tilemap: tiles,
+		World:   game.World,
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// Load reads a game saved by Save from the file at path into game, replacing
+// the contents of its World and Tilemap and setting its Player. If the save
+// is from a different Version, ErrVersion is returned and the game is left
+// alone. If it fails part of the way through loading, the game is left half
+// loaded and shouldn't be played.
+func Load(path string, game *Game) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	if header.Version != Version {
+		return fmt.Errorf("%w: %s is version %d, this game reads version %d", ErrVersion, path, header.Version, Version)
+	}
+
+	var save struct {
+		Player  ecs.EntityID    `json:"player"`
+		Tilemap []byte          `json:"tilemap"`
+		World   json.RawMessage `json:"world"`
+	}
+	if err := json.Unmarshal(data, &save); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if err := game.Tilemap.UnmarshalBinary(save.Tilemap); err != nil {
+		return fmt.Errorf("loading the tilemap: %w", err)
+	}
+
+	if err := game.World.UnmarshalJSON(save.World); err != nil {
+		return fmt.Errorf("loading the world: %w", err)
+	}
+
+	game.Player = save.Player
+
+	return nil
+}
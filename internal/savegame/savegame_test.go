@@ -0,0 +1,103 @@
+package savegame_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matjam/sword/internal/ecs"
+	"github.com/matjam/sword/internal/ecs/component"
+	"github.com/matjam/sword/internal/ecs/entity"
+	"github.com/matjam/sword/internal/savegame"
+	"github.com/matjam/sword/internal/tilemap"
+)
+
+// newGame returns a world with the entity types registered, as the game sets
+// it up before loading.
+func newGame() *savegame.Game {
+	world := ecs.NewWorld()
+	world.RegisterEntities(entity.All()...)
+	return &savegame.Game{World: world, Tilemap: tilemap.NewGrid(1, 1)}
+}
+
+func TestSaveLoad(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	game := newGame()
+	game.Tilemap = tilemap.NewGrid(10, 8)
+	game.Tilemap.SetTile(3, 4, &tilemap.Tile{Type: tilemap.TileTypeFloor, Seen: true})
+	game.World.SetSeed(99)
+	game.World.AddEntity(&entity.Mob{})
+	game.Player = game.World.AddEntity(&entity.Player{})
+	game.World.MoveEntity(game.Player, 3, 0)
+	game.World.EndTurn()
+
+	path := filepath.Join(t.TempDir(), "game.sav")
+	if err := savegame.Save(path, game); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := newGame()
+	if err := savegame.Load(path, loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Player != game.Player {
+		t.Errorf("expected the player to be entity %d, got %d", game.Player, loaded.Player)
+	}
+	if x := ecs.GetComponent[*component.Location](loaded.World, loaded.Player).X; x != 3 {
+		t.Errorf("expected the player to be at x 3, got %d", x)
+	}
+	if loaded.World.Turn() != 1 || loaded.World.Seed() != 99 {
+		t.Errorf("expected turn 1 and seed 99, got %d and %d", loaded.World.Turn(), loaded.World.Seed())
+	}
+	if tile := loaded.Tilemap.GetTile(3, 4); tile == nil || tile.Type != tilemap.TileTypeFloor || !tile.Seen {
+		t.Errorf("expected the tilemap to be loaded, got %+v", tile)
+	}
+}
+
+func TestLoadCompatibility(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	game := newGame()
+	game.Player = game.World.AddEntity(&entity.Player{})
+
+	path := filepath.Join(t.TempDir(), "game.sav")
+	if err := savegame.Save(path, game); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := fmt.Sprintf(`{"version":%d,`, savegame.Version)
+	if !bytes.HasPrefix(data, []byte(header)) {
+		t.Fatalf("expected the save to start with %s, got %.40s", header, data)
+	}
+
+	// fields from a newer version of the same format are ignored
+	newer := bytes.Replace(data, []byte(header), []byte(header+`"weather":"rain",`), 1)
+	if err := os.WriteFile(path, newer, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := savegame.Load(path, newGame()); err != nil {
+		t.Errorf("expected unknown fields to be ignored, got %v", err)
+	}
+
+	// but a different version isn't loaded at all
+	other := bytes.Replace(data, []byte(header), []byte(fmt.Sprintf(`{"version":%d,`, savegame.Version-1)), 1)
+	if err := os.WriteFile(path, other, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := savegame.Load(path, newGame()); !errors.Is(err, savegame.ErrVersion) {
+		t.Errorf("expected ErrVersion, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package scent
+
+// package scent keeps track of where the player has been recently, so that
+// mobs can follow their trail even when they can't see them. The player
+// leaves scent on every tile they stand on, and every turn it fades away and
+// spreads out a little into the tiles around it.
+
+import "github.com/matjam/sword/internal/grid"
+
+// DefaultDecay and DefaultSpread are used if a Map's Decay or Spread aren't
+// set.
+const (
+	DefaultDecay  = 4
+	DefaultSpread = 32
+)
+
+// neighbors are the offsets of the eight tiles around a tile, clockwise
+// starting from north. Sniff prefers the earlier ones when there's a tie.
+var neighbors = [8][2]int{
+	{0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1},
+}
+
+// cardinals are the offsets of the four tiles that scent spreads into.
+var cardinals = [4][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+
+// Map is the strength of the scent on every tile, from 0 for none to 255.
+type Map struct {
+	*grid.Grid[uint8]
+
+	// Decay is how much the scent on every tile fades each turn.
+	Decay uint8
+
+	// Spread is how much weaker the scent is when it spreads into the tiles
+	// next to it. The larger it is, the less the scent spreads.
+	Spread uint8
+
+	// Passable, if set, returns whether scent can spread into the given tile,
+	// and whether Sniff can lead there. Scent doesn't go through walls.
+	Passable func(x, y int) bool
+
+	// next is where the scent for the next turn is worked out, so that
+	// spreading doesn't depend on the order the tiles are visited in.
+	next *grid.Grid[uint8]
+}
+
+// New creates a map with no scent on it.
+func New(width, height int) *Map {
+	return &Map{
+		Grid: grid.NewGrid[uint8](width, height),
+		next: grid.NewGrid[uint8](width, height),
+	}
+}
+
+// Deposit leaves the given amount of scent on a tile. Scent doesn't build up;
+// the tile just ends up with the stronger of the old and new scents.
+func (m *Map) Deposit(x, y int, amount uint8) {
+	m.Set(x, y, max(m.Get(x, y), amount))
+}
+
+// Update ages the scent by a turn: every tile fades by Decay, and then picks
+// up whatever has spread to it from the tiles around it, less Spread.
+func (m *Map) Update() {
+	decay := m.Decay
+	if decay == 0 {
+		decay = DefaultDecay
+	}
+	spread := m.Spread
+	if spread == 0 {
+		spread = DefaultSpread
+	}
+
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			scent := subtract(m.Get(x, y), decay)
+
+			if m.passable(x, y) {
+				for _, n := range cardinals {
+					nx, ny := x+n[0], y+n[1]
+					scent = max(scent, subtract(m.Get(nx, ny), spread))
+				}
+			}
+
+			m.next.Set(x, y, scent)
+		}
+	}
+
+	m.Grid, m.next = m.next, m.Grid
+}
+
+// Sniff returns the direction of the neighbouring tile, in any of the eight
+// directions, with the strongest scent. It returns false if none of them
+// smell any stronger than the tile itself, so there's nowhere to follow the
+// trail to.
+func (m *Map) Sniff(x, y int) (dx, dy int, ok bool) {
+	best := m.Get(x, y)
+
+	for _, n := range neighbors {
+		nx, ny := x+n[0], y+n[1]
+		if !m.passable(nx, ny) {
+			continue
+		}
+
+		if scent := m.Get(nx, ny); scent > best {
+			best = scent
+			dx, dy, ok = n[0], n[1], true
+		}
+	}
+
+	return dx, dy, ok
+}
+
+// passable returns true if scent can go into the tile.
+func (m *Map) passable(x, y int) bool {
+	if x < 0 || x >= m.Width || y < 0 || y >= m.Height {
+		return false
+	}
+	return m.Passable == nil || m.Passable(x, y)
+}
+
+// subtract returns a - b, or 0 if b is bigger than a.
+func subtract(a, b uint8) uint8 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
@@ -0,0 +1,71 @@
+package scent_test
+
+import (
+	"testing"
+
+	"github.com/matjam/sword/internal/scent"
+)
+
+func TestTrail(t *testing.T) {
+	m := scent.New(10, 3)
+
+	// the player walks along the middle row from left to right
+	for x := 1; x < 8; x++ {
+		m.Update()
+		m.Deposit(x, 1, 255)
+	}
+
+	// the newest scent is strongest, so the trail leads towards the player
+	for x := 1; x < 7; x++ {
+		dx, dy, ok := m.Sniff(x, 1)
+		if !ok || dx != 1 || dy != 0 {
+			t.Errorf("expected the trail at %d,1 to lead east, got %d,%d %v", x, dx, dy, ok)
+		}
+	}
+
+	// a mob next to the trail is led onto it
+	if dx, dy, ok := m.Sniff(3, 0); !ok || dy != 1 || dx < 0 {
+		t.Errorf("expected to be led onto the trail, got %d,%d %v", dx, dy, ok)
+	}
+
+	// there's nowhere better to go from where the player is standing
+	if _, _, ok := m.Sniff(7, 1); ok {
+		t.Error("expected the trail to end at the player")
+	}
+
+	// the scent fades away completely if the player leaves
+	for i := 0; i < 100; i++ {
+		m.Update()
+	}
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if m.Get(x, y) != 0 {
+				t.Fatalf("expected the scent to fade away, got %d at %d,%d", m.Get(x, y), x, y)
+			}
+		}
+	}
+}
+
+func TestWallsBlockScent(t *testing.T) {
+	m := scent.New(5, 1)
+	m.Spread = 1
+	m.Passable = func(x, y int) bool { return x != 2 }
+
+	m.Deposit(0, 0, 255)
+	for i := 0; i < 5; i++ {
+		m.Update()
+	}
+
+	if m.Get(1, 0) == 0 {
+		t.Error("expected the scent to spread next to where it was left")
+	}
+	if m.Get(2, 0) != 0 || m.Get(3, 0) != 0 {
+		t.Errorf("expected the wall to stop the scent, got %d in the wall and %d beyond it", m.Get(2, 0), m.Get(3, 0))
+	}
+
+	// and the trail can't lead into the wall
+	m.Deposit(2, 0, 255)
+	if _, _, ok := m.Sniff(1, 0); ok {
+		t.Error("expected Sniff to ignore the wall")
+	}
+}
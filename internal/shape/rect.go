@@ -88,6 +88,75 @@ func (r *Rect) Resize(width int, height int) {
 	r.Height += height
 }
 
+// Rotate90 returns a copy of the rect rotated 90 degrees about its center, so
+// the width and height are swapped but the center stays where it was. This is
+// exact for odd sized rects like rooms; even sizes may shift by a tile.
+func (r *Rect) Rotate90() *Rect {
+	cx, cy := r.Center()
+	return &Rect{
+		X:      cx - r.Height/2,
+		Y:      cy - r.Width/2,
+		Width:  r.Height,
+		Height: r.Width,
+	}
+}
+
+// BorderPoints returns every cell on the edge of the rect, each one once,
+// going clockwise from the top left corner. If the rect is only one cell wide
+// or high, every cell is on the border. An empty rect has no border.
+func (r *Rect) BorderPoints() [][2]int {
+	if r.Width <= 0 || r.Height <= 0 {
+		return nil
+	}
+
+	n := 2*(r.Width+r.Height) - 4
+	if r.Width == 1 || r.Height == 1 {
+		n = r.Width * r.Height
+	}
+	points := make([][2]int, 0, n)
+
+	// top, left to right
+	for x := r.X; x < r.X+r.Width; x++ {
+		points = append(points, [2]int{x, r.Y})
+	}
+	// right, top to bottom
+	for y := r.Y + 1; y < r.Y+r.Height; y++ {
+		points = append(points, [2]int{r.X + r.Width - 1, y})
+	}
+	// bottom, right to left, unless it's the same row as the top
+	if r.Height > 1 {
+		for x := r.X + r.Width - 2; x >= r.X; x-- {
+			points = append(points, [2]int{x, r.Y + r.Height - 1})
+		}
+	}
+	// left, bottom to top, unless it's the same column as the right
+	if r.Width > 1 {
+		for y := r.Y + r.Height - 2; y > r.Y; y-- {
+			points = append(points, [2]int{r.X, y})
+		}
+	}
+
+	return points
+}
+
+// InteriorPoints returns every cell inside the rect that isn't on the border,
+// a row at a time. Together with BorderPoints it covers the whole rect, so a
+// rect less than three cells wide or high has no interior.
+func (r *Rect) InteriorPoints() [][2]int {
+	if r.Width < 3 || r.Height < 3 {
+		return nil
+	}
+
+	points := make([][2]int, 0, (r.Width-2)*(r.Height-2))
+	for y := r.Y + 1; y < r.Y+r.Height-1; y++ {
+		for x := r.X + 1; x < r.X+r.Width-1; x++ {
+			points = append(points, [2]int{x, y})
+		}
+	}
+
+	return points
+}
+
 func (r *Rect) Clone() *Rect {
 	return &Rect{
 		X:      r.X,
@@ -0,0 +1,76 @@
+package shape_test
+
+import (
+	"testing"
+
+	"github.com/matjam/sword/internal/shape"
+)
+
+func TestRotate90(t *testing.T) {
+	r := shape.NewRect(10, 20, 7, 3)
+
+	rotated := r.Rotate90()
+	if rotated.Width != 3 || rotated.Height != 7 {
+		t.Errorf("expected 3x7, got %dx%d", rotated.Width, rotated.Height)
+	}
+
+	cx, cy := r.Center()
+	if x, y := rotated.Center(); x != cx || y != cy {
+		t.Errorf("expected center %d,%d, got %d,%d", cx, cy, x, y)
+	}
+
+	if back := rotated.Rotate90(); *back != *r {
+		t.Errorf("expected rotating twice to give %v, got %v", r, back)
+	}
+}
+
+func TestBorderAndInteriorPoints(t *testing.T) {
+	tests := []struct {
+		name             string
+		w, h             int
+		border, interior int
+	}{
+		{"square", 3, 3, 8, 1},
+		{"room", 7, 5, 20, 15},
+		{"two wide", 2, 4, 8, 0},
+		{"one wide", 1, 4, 4, 0},
+		{"one high", 5, 1, 5, 0},
+		{"single cell", 1, 1, 1, 0},
+		{"empty", 0, 3, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := shape.NewRect(2, 3, tt.w, tt.h)
+			border := r.BorderPoints()
+			interior := r.InteriorPoints()
+
+			if len(border) != tt.border {
+				t.Errorf("expected %d border points, got %d", tt.border, len(border))
+			}
+			if len(interior) != tt.interior {
+				t.Errorf("expected %d interior points, got %d", tt.interior, len(interior))
+			}
+
+			// Between them they should cover every cell in the rect exactly
+			// once, even when the rect is too thin to have an interior.
+			seen := make(map[[2]int]bool)
+			for _, p := range append(border, interior...) {
+				if !r.Contains(p[0], p[1]) {
+					t.Errorf("point %v is outside the rect", p)
+				}
+				if seen[p] {
+					t.Errorf("point %v appears more than once", p)
+				}
+				seen[p] = true
+			}
+			if len(seen) != max(0, tt.w*tt.h) {
+				t.Errorf("expected %d points in total, got %d", tt.w*tt.h, len(seen))
+			}
+
+			if len(border) > 0 && border[0] != [2]int{2, 3} {
+				t.Errorf("expected the border to start at the top left, got %v", border[0])
+			}
+		})
+	}
+}
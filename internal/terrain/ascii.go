@@ -0,0 +1,107 @@
+package terrain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// glyphs are the characters String and FromString use for each terrain type.
+// The first four are the ones maps are mostly made of, and are easy to type
+// into a test.
+var glyphs = map[Type]rune{
+	Stone:      '#',
+	Room:       '.',
+	Corridor:   ' ',
+	Door:       '+',
+	Rubble:     '%',
+	Water:      '~',
+	Trap:       '^',
+	SecretDoor: '=',
+}
+
+// ErrInvalidString is returned by FromString when the string isn't a map.
+// The error it returns wraps this, with the line and column of the problem.
+var ErrInvalidString = errors.New("terrain: invalid map string")
+
+// String returns the terrain as text, one line per row, with a character for
+// each tile:
+//
+//	# stone
+//	. room
+//	  corridor (a space)
+//	+ door
+//	% rubble
+//	~ water
+//	^ trap
+//	= secret door
+//
+// Anything else is drawn as '?'. Every line, including the last, ends with a
+// newline. FromString turns the text back into a terrain.
+func (t *Terrain) String() string {
+	var sb strings.Builder
+	sb.Grow((t.Width + 1) * t.Height)
+
+	for y := 0; y < t.Height; y++ {
+		for _, tt := range t.Row(y) {
+			glyph, ok := glyphs[tt]
+			if !ok {
+				glyph = '?'
+			}
+			sb.WriteRune(glyph)
+		}
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// FromString builds a terrain from text in the format String writes, which
+// makes it easy to write small maps inline in tests. Every line must be the
+// same width, and use only the characters String does.
+//
+// So that a map can be written as a raw string literal starting on the line
+// after the opening backtick, an empty first line and an empty last line are
+// ignored. Since a space is a corridor, the lines can't be indented.
+func FromString(s string) (*Terrain, error) {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	first := 1
+	if len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+		first++
+	}
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: no tiles", ErrInvalidString)
+	}
+
+	types := make(map[rune]Type, len(glyphs))
+	for tt, glyph := range glyphs {
+		types[glyph] = tt
+	}
+
+	rows := make([][]rune, len(lines))
+	for y, line := range lines {
+		rows[y] = []rune(line)
+		if len(rows[y]) != len(rows[0]) {
+			return nil, fmt.Errorf("%w: line %d is %d tiles wide, but line %d is %d",
+				ErrInvalidString, first+y, len(rows[y]), first, len(rows[0]))
+		}
+	}
+
+	t := NewTerrain(len(rows[0]), len(rows))
+	for y, row := range rows {
+		for x, glyph := range row {
+			tt, ok := types[glyph]
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown glyph %q at line %d, column %d",
+					ErrInvalidString, glyph, first+y, x+1)
+			}
+			t.Set(x, y, tt)
+		}
+	}
+
+	return t, nil
+}
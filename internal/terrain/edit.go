@@ -0,0 +1,165 @@
+package terrain
+
+// DefaultMaxUndo is the MaxUndo used if it isn't set.
+const DefaultMaxUndo = 100
+
+// Edit is a change to a single tile of a Terrain.
+type Edit struct {
+	X, Y int
+	Old  Type
+	New  Type
+}
+
+// EditBuffer changes the tiles of a Terrain in a way that can be undone and
+// redone, for building a level editor. Every SetTile is its own action, unless
+// it is made between Begin and End, in which case everything up to the End is
+// a single action that is undone and redone all at once, such as drawing a
+// whole room with one drag of the mouse.
+//
+// Tiles should only be changed through the buffer while it is in use, since
+// undoing puts back what the buffer thinks was there before.
+type EditBuffer struct {
+	Terrain *Terrain
+
+	// MaxUndo is the number of actions that can be undone. Once there are
+	// more, the oldest are forgotten. Zero means DefaultMaxUndo.
+	MaxUndo int
+
+	// OnChange, if set, is called for every tile that changes, including
+	// when an action is undone or redone, so that something like a cached
+	// renderer can redraw just the tiles that changed.
+	OnChange func(x, y int)
+
+	undo [][]Edit
+	redo [][]Edit
+
+	// group is the action being built between Begin and End, and depth is
+	// how many Begins haven't been ended yet.
+	group []Edit
+	depth int
+}
+
+// NewEditBuffer returns an edit buffer for the terrain, with nothing to undo.
+func NewEditBuffer(t *Terrain) *EditBuffer {
+	return &EditBuffer{Terrain: t}
+}
+
+// SetTile changes the tile at the given position. Positions outside the
+// terrain, and changes that don't change anything, are ignored and don't
+// become an action. Making a change throws away anything that could have
+// been redone.
+func (b *EditBuffer) SetTile(x, y int, t Type) {
+	if x < 0 || x >= b.Terrain.Width || y < 0 || y >= b.Terrain.Height {
+		return
+	}
+
+	old := b.Terrain.Get(x, y)
+	if old == t {
+		return
+	}
+
+	b.apply(x, y, t)
+
+	edit := Edit{X: x, Y: y, Old: old, New: t}
+	if b.depth > 0 {
+		b.group = append(b.group, edit)
+		return
+	}
+	b.push([]Edit{edit})
+}
+
+// Begin starts grouping changes into a single action, which ends at the
+// matching End. Begin and End can be nested, such as a fill tool that is
+// used as part of a bigger tool, and the action only ends at the outermost
+// End.
+func (b *EditBuffer) Begin() {
+	b.depth++
+}
+
+// End ends the action started by the matching Begin. An action that didn't
+// change anything isn't kept.
+func (b *EditBuffer) End() {
+	if b.depth == 0 {
+		return
+	}
+
+	b.depth--
+	if b.depth > 0 || len(b.group) == 0 {
+		return
+	}
+
+	b.push(b.group)
+	b.group = nil
+}
+
+// Undo undoes the last action, and returns false if there is nothing to undo.
+// It can't be used in the middle of an action.
+func (b *EditBuffer) Undo() bool {
+	if b.depth > 0 || len(b.undo) == 0 {
+		return false
+	}
+
+	action := b.undo[len(b.undo)-1]
+	b.undo = b.undo[:len(b.undo)-1]
+
+	// the edits are undone in reverse, so a tile that was changed more than
+	// once ends up back how it was before the first change
+	for i := len(action) - 1; i >= 0; i-- {
+		b.apply(action[i].X, action[i].Y, action[i].Old)
+	}
+
+	b.redo = append(b.redo, action)
+	return true
+}
+
+// Redo makes the last action that was undone again, and returns false if
+// there is nothing to redo. It can't be used in the middle of an action.
+func (b *EditBuffer) Redo() bool {
+	if b.depth > 0 || len(b.redo) == 0 {
+		return false
+	}
+
+	action := b.redo[len(b.redo)-1]
+	b.redo = b.redo[:len(b.redo)-1]
+
+	for _, edit := range action {
+		b.apply(edit.X, edit.Y, edit.New)
+	}
+
+	b.undo = append(b.undo, action)
+	return true
+}
+
+// CanUndo returns true if there is an action to undo.
+func (b *EditBuffer) CanUndo() bool {
+	return len(b.undo) > 0
+}
+
+// CanRedo returns true if there is an action to redo.
+func (b *EditBuffer) CanRedo() bool {
+	return len(b.redo) > 0
+}
+
+// push adds a new action to the undo history, forgetting the oldest action
+// if there are too many, and anything that could have been redone.
+func (b *EditBuffer) push(action []Edit) {
+	maxUndo := b.MaxUndo
+	if maxUndo <= 0 {
+		maxUndo = DefaultMaxUndo
+	}
+
+	b.undo = append(b.undo, action)
+	if len(b.undo) > maxUndo {
+		b.undo = append(b.undo[:0], b.undo[len(b.undo)-maxUndo:]...)
+	}
+
+	b.redo = b.redo[:0]
+}
+
+// apply sets the tile and tells OnChange about it.
+func (b *EditBuffer) apply(x, y int, t Type) {
+	b.Terrain.Set(x, y, t)
+	if b.OnChange != nil {
+		b.OnChange(x, y)
+	}
+}
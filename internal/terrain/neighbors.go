@@ -0,0 +1,129 @@
+package terrain
+
+// OutOfBounds is returned by Neighbors8 for neighbours that are off the edge
+// of the terrain. It isn't a real terrain type, and is never stored in a
+// Terrain.
+const OutOfBounds Type = 255
+
+// The bits of the mask returned by WallMask8. The four cardinal directions
+// are the low bits, in the same order as the 16 tile autotiles use, so
+// masking with CardinalMask gives the index into a 16 tile set. The diagonals
+// are only needed for 47 tile autotiling.
+const (
+	MaskNorth uint8 = 1 << iota
+	MaskEast
+	MaskSouth
+	MaskWest
+	MaskNorthEast
+	MaskSouthEast
+	MaskSouthWest
+	MaskNorthWest
+
+	CardinalMask = MaskNorth | MaskEast | MaskSouth | MaskWest
+)
+
+// Source is anything that can be read like a Terrain. It lets the neighbour
+// helpers work on things that aren't stored as a Terrain, such as a tilemap
+// being drawn with a tileset.
+type Source interface {
+	Size() (width, height int)
+	Get(x, y int) Type
+}
+
+// Size returns the width and height of the terrain.
+func (t *Terrain) Size() (width, height int) {
+	return t.Width, t.Height
+}
+
+// neighborOffsets are the offsets of the eight neighbours of a tile, in the
+// order Neighbors8 returns them: clockwise starting from north.
+var neighborOffsets = [8][2]int{
+	{0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1},
+}
+
+// neighborMasks are the WallMask8 bits for each of neighborOffsets.
+var neighborMasks = [8]uint8{
+	MaskNorth, MaskNorthEast, MaskEast, MaskSouthEast, MaskSouth, MaskSouthWest, MaskWest, MaskNorthWest,
+}
+
+// Neighbors8 returns the eight tiles around the given position, clockwise
+// starting from north: N, NE, E, SE, S, SW, W, NW. Neighbours off the edge of
+// the source are OutOfBounds.
+func Neighbors8(src Source, x, y int) [8]Type {
+	width, height := src.Size()
+
+	var neighbors [8]Type
+	for i, offset := range neighborOffsets {
+		nx, ny := x+offset[0], y+offset[1]
+		if nx < 0 || nx >= width || ny < 0 || ny >= height {
+			neighbors[i] = OutOfBounds
+			continue
+		}
+		neighbors[i] = src.Get(nx, ny)
+	}
+	return neighbors
+}
+
+// IsWall returns true if the tile at the given position is stone that can be
+// seen from somewhere open: at least one of its eight neighbours is something
+// other than stone. Stone buried in the middle of the rock isn't a wall, and
+// isn't drawn.
+func IsWall(src Source, x, y int) bool {
+	width, height := src.Size()
+	if x < 0 || x >= width || y < 0 || y >= height || src.Get(x, y) != Stone {
+		return false
+	}
+
+	for _, neighbor := range Neighbors8(src, x, y) {
+		if neighbor != Stone && neighbor != OutOfBounds {
+			return true
+		}
+	}
+	return false
+}
+
+// WallMask8 returns a bit for each of the eight neighbours of the given
+// position that is a wall, as decided by IsWall. A diagonal neighbour only
+// counts if the walls on both sides of it do too, since a corner that isn't
+// joined up on both sides looks the same as no corner at all; this is what
+// cuts the 256 possible masks down to the 47 tiles of a blob tileset.
+func WallMask8(src Source, x, y int) uint8 {
+	var mask uint8
+	for i, offset := range neighborOffsets {
+		if IsWall(src, x+offset[0], y+offset[1]) {
+			mask |= neighborMasks[i]
+		}
+	}
+
+	diagonals := [4][3]uint8{
+		{MaskNorthEast, MaskNorth, MaskEast},
+		{MaskSouthEast, MaskSouth, MaskEast},
+		{MaskSouthWest, MaskSouth, MaskWest},
+		{MaskNorthWest, MaskNorth, MaskWest},
+	}
+	for _, d := range diagonals {
+		if mask&d[1] == 0 || mask&d[2] == 0 {
+			mask &^= d[0]
+		}
+	}
+
+	return mask
+}
+
+// Neighbors8 returns the eight tiles around the given position. See the
+// Neighbors8 function.
+func (t *Terrain) Neighbors8(x, y int) [8]Type {
+	return Neighbors8(t, x, y)
+}
+
+// IsWall returns true if the tile at the given position is a wall. See the
+// IsWall function.
+func (t *Terrain) IsWall(x, y int) bool {
+	return IsWall(t, x, y)
+}
+
+// WallMask8 returns the walls around the given position as a mask. See the
+// WallMask8 function.
+func (t *Terrain) WallMask8(x, y int) uint8 {
+	return WallMask8(t, x, y)
+}
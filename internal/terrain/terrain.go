@@ -13,8 +13,36 @@ const (
 	Room
 	Corridor
 	Door
+	Rubble
+	Water
+	Trap
+
+	// SecretDoor is a door that looks like a wall until it is found. See
+	// RevealSecretDoor.
+	SecretDoor
 )
 
+// IsPassable returns true if an entity can walk over the terrain type. Water
+// is passable, but slow going. Traps are passable too, they just hurt. Secret
+// doors aren't, until they have been found and turned into doors.
+func (t Type) IsPassable() bool {
+	switch t {
+	case Room, Corridor, Door, Water, Trap:
+		return true
+	}
+
+	return false
+}
+
+// ThemeID picks which of a tileset's looks a part of the map is drawn with,
+// such as a crypt wing and a cave wing in the same dungeon. The terrain type
+// says what a tile is, and the theme only changes how it looks. DefaultTheme
+// is the tileset's own look.
+type ThemeID uint8
+
+// DefaultTheme is the theme of tiles that haven't been given one.
+const DefaultTheme ThemeID = 0
+
 type Terrain struct {
 	*grid.Grid[Type]
 
@@ -31,3 +59,126 @@ func NewTerrain(width, height int) *Terrain {
 		Grid:   grid.NewGrid[Type](width, height),
 	}
 }
+
+// MarshalBinary implements encoding.BinaryMarshaler. Each tile is encoded as
+// its single byte Type.
+func (t *Terrain) MarshalBinary() ([]byte, error) {
+	return t.Grid.Marshal(func(tt Type) []byte {
+		return []byte{byte(tt)}
+	}), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. The terrain is
+// resized to match the data.
+func (t *Terrain) UnmarshalBinary(data []byte) error {
+	if t.Grid == nil {
+		t.Grid = grid.NewGrid[Type](0, 0)
+	}
+
+	err := t.Grid.Unmarshal(data, func(b []byte) Type {
+		if len(b) == 0 {
+			return Stone
+		}
+		return Type(b[0])
+	})
+	if err != nil {
+		return err
+	}
+
+	t.Width = t.Grid.Width
+	t.Height = t.Grid.Height
+
+	return nil
+}
+
+// RevealSecretDoor turns the secret door at the given position into an
+// ordinary door, such as when the player searches next to it. It returns
+// false if there isn't a secret door there.
+func (t *Terrain) RevealSecretDoor(x, y int) bool {
+	if t.Get(x, y) != SecretDoor {
+		return false
+	}
+
+	t.Set(x, y, Door)
+	return true
+}
+
+// Crop returns a copy of the terrain trimmed down to the smallest rectangle
+// holding everything that isn't stone, with margin tiles of stone left around
+// it, such as for exporting a map or drawing a minimap. The margin is padded
+// out with stone if the content is closer than that to the edge.
+//
+// offsetX and offsetY are where the top left corner of the cropped terrain
+// was in this one, so a location in this terrain is at x-offsetX, y-offsetY
+// in the cropped one. If the terrain is nothing but stone, Crop returns nil.
+func (t *Terrain) Crop(margin int) (cropped *Terrain, offsetX, offsetY int) {
+	minX, minY := t.Width, t.Height
+	maxX, maxY := -1, -1
+
+	// this looks at every tile, so it reads the rows directly rather than
+	// going through Get.
+	for y := 0; y < t.Height; y++ {
+		for x, tt := range t.Row(y) {
+			if tt == Stone {
+				continue
+			}
+
+			minX, minY = min(minX, x), min(minY, y)
+			maxX, maxY = max(maxX, x), max(maxY, y)
+		}
+	}
+
+	if maxX < 0 {
+		return nil, 0, 0
+	}
+
+	margin = max(margin, 0)
+	offsetX, offsetY = minX-margin, minY-margin
+	sub := t.SubGrid(offsetX, offsetY, maxX-minX+1+margin*2, maxY-minY+1+margin*2)
+
+	return &Terrain{Grid: sub, Width: sub.Width, Height: sub.Height}, offsetX, offsetY
+}
+
+// Count returns the number of tiles that are any of the given types.
+func (t *Terrain) Count(types ...Type) int {
+	return t.CountMatching(isAnyOf(types))
+}
+
+// Find returns the position of every tile that is any of the given types, row
+// by row from the top left.
+func (t *Terrain) Find(types ...Type) [][2]int {
+	return t.FindAll(isAnyOf(types))
+}
+
+// isAnyOf returns a function that reports whether a tile is one of types.
+func isAnyOf(types []Type) func(Type) bool {
+	return func(tt Type) bool {
+		for _, want := range types {
+			if tt == want {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Equal returns true if the other terrain is the same size and has the same
+// type of terrain in every tile.
+func (t *Terrain) Equal(other *Terrain) bool {
+	if other == nil {
+		return false
+	}
+
+	return t.Grid.Equal(other.Grid, func(a, b Type) bool {
+		return a == b
+	})
+}
+
+// Hash returns a hash of the terrain's contents, which can be used to detect
+// whether the terrain has changed, for example to know when a cached
+// rendering of it needs to be redrawn.
+func (t *Terrain) Hash() uint64 {
+	return t.Grid.Hash(func(tt Type) uint64 {
+		return uint64(tt)
+	})
+}
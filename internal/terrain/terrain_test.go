@@ -0,0 +1,399 @@
+package terrain_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matjam/sword/internal/terrain"
+)
+
+func TestSetRectClipped(t *testing.T) {
+	tests := []struct {
+		name       string
+		x, y, w, h int
+		expected   int
+	}{
+		{"inside", 1, 1, 3, 3, 9},
+		{"bottom right corner", 8, 8, 5, 5, 4},
+		{"top left corner", -2, -2, 4, 4, 4},
+		{"wider than the map", -1, 4, 12, 1, 10},
+		{"completely outside", 12, 12, 3, 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := terrain.NewTerrain(10, 10)
+			tr.SetRect(tt.x, tt.y, tt.w, tt.h, terrain.Room)
+
+			count := 0
+			for y := 0; y < tr.Height; y++ {
+				for x := 0; x < tr.Width; x++ {
+					inside := x >= tt.x && x < tt.x+tt.w && y >= tt.y && y < tt.y+tt.h
+
+					switch tr.Get(x, y) {
+					case terrain.Room:
+						count++
+						if !inside {
+							t.Errorf("tile %d,%d outside the rectangle was changed", x, y)
+						}
+					case terrain.Stone:
+						if inside {
+							t.Errorf("tile %d,%d inside the rectangle was not changed", x, y)
+						}
+					}
+				}
+			}
+
+			if count != tt.expected {
+				t.Errorf("expected %d tiles to be set, got %d", tt.expected, count)
+			}
+		})
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	tr := terrain.NewTerrain(7, 5)
+	tr.SetRect(1, 1, 3, 3, terrain.Room)
+	tr.Set(4, 2, terrain.Door)
+	tr.Set(5, 2, terrain.Corridor)
+	tr.Set(2, 2, terrain.Water)
+
+	data, err := tr.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var loaded terrain.Terrain
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if loaded.Width != tr.Width || loaded.Height != tr.Height {
+		t.Fatalf("expected a %dx%d terrain, got %dx%d", tr.Width, tr.Height, loaded.Width, loaded.Height)
+	}
+
+	for y := 0; y < tr.Height; y++ {
+		for x := 0; x < tr.Width; x++ {
+			if loaded.Get(x, y) != tr.Get(x, y) {
+				t.Errorf("expected %d at %d,%d, got %d", tr.Get(x, y), x, y, loaded.Get(x, y))
+			}
+		}
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	tr := terrain.NewTerrain(3, 3)
+	data, _ := tr.MarshalBinary()
+
+	for _, bad := range [][]byte{nil, data[:len(data)-1], append(data, 0)} {
+		loaded := terrain.NewTerrain(1, 1)
+		if err := loaded.UnmarshalBinary(bad); err == nil {
+			t.Errorf("expected an error for %v", bad)
+		}
+
+		if loaded.Width != 1 || loaded.Height != 1 {
+			t.Errorf("a failed unmarshal should leave the terrain unchanged")
+		}
+	}
+}
+
+func TestEqualAndHash(t *testing.T) {
+	a := terrain.NewTerrain(5, 5)
+	b := terrain.NewTerrain(5, 5)
+	a.SetRect(1, 1, 3, 3, terrain.Room)
+	b.SetRect(1, 1, 3, 3, terrain.Room)
+
+	if !a.Equal(b) || a.Hash() != b.Hash() {
+		t.Error("expected identical terrain to be equal with the same hash")
+	}
+
+	b.Set(2, 2, terrain.Water)
+	if a.Equal(b) || a.Hash() == b.Hash() {
+		t.Error("expected terrain with one different tile to differ")
+	}
+}
+
+func TestNeighbors8(t *testing.T) {
+	tr := terrain.NewTerrain(3, 3)
+	tr.Set(1, 0, terrain.Room)
+	tr.Set(2, 1, terrain.Door)
+
+	expected := [8]terrain.Type{
+		terrain.OutOfBounds, terrain.OutOfBounds, terrain.Room, terrain.Stone,
+		terrain.Stone, terrain.OutOfBounds, terrain.OutOfBounds, terrain.OutOfBounds,
+	}
+	if got := tr.Neighbors8(0, 0); got != expected {
+		t.Errorf("expected the corner's neighbours to be %v, got %v", expected, got)
+	}
+
+	expected = [8]terrain.Type{
+		terrain.Room, terrain.Stone, terrain.Door, terrain.Stone,
+		terrain.Stone, terrain.Stone, terrain.Stone, terrain.Stone,
+	}
+	if got := tr.Neighbors8(1, 1); got != expected {
+		t.Errorf("expected the centre's neighbours to be %v, got %v", expected, got)
+	}
+}
+
+func TestWallMask8(t *testing.T) {
+	// a single open tile in the middle of the rock is surrounded by a ring of
+	// walls, and the rock beyond them isn't a wall at all.
+	ring := terrain.NewTerrain(5, 5)
+	ring.Set(2, 2, terrain.Room)
+
+	if !ring.IsWall(1, 1) || !ring.IsWall(2, 1) || ring.IsWall(0, 0) || ring.IsWall(2, 2) {
+		t.Error("expected only the ring around the open tile to be walls")
+	}
+
+	// two columns of wall between two open columns, so every wall has walls
+	// all around it on one side.
+	columns := terrain.NewTerrain(4, 4)
+	columns.SetRect(0, 0, 1, 4, terrain.Room)
+	columns.SetRect(3, 0, 1, 4, terrain.Room)
+
+	tests := []struct {
+		name     string
+		tr       *terrain.Terrain
+		x, y     int
+		expected uint8
+	}{
+		{"corner of the ring", ring, 1, 1, terrain.MaskEast | terrain.MaskSouth},
+		{"diagonals need both sides", ring, 2, 1, terrain.MaskEast | terrain.MaskWest},
+		{"surrounded", ring, 2, 2, 0xff},
+		{"solid rock", ring, 0, 0, 0},
+		{"diagonals count", columns, 1, 1, terrain.MaskNorth | terrain.MaskNorthEast | terrain.MaskEast | terrain.MaskSouthEast | terrain.MaskSouth},
+		{"edge of the map", columns, 1, 0, terrain.MaskEast | terrain.MaskSouthEast | terrain.MaskSouth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tr.WallMask8(tt.x, tt.y); got != tt.expected {
+				t.Errorf("expected WallMask8(%d, %d) to be %08b, got %08b", tt.x, tt.y, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCrop(t *testing.T) {
+	// a small room with a door in the bottom right corner of a big map
+	tr := terrain.NewTerrain(40, 30)
+	tr.SetRect(31, 21, 5, 3, terrain.Room)
+	tr.Set(36, 22, terrain.Door)
+
+	tests := []struct {
+		name             string
+		margin           int
+		offsetX, offsetY int
+		width, height    int
+	}{
+		{"no margin", 0, 31, 21, 6, 3},
+		{"margin", 2, 29, 19, 10, 7},
+		// the margin goes past the right edge, so it's padded with stone
+		{"wide margin", 5, 26, 16, 16, 13},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cropped, offsetX, offsetY := tr.Crop(tt.margin)
+
+			if offsetX != tt.offsetX || offsetY != tt.offsetY {
+				t.Errorf("expected offset %d,%d, got %d,%d", tt.offsetX, tt.offsetY, offsetX, offsetY)
+			}
+			if cropped.Width != tt.width || cropped.Height != tt.height {
+				t.Fatalf("expected %dx%d, got %dx%d", tt.width, tt.height, cropped.Width, cropped.Height)
+			}
+
+			// every tile is where the offset says it should be
+			for y := 0; y < cropped.Height; y++ {
+				for x := 0; x < cropped.Width; x++ {
+					if got, want := cropped.Get(x, y), tr.Get(x+offsetX, y+offsetY); got != want {
+						t.Errorf("expected %d at %d,%d, got %d", want, x, y, got)
+					}
+				}
+			}
+		})
+	}
+
+	if cropped, _, _ := terrain.NewTerrain(10, 10).Crop(1); cropped != nil {
+		t.Error("expected cropping solid stone to return nil")
+	}
+}
+
+func TestCountAndFind(t *testing.T) {
+	tr := terrain.NewTerrain(5, 3)
+	tr.SetRect(1, 1, 3, 1, terrain.Room)
+	tr.Set(4, 1, terrain.Door)
+	tr.Set(0, 1, terrain.Door)
+
+	if n := tr.Count(terrain.Room); n != 3 {
+		t.Errorf("expected 3 room tiles, got %d", n)
+	}
+	if n := tr.Count(terrain.Room, terrain.Door); n != 5 {
+		t.Errorf("expected 5 room and door tiles, got %d", n)
+	}
+
+	doors := tr.Find(terrain.Door)
+	if len(doors) != 2 || doors[0] != [2]int{0, 1} || doors[1] != [2]int{4, 1} {
+		t.Errorf("expected doors at 0,1 and 4,1, got %v", doors)
+	}
+}
+
+func TestFromString(t *testing.T) {
+	tr, err := terrain.FromString(`
+#####
+#.+ #
+#~%#^
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tr.Width != 5 || tr.Height != 3 {
+		t.Fatalf("expected 5x3, got %dx%d", tr.Width, tr.Height)
+	}
+	if tr.Get(1, 1) != terrain.Room || tr.Get(2, 1) != terrain.Door || tr.Get(3, 1) != terrain.Corridor ||
+		tr.Get(1, 2) != terrain.Water || tr.Get(2, 2) != terrain.Rubble || tr.Get(4, 2) != terrain.Trap {
+		t.Errorf("tiles weren't parsed as expected:\n%s", tr)
+	}
+
+	// String is the inverse of FromString
+	expected := "#####\n#.+ #\n#~%#^\n"
+	if tr.String() != expected {
+		t.Errorf("expected %q, got %q", expected, tr.String())
+	}
+	if again, err := terrain.FromString(tr.String()); err != nil || !again.Equal(tr) {
+		t.Errorf("expected a round trip to give the same terrain, got %v", err)
+	}
+}
+
+func TestFromStringInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		expected string
+	}{
+		{"empty", "", "no tiles"},
+		{"ragged", "###\n#.\n###", "line 2 is 2 tiles wide, but line 1 is 3"},
+		{"ragged after blank first line", "\n###\n##", "line 3 is 2 tiles wide, but line 2 is 3"},
+		{"unknown glyph", "###\n#x#\n###", "unknown glyph 'x' at line 2, column 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := terrain.FromString(tt.s)
+			if !errors.Is(err, terrain.ErrInvalidString) || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected an error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestEditBuffer(t *testing.T) {
+	tr := terrain.NewTerrain(10, 10)
+	buf := terrain.NewEditBuffer(tr)
+
+	changed := make([][2]int, 0)
+	buf.OnChange = func(x, y int) {
+		changed = append(changed, [2]int{x, y})
+	}
+
+	buf.SetTile(1, 1, terrain.Room)
+	buf.SetTile(1, 1, terrain.Room) // no change, so not an action
+	buf.SetTile(20, 20, terrain.Room)
+
+	// a grouped action, which changes one tile twice
+	buf.Begin()
+	buf.SetTile(2, 2, terrain.Corridor)
+	buf.Begin()
+	buf.SetTile(3, 3, terrain.Door)
+	buf.End()
+	buf.SetTile(2, 2, terrain.Water)
+	buf.End()
+
+	if tr.Get(1, 1) != terrain.Room || tr.Get(2, 2) != terrain.Water || tr.Get(3, 3) != terrain.Door {
+		t.Fatal("expected the edits to be made")
+	}
+	if len(changed) != 4 {
+		t.Errorf("expected 4 changes, got %v", changed)
+	}
+
+	// the whole group is undone at once
+	if !buf.Undo() {
+		t.Fatal("expected to undo the group")
+	}
+	if tr.Get(2, 2) != terrain.Stone || tr.Get(3, 3) != terrain.Stone {
+		t.Errorf("expected the group to be undone, got %v and %v", tr.Get(2, 2), tr.Get(3, 3))
+	}
+	if tr.Get(1, 1) != terrain.Room {
+		t.Error("expected the first edit to be left alone")
+	}
+	if changed = changed[4:]; len(changed) != 3 || changed[0] != [2]int{2, 2} {
+		t.Errorf("expected the group's changes in reverse, got %v", changed)
+	}
+
+	if !buf.Undo() || tr.Get(1, 1) != terrain.Stone {
+		t.Error("expected the first edit to be undone")
+	}
+	if buf.Undo() || buf.CanUndo() {
+		t.Error("expected nothing more to undo")
+	}
+
+	// redoing puts the group back the way it ended up
+	if !buf.Redo() || !buf.Redo() {
+		t.Fatal("expected to redo both actions")
+	}
+	if tr.Get(1, 1) != terrain.Room || tr.Get(2, 2) != terrain.Water || tr.Get(3, 3) != terrain.Door {
+		t.Error("expected the edits to be redone")
+	}
+	if buf.Redo() {
+		t.Error("expected nothing more to redo")
+	}
+
+	// a new edit throws away the redo history
+	buf.Undo()
+	buf.SetTile(5, 5, terrain.Room)
+	if buf.CanRedo() {
+		t.Error("expected a new edit to clear the redo history")
+	}
+
+	// an empty group isn't an action
+	buf.Begin()
+	buf.End()
+	if !buf.Undo() || tr.Get(5, 5) != terrain.Stone {
+		t.Error("expected the empty group to be skipped")
+	}
+}
+
+func TestEditBuffer_MaxUndo(t *testing.T) {
+	tr := terrain.NewTerrain(10, 1)
+	buf := terrain.NewEditBuffer(tr)
+	buf.MaxUndo = 3
+
+	for x := 0; x < 5; x++ {
+		buf.SetTile(x, 0, terrain.Room)
+	}
+
+	undone := 0
+	for buf.Undo() {
+		undone++
+	}
+
+	if undone != 3 {
+		t.Errorf("expected only 3 actions to be kept, got %d", undone)
+	}
+	if tr.Get(1, 0) != terrain.Room || tr.Get(2, 0) != terrain.Stone {
+		t.Errorf("expected the oldest actions to be forgotten, got %v and %v", tr.Get(1, 0), tr.Get(2, 0))
+	}
+}
+
+// BenchmarkCrop crops a large map that is mostly stone, which is dominated by
+// the scan for the tiles that aren't.
+func BenchmarkCrop(b *testing.B) {
+	tr := terrain.NewTerrain(255, 255)
+	tr.SetRect(100, 80, 40, 30, terrain.Room)
+
+	for i := 0; i < b.N; i++ {
+		tr.Crop(1)
+	}
+}
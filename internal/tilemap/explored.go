@@ -0,0 +1,109 @@
+package tilemap
+
+import "github.com/matjam/sword/internal/grid"
+
+// Flags stored for each tile of an Explored grid.
+const (
+	// ExploredSeen is set once a tile has been seen, and is never cleared.
+	ExploredSeen uint8 = 1 << iota
+	// ExploredVisible is set while a tile can be seen, and is cleared every
+	// time the FOV is computed again.
+	ExploredVisible
+)
+
+// Explored records which tiles of a map have been seen, and which can be seen
+// right now, separately from the tiles themselves. Every player (or level)
+// can have one of their own, and it can be saved without the rest of the map.
+//
+// Set Grid.Explored to have ComputeFOV write into it. The Seen and Visible
+// fields on each Tile are still kept up to date as well, for code that hasn't
+// moved over yet; ExploredFromTiles and CopyToTiles convert between the two.
+type Explored struct {
+	*grid.Grid[uint8]
+}
+
+// NewExplored creates an Explored grid of the given size, where nothing has
+// been seen yet.
+func NewExplored(width int, height int) *Explored {
+	return &Explored{Grid: grid.NewGrid[uint8](width, height)}
+}
+
+// ExploredFromTiles creates an Explored grid from the Seen and Visible fields
+// of the tiles in the map.
+func ExploredFromTiles(tm *Grid) *Explored {
+	e := NewExplored(tm.Width, tm.Height)
+	for i, tile := range tm.Tiles {
+		var flags uint8
+		if tile.Seen {
+			flags |= ExploredSeen
+		}
+		if tile.Visible {
+			flags |= ExploredVisible
+		}
+		e.Set(i%tm.Width, i/tm.Width, flags)
+	}
+	return e
+}
+
+// CopyToTiles sets the Seen and Visible fields of the tiles in the map from
+// the Explored grid. Tiles outside the Explored grid are left alone.
+func (e *Explored) CopyToTiles(tm *Grid) {
+	for y := 0; y < min(tm.Height, e.Height); y++ {
+		for x := 0; x < min(tm.Width, e.Width); x++ {
+			tile := tm.GetTile(x, y)
+			tile.Seen = e.IsSeen(x, y)
+			tile.Visible = e.IsVisible(x, y)
+		}
+	}
+}
+
+// IsSeen returns true if the tile at the given position has ever been seen.
+func (e *Explored) IsSeen(x int, y int) bool {
+	return e.Get(x, y)&ExploredSeen != 0
+}
+
+// IsVisible returns true if the tile at the given position can be seen now.
+func (e *Explored) IsVisible(x int, y int) bool {
+	return e.Get(x, y)&ExploredVisible != 0
+}
+
+// clearVisible clears ExploredVisible on every tile, ready for the FOV to be
+// computed again.
+func (e *Explored) clearVisible() {
+	for y := 0; y < e.Height; y++ {
+		for x := 0; x < e.Width; x++ {
+			e.Set(x, y, e.Get(x, y)&^ExploredVisible)
+		}
+	}
+}
+
+// see marks the tile at the given position as seen, and visible now.
+func (e *Explored) see(x int, y int) {
+	e.Set(x, y, e.Get(x, y)|ExploredSeen|ExploredVisible)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, in the same format as
+// grid.Grid.Marshal, with each tile encoded as its single byte of flags.
+func (e *Explored) MarshalBinary() ([]byte, error) {
+	return e.Marshal(func(flags uint8) []byte {
+		return []byte{flags}
+	}), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. The grid is resized
+// to match the data.
+func (e *Explored) UnmarshalBinary(data []byte) error {
+	g := grid.NewGrid[uint8](0, 0)
+	err := g.Unmarshal(data, func(b []byte) uint8 {
+		if len(b) == 0 {
+			return 0
+		}
+		return b[0]
+	})
+	if err != nil {
+		return err
+	}
+
+	e.Grid = g
+	return nil
+}
@@ -0,0 +1,179 @@
+package tilemap
+
+import "github.com/matjam/sword/internal/grid"
+
+// IsOpaque returns true if light can't pass through a tile of this type, so
+// that it blocks line of sight. Open doors let light through, just like the
+// floor, but closed doors don't.
+func (t TileType) IsOpaque() bool {
+	switch t {
+	case TileTypeWall, TileTypeClosedDoor:
+		return true
+	}
+	return false
+}
+
+// ComputeFOV works out which tiles can be seen from the given position, out
+// to the given radius. Every tile that can be seen is marked Visible and Seen,
+// and every other tile has Visible cleared. Opaque tiles such as walls are
+// marked visible when they're lit, but nothing behind them is.
+//
+// The grid remembers where the FOV was last computed from, so that it can be
+// recomputed when something changes what can be seen, such as a door being
+// opened or closed. See ToggleDoor.
+//
+// If Explored is set, the tiles are marked in it as well.
+func (tm *Grid) ComputeFOV(x int, y int, radius int) {
+	tm.fovX, tm.fovY, tm.fovRadius = x, y, radius
+	tm.fovComputed = true
+
+	for i := range tm.Tiles {
+		tm.Tiles[i].Visible = false
+	}
+	if tm.Explored != nil {
+		tm.Explored.clearVisible()
+	}
+
+	tm.shadowcast(x, y, radius, func(x, y int) {
+		tile := tm.GetTile(x, y)
+		tile.Visible = true
+		tile.Seen = true
+
+		if tm.Explored != nil {
+			tm.Explored.see(x, y)
+		}
+	})
+}
+
+// CanSee returns true if something at x1,y1 that can see out to the given
+// radius can see the tile at x2,y2. It uses the same range as ComputeFOV and
+// a line of sight check like IsVisible, so it's cheap enough to use for every
+// mob without working out everything they can see.
+func (tm *Grid) CanSee(x1 int, y1 int, x2 int, y2 int, radius int) bool {
+	if grid.EuclideanSquared(x1, y1, x2, y2) >= radius*radius {
+		return false
+	}
+	return tm.IsVisible(x1, y1, x2, y2)
+}
+
+// shadowcast calls visit for every tile that can be seen from the given
+// position, out to the given radius, including the position itself. Tiles
+// on the edges between octants may be visited more than once.
+func (tm *Grid) shadowcast(x int, y int, radius int, visit func(x, y int)) {
+	if tm.GetTile(x, y) == nil {
+		return
+	}
+	visit(x, y)
+
+	// This is recursive shadowcasting, as described at
+	// https://www.roguebasin.com/index.php/FOV_using_recursive_shadowcasting
+	//
+	// The area around the origin is split into eight octants. Each octant is
+	// scanned one row at a time, moving away from the origin, and whenever
+	// the scan runs into an opaque tile the part of the octant beyond it is
+	// scanned separately with a narrower slope. The multipliers turn the
+	// coordinates in the first octant into the coordinates in each of the
+	// others, so that one function can do the work for all eight.
+	for _, m := range octantMultipliers {
+		tm.castLight(x, y, 1, 1.0, 0.0, radius, m[0], m[1], m[2], m[3], visit)
+	}
+}
+
+var octantMultipliers = [8][4]int{
+	{1, 0, 0, 1},
+	{0, 1, 1, 0},
+	{0, -1, 1, 0},
+	{-1, 0, 0, 1},
+	{-1, 0, 0, -1},
+	{0, -1, -1, 0},
+	{0, 1, -1, 0},
+	{1, 0, 0, -1},
+}
+
+// castLight scans a single octant, starting at the given row, between the
+// start and end slopes.
+func (tm *Grid) castLight(cx, cy, row int, start, end float64, radius int, xx, xy, yx, yy int, visit func(x, y int)) {
+	if start < end {
+		return
+	}
+
+	radiusSquared := radius * radius
+
+	for j := row; j <= radius; j++ {
+		dx, dy := -j-1, -j
+		blocked := false
+		newStart := 0.0
+
+		for dx <= 0 {
+			dx++
+
+			// the slopes of the left and right edges of this tile
+			leftSlope := (float64(dx) - 0.5) / (float64(dy) + 0.5)
+			rightSlope := (float64(dx) + 0.5) / (float64(dy) - 0.5)
+
+			if start < rightSlope {
+				continue
+			}
+			if end > leftSlope {
+				break
+			}
+
+			tx, ty := cx+dx*xx+dy*xy, cy+dx*yx+dy*yy
+			tile := tm.GetTile(tx, ty)
+			if tile != nil && grid.EuclideanSquared(0, 0, dx, dy) < radiusSquared {
+				visit(tx, ty)
+			}
+
+			// anything outside the map blocks light, just like a wall
+			opaque := tile == nil || tile.Type.IsOpaque()
+
+			if blocked {
+				// we're scanning a run of opaque tiles
+				if opaque {
+					newStart = rightSlope
+					continue
+				}
+
+				blocked = false
+				start = newStart
+			} else if opaque && j < radius {
+				// this is the start of a run of opaque tiles, so scan the
+				// part of the octant that can still be seen past them
+				blocked = true
+				tm.castLight(cx, cy, j+1, start, leftSlope, radius, xx, xy, yx, yy, visit)
+				newStart = rightSlope
+			}
+		}
+
+		if blocked {
+			break
+		}
+	}
+}
+
+// ToggleDoor opens the door at the given position if it's closed, or closes
+// it if it's open. If the FOV has been computed, it is recomputed from the
+// same place, since opening or closing a door changes what can be seen.
+//
+// It returns false if there isn't a door at the given position.
+func (tm *Grid) ToggleDoor(x int, y int) bool {
+	tile := tm.GetTile(x, y)
+	if tile == nil {
+		return false
+	}
+
+	switch tile.Type {
+	case TileTypeClosedDoor:
+		tile.Type = TileTypeOpenDoor
+	case TileTypeOpenDoor:
+		tile.Type = TileTypeClosedDoor
+	default:
+		return false
+	}
+
+	if tm.fovComputed {
+		tm.ComputeFOV(tm.fovX, tm.fovY, tm.fovRadius)
+	}
+
+	return true
+}
@@ -0,0 +1,53 @@
+package tilemap
+
+import "math"
+
+// ClearLight turns off all of the light in the grid, ready for AddLight to be
+// called for every light source. Until it's first called the grid has no
+// lighting at all, and Brightness treats every tile as fully lit.
+func (tm *Grid) ClearLight() {
+	tm.lit = true
+	for i := range tm.Tiles {
+		tm.Tiles[i].LightLevel = 0
+	}
+}
+
+// AddLight adds the light from a light source at the given position to the
+// LightLevel of every tile it reaches. The light is brightest at the source,
+// and fades out to nothing at the given radius. It is blocked by walls and
+// closed doors in the same way as the FOV, so light doesn't leak into the
+// next room. Light from several sources adds up, to a maximum of 255.
+func (tm *Grid) AddLight(x int, y int, radius int, intensity uint8) {
+	// the octants overlap along their edges, so make sure each tile only gets
+	// the light once.
+	lit := make(map[int]bool)
+
+	tm.shadowcast(x, y, radius, func(tx, ty int) {
+		i := ty*tm.Width + tx
+		if lit[i] {
+			return
+		}
+		lit[i] = true
+
+		distance := math.Hypot(float64(tx-x), float64(ty-y))
+		light := int(float64(intensity) * max(0, 1-distance/float64(radius+1)))
+
+		tile := &tm.Tiles[i]
+		tile.LightLevel = uint8(min(int(tile.LightLevel)+light, math.MaxUint8))
+	})
+}
+
+// Brightness returns how brightly the tile at the given position should be
+// drawn, from 0.0 for pitch black to 1.0 for fully lit. If the light has
+// never been computed, every tile is fully lit.
+func (tm *Grid) Brightness(x int, y int) float32 {
+	if !tm.lit {
+		return 1
+	}
+
+	tile := tm.GetTile(x, y)
+	if tile == nil {
+		return 0
+	}
+	return float32(tile.LightLevel) / math.MaxUint8
+}
@@ -0,0 +1,74 @@
+package tilemap
+
+import (
+	"encoding/binary"
+
+	"github.com/matjam/sword/internal/grid"
+)
+
+// tile flags, packed into a single byte by MarshalBinary.
+const (
+	flagSeen = 1 << iota
+	flagVisible
+	flagRevealed
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler, in the same format as
+// grid.Grid.Marshal. Each tile is encoded as its Type, a byte of flags, its
+// LightLevel, and then its Region as a varint.
+func (tm *Grid) MarshalBinary() ([]byte, error) {
+	g := grid.NewGrid[Tile](tm.Width, tm.Height)
+	for i, tile := range tm.Tiles {
+		g.Set(i%tm.Width, i/tm.Width, tile)
+	}
+
+	return g.Marshal(func(t Tile) []byte {
+		var flags byte
+		if t.Seen {
+			flags |= flagSeen
+		}
+		if t.Visible {
+			flags |= flagVisible
+		}
+		if t.Revealed {
+			flags |= flagRevealed
+		}
+
+		return binary.AppendVarint([]byte{byte(t.Type), flags, t.LightLevel}, int64(t.Region))
+	}), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. The map is resized
+// to match the data, and the field of view is forgotten, so it is worked out
+// again the next time ComputeFOV is called.
+func (tm *Grid) UnmarshalBinary(data []byte) error {
+	g := grid.NewGrid[Tile](0, 0)
+	err := g.Unmarshal(data, func(b []byte) Tile {
+		if len(b) < 3 {
+			return Tile{Type: TileTypeWall}
+		}
+
+		region, _ := binary.Varint(b[3:])
+		return Tile{
+			Type:       TileType(b[0]),
+			Seen:       b[1]&flagSeen != 0,
+			Visible:    b[1]&flagVisible != 0,
+			Revealed:   b[1]&flagRevealed != 0,
+			LightLevel: b[2],
+			Region:     int(region),
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	tm.Width = g.Width
+	tm.Height = g.Height
+	tm.Tiles = make([]Tile, g.Width*g.Height)
+	for i := range tm.Tiles {
+		tm.Tiles[i] = g.Get(i%g.Width, i/g.Width)
+	}
+	tm.fovComputed = false
+
+	return nil
+}
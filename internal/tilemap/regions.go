@@ -0,0 +1,70 @@
+package tilemap
+
+// LabelRegions flood fills every connected area of passable tiles and writes
+// a region id into each tile's Region field, so that gameplay code can cheaply
+// check whether two tiles are in the same area. Region ids start at 1. Walls,
+// and anything else that isn't part of a region, get 0.
+//
+// If doorsSeparate is true, closed doors split the areas on either side into
+// separate regions and are given region 0 themselves. Otherwise closed doors
+// are treated as passable and join the areas together.
+//
+// It returns the number of regions found.
+func (tm *Grid) LabelRegions(doorsSeparate bool) int {
+	for i := range tm.Tiles {
+		tm.Tiles[i].Region = 0
+	}
+
+	regions := 0
+	queue := make([]int, 0)
+
+	for i := range tm.Tiles {
+		if tm.Tiles[i].Region != 0 || !isConnected(tm.Tiles[i].Type, doorsSeparate) {
+			continue
+		}
+
+		regions++
+		tm.Tiles[i].Region = regions
+		queue = append(queue[:0], i)
+
+		for len(queue) > 0 {
+			x, y := queue[0]%tm.Width, queue[0]/tm.Width
+			queue = queue[1:]
+
+			for _, n := range [][2]int{{x, y - 1}, {x + 1, y}, {x, y + 1}, {x - 1, y}} {
+				tile := tm.GetTile(n[0], n[1])
+				if tile == nil || tile.Region != 0 || !isConnected(tile.Type, doorsSeparate) {
+					continue
+				}
+
+				tile.Region = regions
+				queue = append(queue, n[1]*tm.Width+n[0])
+			}
+		}
+	}
+
+	return regions
+}
+
+// RegionOf returns the region of the tile at the given position, as labelled
+// by LabelRegions. It returns 0 if the position is outside the bounds of the
+// map, or the tile isn't part of a region.
+func (tm *Grid) RegionOf(x int, y int) int {
+	tile := tm.GetTile(x, y)
+	if tile == nil {
+		return 0
+	}
+	return tile.Region
+}
+
+// isConnected returns true if a tile of the given type joins the tiles around
+// it into a region.
+func isConnected(t TileType, doorsSeparate bool) bool {
+	switch t {
+	case TileTypeWall:
+		return false
+	case TileTypeClosedDoor:
+		return !doorsSeparate
+	}
+	return true
+}
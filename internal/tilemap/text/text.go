@@ -4,7 +4,10 @@ package text
 // a given Grid using the font given to it.
 
 import (
+	"image"
 	"image/color"
+	"log/slog"
+	"slices"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text"
@@ -13,55 +16,260 @@ import (
 	"golang.org/x/image/font"
 )
 
+// RememberedBrightness is how brightly tiles that have been seen, but can't be
+// seen right now, are drawn, when the tilemap has an Explored grid.
+const RememberedBrightness = 0.4
+
+// Glyph is the rune and color used to draw a type of tile.
+type Glyph struct {
+	Rune  rune
+	Color color.Color
+}
+
 type Renderer struct {
 	// The tilemap to render
 	tilemap *tilemap.Grid
 	// The font to use for rendering
 	tilefont font.Face
-	// The size of the font
-	size int
+	// The size of each cell, and the distance from the top of a cell to the
+	// baseline of the glyph in it
+	cellWidth  int
+	cellHeight int
+	ascent     int
+	// The glyph to draw for each type of tile
+	glyphs map[tilemap.TileType]Glyph
+
+	// cached is true if the tiles are drawn to cache, and only redrawn when
+	// they change, instead of every frame. cells is what was last drawn to
+	// each tile of the cache, row by row, and cacheViewport is the part of
+	// the tilemap it holds. See NewCachedRenderer.
+	cached        bool
+	cache         *ebiten.Image
+	cells         []cell
+	cacheViewport tilemap.Rectangle
 }
 
-func NewRenderer(tilemap *tilemap.Grid, fontName string) tilemap.Renderer {
-	return &Renderer{
-		tilemap:  tilemap,
+// cell is what is drawn in a single tile.
+type cell struct {
+	rune  rune
+	color color.Color
+}
+
+// NewRenderer creates a renderer for the tilemap using the named font. glyphs
+// overrides the glyph drawn for any of the tile types, for example to draw
+// walls as '#' instead of '█'. Any tile type that isn't in glyphs, or glyphs
+// being nil, uses the default glyph, and a Glyph with a nil Color is drawn in
+// white. A warning is logged for any rune that isn't in the font.
+func NewRenderer(tm *tilemap.Grid, fontName string, glyphs map[tilemap.TileType]Glyph) tilemap.Renderer {
+	r := &Renderer{
+		tilemap:  tm,
 		tilefont: assets.GetFont(fontName),
-		size:     assets.GetFontSize(fontName),
+		glyphs:   make(map[tilemap.TileType]Glyph),
+	}
+
+	r.cellWidth, r.cellHeight = assets.GetFontCellSize(fontName)
+	r.ascent = r.tilefont.Metrics().Ascent.Ceil()
+
+	for tileType, glyph := range defaultGlyphs {
+		r.glyphs[tileType] = glyph
 	}
+
+	for tileType, glyph := range glyphs {
+		if glyph.Color == nil {
+			glyph.Color = color.White
+		}
+
+		if _, ok := r.tilefont.GlyphAdvance(glyph.Rune); !ok {
+			slog.Warn("font has no glyph for rune", "font", fontName, "tile", tileType, "rune", string(glyph.Rune))
+		}
+
+		r.glyphs[tileType] = glyph
+	}
+
+	return r
+}
+
+// NewCachedRenderer creates a renderer like NewRenderer, except that it draws
+// the tiles to an offscreen image and only redraws the rows that have changed
+// since the last frame, including any that have been dimmed or lit up. A map
+// that hardly changes then costs a single DrawImage a frame, rather than a
+// text.Draw for every run of tiles. Moving the viewport redraws everything.
+func NewCachedRenderer(tm *tilemap.Grid, fontName string, glyphs map[tilemap.TileType]Glyph) tilemap.Renderer {
+	r := NewRenderer(tm, fontName, glyphs).(*Renderer)
+	r.cached = true
+	return r
+}
+
+// Invalidate makes a cached renderer redraw every tile on the next frame,
+// for when something has changed that it can't see in the tiles themselves.
+func (r *Renderer) Invalidate() {
+	r.cells = nil
 }
 
 // Draw the tilemap to the given destination image. The viewport is the
 // rectangle of the tilemap to render.
 func (r *Renderer) Draw(dst *ebiten.Image, x int, y int, viewport tilemap.Rectangle) {
+	if r.cached {
+		r.drawCached(dst, x, y, viewport)
+		return
+	}
+
 	// Iterate over the tiles in the viewport, and write them to the destination,
 	// line by line. We use the tilemap's width to calculate the position of the
-	// tile in the tilemap.
+	// tile in the tilemap. Each run of tiles with the same color is drawn in a
+	// single call.
+
+	row := make([]cell, viewport.Width)
+
+	for ty := viewport.Y; ty < viewport.Y+viewport.Height; ty++ {
+		r.readRow(row, viewport.X, ty)
+		r.drawRow(dst, x, y+(ty-viewport.Y)*r.cellHeight, row)
+
+		// it doesn't matter if we don't clear the row, because we're going to
+		// overwrite it anyway.
+	}
+}
+
+// drawCached draws the tiles to the cache, redrawing only the rows that have
+// changed, and then draws the cache to dst.
+func (r *Renderer) drawCached(dst *ebiten.Image, x int, y int, viewport tilemap.Rectangle) {
+	if viewport.Width <= 0 || viewport.Height <= 0 {
+		return
+	}
+
+	if viewport != r.cacheViewport {
+		r.cacheViewport = viewport
+		r.cells = nil
+
+		if r.cache != nil {
+			r.cache.Dispose()
+		}
+		r.cache = ebiten.NewImage(viewport.Width*r.cellWidth, viewport.Height*r.cellHeight)
+	}
 
-	row := make([]rune, viewport.Width)
-	destY := y
+	// cells is nil whenever everything needs to be redrawn
+	redrawAll := r.cells == nil
+	if redrawAll {
+		r.cells = make([]cell, viewport.Width*viewport.Height)
+	}
+
+	row := make([]cell, viewport.Width)
+	for ty := 0; ty < viewport.Height; ty++ {
+		r.readRow(row, viewport.X, viewport.Y+ty)
+
+		cached := r.cells[ty*viewport.Width : (ty+1)*viewport.Width]
+		if !redrawAll && slices.Equal(row, cached) {
+			continue
+		}
+		copy(cached, row)
+
+		rowImage := r.cache.SubImage(image.Rect(0, ty*r.cellHeight, r.cache.Bounds().Dx(), (ty+1)*r.cellHeight)).(*ebiten.Image)
+		rowImage.Clear()
+		r.drawRow(rowImage, 0, ty*r.cellHeight, row)
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(x), float64(y))
+	dst.DrawImage(r.cache, op)
+}
+
+// readRow fills row with what should be drawn for the tiles starting at x, y.
+// Tiles outside the tilemap are left blank, and so are tiles that haven't been
+// seen, if the tilemap has an Explored grid.
+func (r *Renderer) readRow(row []cell, x, y int) {
+	explored := r.tilemap.Explored
 
-	for y := viewport.Y; y < viewport.Y+viewport.Height; y++ {
-		for x := viewport.X; x < viewport.X+viewport.Width; x++ {
-			tile := r.tilemap.GetTile(x, y)
-			if tile == nil {
+	for i := range row {
+		row[i] = cell{}
+
+		tile := r.tilemap.GetTile(x+i, y)
+		if tile == nil {
+			continue
+		}
+
+		brightness := r.tilemap.Brightness(x+i, y)
+		if explored != nil {
+			if !explored.IsSeen(x+i, y) {
 				continue
 			}
+			if !explored.IsVisible(x+i, y) {
+				brightness = min(brightness, RememberedBrightness)
+			}
+		}
 
-			row[x-viewport.X] = tileTypeToRune[tile.Type]
+		// unrevealed traps look just like the floor around them
+		tileType := tile.Type
+		if tileType == tilemap.TileTypeTrap && !tile.Revealed {
+			tileType = tilemap.TileTypeFloor
 		}
-		text.Draw(dst, string(row), r.tilefont, x, destY, color.White)
-		destY += r.size - 1
 
-		// it doesn't matter if we don't clear the row, because we're going to
-		// overwrite it anyway.
+		glyph := r.glyphs[tileType]
+		row[i] = cell{
+			rune:  glyph.Rune,
+			color: dim(glyph.Color, brightness),
+		}
 	}
 }
 
-var tileTypeToRune = map[tilemap.TileType]rune{
-	tilemap.TileTypeWall:       '█',
-	tilemap.TileTypeClosedDoor: '▒',
-	tilemap.TileTypeOpenDoor:   '░',
-	tilemap.TileTypeFloor:      ' ',
-	tilemap.TileTypeStairsUp:   '<',
-	tilemap.TileTypeStairsDown: '>',
+// drawRow draws a row of tiles with its top left corner at x, y. Each run of
+// tiles with the same color is drawn in a single call.
+func (r *Renderer) drawRow(dst *ebiten.Image, x, y int, row []cell) {
+	runes := make([]rune, len(row))
+	for i, c := range row {
+		runes[i] = c.rune
+	}
+
+	// text.Draw takes the position of the baseline, not the top of the text
+	baseline := y + r.ascent
+
+	start := 0
+	for end := 1; end <= len(row); end++ {
+		if end < len(row) && row[end].color == row[start].color {
+			continue
+		}
+
+		clr := row[start].color
+		if clr == nil {
+			clr = color.White
+		}
+
+		text.Draw(dst, string(runes[start:end]), r.tilefont, x+start*r.cellWidth, baseline, clr)
+		start = end
+	}
+}
+
+// dim darkens the color to the given brightness, for tiles that aren't fully
+// lit.
+func dim(clr color.Color, brightness float32) color.Color {
+	if brightness >= 1 {
+		return clr
+	}
+	if clr == nil {
+		clr = color.White
+	}
+
+	// the components are premultiplied by alpha, so scaling them all keeps
+	// the color valid.
+	r, g, b, a := clr.RGBA()
+	return color.RGBA64{
+		R: uint16(float32(r) * brightness),
+		G: uint16(float32(g) * brightness),
+		B: uint16(float32(b) * brightness),
+		A: uint16(a),
+	}
+}
+
+// CellSize returns the size in pixels of a single tile when drawn.
+func (r *Renderer) CellSize() (width, height int) {
+	return r.cellWidth, r.cellHeight
+}
+
+var defaultGlyphs = map[tilemap.TileType]Glyph{
+	tilemap.TileTypeWall:       {'█', color.White},
+	tilemap.TileTypeClosedDoor: {'▒', color.White},
+	tilemap.TileTypeOpenDoor:   {'░', color.White},
+	tilemap.TileTypeFloor:      {' ', color.White},
+	tilemap.TileTypeStairsUp:   {'<', color.White},
+	tilemap.TileTypeStairsDown: {'>', color.White},
+	tilemap.TileTypeTrap:       {'^', color.RGBA{R: 255, G: 64, B: 64, A: 255}},
 }
@@ -15,6 +15,10 @@ import (
 type Renderer interface {
 	// Draw is called every frame to draw the grid to the screen.
 	Draw(dst *ebiten.Image, x int, y int, viewport Rectangle)
+
+	// CellSize returns the size in pixels of a single tile when drawn, so
+	// that screen positions can be converted to tiles and back.
+	CellSize() (width, height int)
 }
 
 type Rectangle struct {
@@ -24,17 +28,19 @@ type Rectangle struct {
 	Height int
 }
 
-// ENUM(wall, closed_door, open_door, floor, stairs_up, stairs_down)
+// ENUM(wall, closed_door, open_door, floor, stairs_up, stairs_down, trap)
 type TileType uint8
 
 // Tile is a single tile in a grid. The Tile struct holds information about
 // whether the tile has been seen by the player, and what region it belongs to
-// which is used during map generation.
+// which is used during map generation. Revealed is only used by traps, which
+// look like ordinary floor until they have been revealed.
 type Tile struct {
 	Type       TileType
 	Region     int
 	Seen       bool
 	Visible    bool
+	Revealed   bool
 	LightLevel uint8
 }
 
@@ -45,6 +51,19 @@ type Grid struct {
 	Width  int
 	Height int
 	Tiles  []Tile
+
+	// Explored, if set, is where ComputeFOV records which tiles have been
+	// seen, as well as on the tiles themselves. Only the text renderer reads
+	// it so far, and it isn't saved with the map; use ExploredFromTiles to
+	// rebuild it from the tiles after loading.
+	Explored *Explored
+
+	// where the FOV was last computed from, see ComputeFOV
+	fovX, fovY, fovRadius int
+	fovComputed           bool
+
+	// lit is true once the light has been computed, see ClearLight
+	lit bool
 }
 
 // NewGrid creates a new Grid with the given width and height.
@@ -112,10 +131,10 @@ func (tm *Grid) IsVisible(x1 int, y1 int, x2 int, y2 int) bool {
 		return false
 	}
 
-	// check every tile between the two tiles to see if they are walls or
-	// closed doors. If they are, we return false.
+	// check every tile between the two tiles to see if they are opaque, such
+	// as walls or closed doors. If they are, we return false.
 	for _, tile := range tm.GetTilesBetween(x1, y1, x2, y2) {
-		if tile.Type == TileTypeWall || tile.Type == TileTypeClosedDoor {
+		if tile.Type.IsOpaque() {
 			return false
 		}
 	}
@@ -124,14 +143,97 @@ func (tm *Grid) IsVisible(x1 int, y1 int, x2 int, y2 int) bool {
 	return true
 }
 
-// GetTilesBetween returns a slice of tiles between the two given positions.
-// Obviously this needs to use some cool vector math to work out what tiles are
-// between the two positions. This uses the Bresenham's line algorithm to
-// calculate the tiles between the two positions.
+// GetTilesBetween returns a slice of tiles between the two given positions,
+// including both ends. Any part of the line that is outside the bounds of the
+// map is left out. See line() for how the tiles are picked.
 func (tm *Grid) GetTilesBetween(x1 int, y1 int, x2 int, y2 int) []Tile {
 	// We create a slice of tiles to hold the tiles between the two positions.
 	tiles := []Tile{}
 
+	line(x1, y1, x2, y2, func(x, y int) bool {
+		// If the tile is not nil, we append it to the slice of tiles.
+		if tile := tm.GetTile(x, y); tile != nil {
+			tiles = append(tiles, *tile)
+		}
+		return true
+	})
+
+	// We return the slice of tiles.
+	return tiles
+}
+
+// RayCast follows the line from the first position towards the second, and
+// stops at the first tile that can't be passed, such as a wall or a closed
+// door, or the edge of the map. It returns the last passable tile on the line,
+// and whether the line was blocked before it reached the second position. The
+// first position itself is never checked, since that's where the line starts.
+//
+// This is what thrown items and ranged attacks use to work out where they
+// land.
+func (tm *Grid) RayCast(x1 int, y1 int, x2 int, y2 int) (hitX int, hitY int, blocked bool) {
+	hitX, hitY = x1, y1
+
+	line(x1, y1, x2, y2, func(x, y int) bool {
+		if x == x1 && y == y1 {
+			return true
+		}
+
+		tile := tm.GetTile(x, y)
+		if tile == nil || !tile.Type.IsPassable() {
+			blocked = true
+			return false
+		}
+
+		hitX, hitY = x, y
+		return true
+	})
+
+	return hitX, hitY, blocked
+}
+
+// IsPassable returns true if an entity can move through a tile of this type.
+func (t TileType) IsPassable() bool {
+	switch t {
+	case TileTypeWall, TileTypeClosedDoor:
+		return false
+	}
+	return true
+}
+
+// CanStep returns true if an entity standing at the given position can take a
+// single step by dx,dy, where dx and dy are each -1, 0 or 1. The tile being
+// stepped onto has to be passable, and a diagonal step isn't allowed to
+// squeeze between two wall corners: at least one of the two tiles beside the
+// diagonal has to be passable too.
+func (tm *Grid) CanStep(x int, y int, dx int, dy int) bool {
+	if dx == 0 && dy == 0 {
+		return true
+	}
+
+	if !tm.isPassable(x+dx, y+dy) {
+		return false
+	}
+
+	if dx != 0 && dy != 0 {
+		return tm.isPassable(x+dx, y) || tm.isPassable(x, y+dy)
+	}
+
+	return true
+}
+
+// isPassable returns true if the tile at the given position exists and can be
+// moved through.
+func (tm *Grid) isPassable(x int, y int) bool {
+	tile := tm.GetTile(x, y)
+	return tile != nil && tile.Type.IsPassable()
+}
+
+// line calls visit for every position on the line between the two given
+// positions, starting with the first and ending with the second, until visit
+// returns false. Obviously this needs to use some cool vector math to work
+// out what positions are between the two ends. This uses the Bresenham's
+// line algorithm to calculate them.
+func line(x1 int, y1 int, x2 int, y2 int, visit func(x, y int) bool) {
 	// We calculate the difference between the two positions.
 	dx := x2 - x1
 	dy := y2 - y1
@@ -150,17 +252,13 @@ func (tm *Grid) GetTilesBetween(x1 int, y1 int, x2 int, y2 int) []Tile {
 
 	// We loop until we reach the second position.
 	for {
-		// We get the tile at the first position.
-		tile := tm.GetTile(x1, y1)
-
-		// If the tile is not nil, we append it to the slice of tiles.
-		if tile != nil {
-			tiles = append(tiles, *tile)
+		if !visit(x1, y1) {
+			return
 		}
 
 		// If we have reached the second position, we break out of the loop.
 		if x1 == x2 && y1 == y2 {
-			break
+			return
 		}
 
 		// We calculate the error2.
@@ -185,9 +283,6 @@ func (tm *Grid) GetTilesBetween(x1 int, y1 int, x2 int, y2 int) []Tile {
 			y1 += sy
 		}
 	}
-
-	// We return the slice of tiles.
-	return tiles
 }
 
 func abs(x int) int {
@@ -214,6 +309,7 @@ func sign(x int) int {
 // floors are .
 // stairs up are <
 // stairs down are >
+// traps are ^, or . if they haven't been revealed
 func (tm *Grid) Dump() {
 	for y := 0; y < tm.Height; y++ {
 		for x := 0; x < tm.Width; x++ {
@@ -234,6 +330,12 @@ func (tm *Grid) Dump() {
 				fmt.Printf("<")
 			case TileTypeStairsDown:
 				fmt.Printf(">")
+			case TileTypeTrap:
+				if tile.Revealed {
+					fmt.Printf("^")
+				} else {
+					fmt.Printf(".")
+				}
 			}
 		}
 		fmt.Println()
@@ -24,11 +24,13 @@ const (
 	TileTypeStairsUp
 	// TileTypeStairsDown is a TileType of type Stairs_down.
 	TileTypeStairsDown
+	// TileTypeTrap is a TileType of type Trap.
+	TileTypeTrap
 )
 
 var ErrInvalidTileType = errors.New("not a valid TileType")
 
-const _TileTypeName = "wallclosed_dooropen_doorfloorstairs_upstairs_down"
+const _TileTypeName = "wallclosed_dooropen_doorfloorstairs_upstairs_downtrap"
 
 var _TileTypeMap = map[TileType]string{
 	TileTypeWall:       _TileTypeName[0:4],
@@ -37,6 +39,7 @@ var _TileTypeMap = map[TileType]string{
 	TileTypeFloor:      _TileTypeName[24:29],
 	TileTypeStairsUp:   _TileTypeName[29:38],
 	TileTypeStairsDown: _TileTypeName[38:49],
+	TileTypeTrap:       _TileTypeName[49:53],
 }
 
 // String implements the Stringer interface.
@@ -61,6 +64,7 @@ var _TileTypeValue = map[string]TileType{
 	_TileTypeName[24:29]: TileTypeFloor,
 	_TileTypeName[29:38]: TileTypeStairsUp,
 	_TileTypeName[38:49]: TileTypeStairsDown,
+	_TileTypeName[49:53]: TileTypeTrap,
 }
 
 // ParseTileType attempts to convert a string to a TileType.
@@ -1,6 +1,7 @@
 package tilemap_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/matjam/sword/internal/tilemap"
@@ -63,3 +64,296 @@ func TestIsVisible(t *testing.T) {
 		t.Errorf("expected tile to not be visible")
 	}
 }
+
+// twoRooms returns a map with two 3x3 rooms joined by a closed door at 4,2.
+func twoRooms() *tilemap.Grid {
+	tm := tilemap.NewGrid(9, 5)
+	for y := 1; y < 4; y++ {
+		for x := 1; x < 8; x++ {
+			if x != 4 {
+				tm.SetTile(x, y, &tilemap.Tile{Type: tilemap.TileTypeFloor})
+			}
+		}
+	}
+	tm.SetTile(4, 2, &tilemap.Tile{Type: tilemap.TileTypeClosedDoor})
+	return tm
+}
+
+func TestLabelRegionsDoorsConnect(t *testing.T) {
+	tm := twoRooms()
+
+	if regions := tm.LabelRegions(false); regions != 1 {
+		t.Errorf("expected 1 region, got %d", regions)
+	}
+
+	if tm.RegionOf(1, 1) == 0 || tm.RegionOf(1, 1) != tm.RegionOf(7, 3) {
+		t.Errorf("expected both rooms to be in the same region, got %d and %d", tm.RegionOf(1, 1), tm.RegionOf(7, 3))
+	}
+
+	if tm.RegionOf(4, 2) != tm.RegionOf(1, 1) {
+		t.Errorf("expected the door to be in the same region as the rooms")
+	}
+}
+
+func TestLabelRegionsDoorsSeparate(t *testing.T) {
+	tm := twoRooms()
+
+	if regions := tm.LabelRegions(true); regions != 2 {
+		t.Errorf("expected 2 regions, got %d", regions)
+	}
+
+	if tm.RegionOf(1, 1) == 0 || tm.RegionOf(7, 3) == 0 || tm.RegionOf(1, 1) == tm.RegionOf(7, 3) {
+		t.Errorf("expected the rooms to be in different regions, got %d and %d", tm.RegionOf(1, 1), tm.RegionOf(7, 3))
+	}
+
+	if tm.RegionOf(4, 2) != 0 {
+		t.Errorf("expected the door to have no region, got %d", tm.RegionOf(4, 2))
+	}
+
+	if tm.RegionOf(0, 0) != 0 || tm.RegionOf(-1, 0) != 0 {
+		t.Errorf("expected walls and out of bounds tiles to have no region")
+	}
+}
+
+func TestCanSee(t *testing.T) {
+	tm := twoRooms()
+
+	if !tm.CanSee(1, 2, 3, 2, 5) {
+		t.Error("expected to see across the room")
+	}
+	if tm.CanSee(3, 2, 5, 2, 5) {
+		t.Error("expected the closed door to block sight")
+	}
+
+	tm.ToggleDoor(4, 2)
+	if !tm.CanSee(3, 2, 7, 2, 5) {
+		t.Error("expected to see through the open door")
+	}
+	if tm.CanSee(1, 2, 7, 2, 5) || !tm.CanSee(1, 2, 7, 2, 7) {
+		t.Error("expected the radius to limit how far can be seen")
+	}
+}
+
+func TestComputeFOVDoors(t *testing.T) {
+	tm := twoRooms()
+	tm.ComputeFOV(2, 2, 10)
+
+	if !tm.GetTile(1, 1).Visible || !tm.GetTile(4, 2).Visible {
+		t.Errorf("expected the near room and the door to be visible")
+	}
+	if tm.GetTile(6, 2).Visible || tm.GetTile(6, 2).Seen {
+		t.Errorf("expected the far room to be hidden behind the closed door")
+	}
+
+	// opening the door lets the light through to the far room
+	if !tm.ToggleDoor(4, 2) {
+		t.Fatal("expected the door to be toggled")
+	}
+	if tm.GetTile(4, 2).Type != tilemap.TileTypeOpenDoor {
+		t.Errorf("expected the door to be open")
+	}
+	if !tm.GetTile(6, 2).Visible || !tm.GetTile(7, 2).Visible {
+		t.Errorf("expected the far room to be visible through the open door")
+	}
+
+	// closing it again hides the far room, but it's been seen now
+	tm.ToggleDoor(4, 2)
+	if tm.GetTile(6, 2).Visible {
+		t.Errorf("expected the far room to be hidden again once the door is closed")
+	}
+	if !tm.GetTile(6, 2).Seen {
+		t.Errorf("expected the far room to be remembered as seen")
+	}
+
+	if tm.ToggleDoor(1, 1) {
+		t.Errorf("expected toggling a floor tile to do nothing")
+	}
+}
+
+func TestRayCast(t *testing.T) {
+	tests := []struct {
+		name           string
+		x1, y1, x2, y2 int
+		hitX, hitY     int
+		blocked        bool
+	}{
+		{"clear line", 1, 1, 3, 3, 3, 3, false},
+		{"clear line through an open door", 1, 2, 7, 2, 7, 2, false},
+		{"blocked by a wall", 2, 1, 6, 1, 3, 1, true},
+		{"adjacent", 1, 1, 2, 1, 2, 1, false},
+		{"adjacent wall", 3, 1, 4, 1, 3, 1, true},
+		{"same tile", 1, 1, 1, 1, 1, 1, false},
+	}
+
+	tm := twoRooms()
+	tm.ToggleDoor(4, 2)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hitX, hitY, blocked := tm.RayCast(tt.x1, tt.y1, tt.x2, tt.y2)
+			if hitX != tt.hitX || hitY != tt.hitY || blocked != tt.blocked {
+				t.Errorf("expected %d,%d blocked %v, got %d,%d blocked %v", tt.hitX, tt.hitY, tt.blocked, hitX, hitY, blocked)
+			}
+		})
+	}
+
+	// the edge of the map blocks the line just like a wall
+	open := tilemap.NewGrid(3, 1)
+	for x := 0; x < 3; x++ {
+		open.SetTile(x, 0, &tilemap.Tile{Type: tilemap.TileTypeFloor})
+	}
+	if hitX, hitY, blocked := open.RayCast(0, 0, 5, 0); hitX != 2 || hitY != 0 || !blocked {
+		t.Errorf("expected the edge of the map to block at 2,0, got %d,%d blocked %v", hitX, hitY, blocked)
+	}
+}
+
+func TestAddLight(t *testing.T) {
+	tm := twoRooms()
+
+	if tm.Brightness(1, 1) != 1 {
+		t.Error("expected every tile to be fully lit before the light is computed")
+	}
+
+	tm.ClearLight()
+	tm.AddLight(2, 2, 4, 200)
+
+	source := tm.GetTile(2, 2).LightLevel
+	if source != 200 {
+		t.Errorf("expected the source to be lit at full intensity, got %d", source)
+	}
+
+	corner := tm.GetTile(1, 1).LightLevel
+	if corner == 0 || corner >= source {
+		t.Errorf("expected the light to fade with distance, got %d at the source and %d in the corner", source, corner)
+	}
+
+	// the closed door keeps the light out of the other room
+	if level := tm.GetTile(6, 2).LightLevel; level != 0 {
+		t.Errorf("expected the other room to be dark, got %d", level)
+	}
+	if tm.Brightness(6, 2) != 0 {
+		t.Errorf("expected the other room to be drawn dark, got %f", tm.Brightness(6, 2))
+	}
+
+	// a second light adds to the first, up to the maximum
+	tm.AddLight(2, 2, 4, 200)
+	if level := tm.GetTile(2, 2).LightLevel; level != 255 {
+		t.Errorf("expected overlapping lights to be capped at 255, got %d", level)
+	}
+	if level, expected := int(tm.GetTile(1, 1).LightLevel), min(int(corner)*2, 255); level != expected {
+		t.Errorf("expected overlapping lights to add up to %d, got %d", expected, level)
+	}
+
+	tm.ClearLight()
+	if level := tm.GetTile(2, 2).LightLevel; level != 0 {
+		t.Errorf("expected the light to be cleared, got %d", level)
+	}
+}
+
+func TestCanStep(t *testing.T) {
+	tests := []struct {
+		name     string
+		x, y     int
+		dx, dy   int
+		expected bool
+	}{
+		{"orthogonal", 1, 1, 1, 0, true},
+		{"diagonal", 1, 1, 1, 1, true},
+		{"into a wall", 1, 1, -1, 0, false},
+		{"diagonally into a wall", 1, 1, -1, -1, false},
+		{"into an open door", 3, 2, 1, 0, true},
+		{"not moving", 1, 1, 0, 0, true},
+		{"diagonally past one corner", 3, 1, 1, 1, true},
+		{"diagonally between two corners", 3, 3, 1, 1, false},
+		{"off the map", 7, 3, 1, 1, false},
+	}
+
+	tm := twoRooms()
+	tm.ToggleDoor(4, 2)
+
+	// a floor tile that only touches the left hand room at its corner, with
+	// walls on both sides of the diagonal
+	tm.SetTile(4, 4, &tilemap.Tile{Type: tilemap.TileTypeFloor})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tm.CanStep(tt.x, tt.y, tt.dx, tt.dy); got != tt.expected {
+				t.Errorf("expected CanStep(%d, %d, %d, %d) to be %v, got %v", tt.x, tt.y, tt.dx, tt.dy, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	tm := tilemap.NewGrid(4, 3)
+	tm.SetTile(1, 1, &tilemap.Tile{Type: tilemap.TileTypeFloor, Region: 300, Seen: true, LightLevel: 128})
+	tm.SetTile(2, 1, &tilemap.Tile{Type: tilemap.TileTypeTrap, Region: -1, Visible: true, Revealed: true})
+
+	data, err := tm.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := tilemap.NewGrid(1, 1)
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Width != 4 || loaded.Height != 3 {
+		t.Fatalf("expected a 4x3 map, got %dx%d", loaded.Width, loaded.Height)
+	}
+	if !reflect.DeepEqual(loaded.Tiles, tm.Tiles) {
+		t.Errorf("expected the tiles to match, got %+v", loaded.Tiles)
+	}
+
+	if err := loaded.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Error("expected truncated data to fail")
+	}
+}
+
+func TestExplored(t *testing.T) {
+	tm := twoRooms()
+	tm.Explored = tilemap.NewExplored(tm.Width, tm.Height)
+	tm.ComputeFOV(2, 2, 10)
+	tm.ToggleDoor(4, 2)
+	tm.ToggleDoor(4, 2)
+
+	// the explored grid agrees with the tiles
+	for y := 0; y < tm.Height; y++ {
+		for x := 0; x < tm.Width; x++ {
+			tile := tm.GetTile(x, y)
+			if tm.Explored.IsSeen(x, y) != tile.Seen || tm.Explored.IsVisible(x, y) != tile.Visible {
+				t.Errorf("tile %d,%d is seen %v visible %v, but explored says %v %v",
+					x, y, tile.Seen, tile.Visible, tm.Explored.IsSeen(x, y), tm.Explored.IsVisible(x, y))
+			}
+		}
+	}
+
+	if !tm.Explored.IsSeen(6, 2) || tm.Explored.IsVisible(6, 2) {
+		t.Error("expected the far room to be remembered, but not visible")
+	}
+
+	data, err := tm.Explored.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := tilemap.NewExplored(1, 1)
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, tm.Explored) {
+		t.Error("expected the explored grid to load the same as it was saved")
+	}
+
+	// the adapters convert between the tiles and the explored grid
+	fromTiles := tilemap.ExploredFromTiles(tm)
+	if !reflect.DeepEqual(fromTiles, tm.Explored) {
+		t.Error("expected the explored grid made from the tiles to match")
+	}
+
+	fresh := twoRooms()
+	loaded.CopyToTiles(fresh)
+	if !reflect.DeepEqual(fresh.Tiles, tm.Tiles) {
+		t.Error("expected copying the explored grid to the tiles to mark them the same")
+	}
+}
@@ -0,0 +1,98 @@
+package tileset
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/matjam/sword/internal/terrain"
+	"github.com/matjam/sword/internal/tilemap"
+)
+
+// Ensure that we're implementing the tilemap.Renderer interface.
+var _ = tilemap.Renderer(&Renderer{})
+
+// Renderer draws a tilemap.Grid using a tileset. It implements the same
+// tilemap.Renderer interface as the text renderer, so the game can switch
+// between sprites and ASCII without caring which one it has.
+type Renderer struct {
+	tilemap *tilemap.Grid
+	tileset *Tileset
+	scale   int
+}
+
+// NewRenderer creates a renderer that draws the tilemap with the tileset,
+// with every tile scaled up by scale.
+func NewRenderer(tm *tilemap.Grid, ts *Tileset, scale int) tilemap.Renderer {
+	return &Renderer{
+		tilemap: tm,
+		tileset: ts,
+		scale:   max(scale, 1),
+	}
+}
+
+// Draw the tilemap to the given destination image. The viewport is the
+// rectangle of the tilemap to render, and x and y are where the top left
+// corner of the viewport is drawn.
+func (r *Renderer) Draw(dst *ebiten.Image, x int, y int, viewport tilemap.Rectangle) {
+	// render() wants the position of tile 0,0 rather than of the viewport
+	cellWidth, cellHeight := r.CellSize()
+	x -= viewport.X * cellWidth
+	y -= viewport.Y * cellHeight
+
+	bounds := image.Rect(viewport.X, viewport.Y, viewport.X+viewport.Width, viewport.Y+viewport.Height)
+	r.tileset.render(gridSource{r.tilemap}, dst, x, y, bounds, float64(r.scale))
+}
+
+// CellSize returns the size in pixels of a single tile when drawn.
+func (r *Renderer) CellSize() (width, height int) {
+	return r.tileset.tileWidth * r.scale, r.tileset.tileHeight * r.scale
+}
+
+// gridSource draws a tilemap.Grid, by picking the terrain type whose sprite
+// matches each type of tile.
+type gridSource struct {
+	*tilemap.Grid
+}
+
+func (s gridSource) Size() (width, height int) {
+	return s.Width, s.Height
+}
+
+func (s gridSource) Get(x, y int) terrain.Type {
+	tile := s.GetTile(x, y)
+	if tile == nil {
+		return terrain.Stone
+	}
+
+	switch tile.Type {
+	case tilemap.TileTypeWall:
+		return terrain.Stone
+	case tilemap.TileTypeClosedDoor, tilemap.TileTypeOpenDoor:
+		return terrain.Door
+	case tilemap.TileTypeTrap:
+		// unrevealed traps look just like the floor around them
+		if tile.Revealed {
+			return terrain.Trap
+		}
+		return terrain.Room
+	}
+
+	// the tileset doesn't have sprites for stairs yet, so they're drawn as
+	// floor for now.
+	return terrain.Room
+}
+
+func (s gridSource) brightness(x, y int) float32 {
+	return s.Brightness(x, y)
+}
+
+// the tilemap doesn't know about themes yet, so it's all drawn with the
+// default one
+func (s gridSource) theme(x, y int) terrain.ThemeID {
+	return terrain.DefaultTheme
+}
+
+func (s gridSource) isRevealed(x, y int) bool {
+	tile := s.GetTile(x, y)
+	return tile != nil && tile.Revealed
+}
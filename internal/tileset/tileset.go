@@ -2,21 +2,53 @@ package tileset
 
 import (
 	"image"
+	"image/color"
 	"log/slog"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/matjam/sword/internal/grid"
 	"github.com/matjam/sword/internal/terrain"
 )
 
+// DefaultShadowIntensity is the ShadowIntensity used if it isn't set.
+const DefaultShadowIntensity = 0.4
+
 // Tileset represents a tileset atlas, for use with a tilemap and
 // an autotiler. It contains the autotiles and fixtures, all of which
 // are the same size and located on the same image.
 type Tileset struct {
+	// Shadows makes Render darken the top edge of every floor tile that has
+	// a wall to its north, as if the wall were casting a shadow, which makes
+	// the walls look like they stand up from the floor. See drawShadow().
+	Shadows bool
+
+	// ShadowIntensity is how dark the shadows are, from 0 for no shadow to 1
+	// for black. Zero means DefaultShadowIntensity.
+	ShadowIntensity float32
+
+	// MergeFloors draws corridors, and the traps in them, with the same
+	// floor as rooms, for games that don't want the two to look different.
+	// Only the drawing changes; the terrain still tells rooms and corridors
+	// apart for everything else, such as the room graph and placing mobs.
+	MergeFloors bool
+
+	// RockFixtures are the names of the fixtures that solid rock is drawn
+	// with: the stone that isn't a wall, because there's nothing open next
+	// to it. If it is empty, which is the default, solid rock isn't drawn at
+	// all and whatever is behind the map shows through. With more than one
+	// fixture, each tile picks one by hashing its position, so that a big
+	// area of rock isn't one flat pattern but still looks the same every
+	// time it is drawn. Names that aren't fixtures in the tileset are
+	// skipped.
+	RockFixtures []string
+
 	name string
 	// The image containing the tileset atlas
 	atlas *ebiten.Image
 	// The size of each tile in the atlas
-	tileSize int
+	tileWidth  int
+	tileHeight int
 	// The number of columns in the atlas
 	columns int
 	// The number of rows in the atlas
@@ -25,11 +57,40 @@ type Tileset struct {
 	autotiles []*ebiten.Image
 	// The fixtures in the atlas
 	fixtures map[string]*ebiten.Image
+	// A single white pixel, which is stretched over the tiles to draw shadows
+	pixel *ebiten.Image
+	// The themes added with AddTheme()
+	themes map[terrain.ThemeID]*theme
+}
+
+// Theme is another look for the tiles in part of a map, drawn from the same
+// atlas as the rest of the tileset. Anything a theme leaves out is drawn the
+// way the tileset normally draws it. See RenderThemed.
+type Theme struct {
+	// Autotiles are the positions in the atlas of the 16 wall autotiles, in
+	// the same order as the ones passed to Load. If it is empty, the walls
+	// use the tileset's own autotiles.
+	Autotiles [][2]int
+
+	// Fixtures maps the name of a fixture to the name of the fixture that is
+	// drawn in its place, such as "floor_dots" to "floor_bones" for a crypt.
+	// Both must be fixtures of the tileset.
+	Fixtures map[string]string
+
+	// RockFixtures, if it isn't empty, replaces the tileset's RockFixtures.
+	RockFixtures []string
+}
+
+// theme is a Theme with the sprites looked up in the atlas.
+type theme struct {
+	autotiles    []*ebiten.Image
+	fixtures     map[string]*ebiten.Image
+	rockFixtures []string
 }
 
 func Load(name string,
 	atlas *ebiten.Image,
-	tileSize int,
+	tileWidth int, tileHeight int,
 	columns int, rows int,
 	autotiles [][2]int,
 	fixtures map[string][2]int) *Tileset {
@@ -39,33 +100,27 @@ func Load(name string,
 	}
 
 	ts := &Tileset{
-		name:      name,
-		atlas:     atlas,
-		tileSize:  tileSize,
-		columns:   columns,
-		rows:      rows,
-		autotiles: make([]*ebiten.Image, len(autotiles)),
-		fixtures:  make(map[string]*ebiten.Image),
+		name:       name,
+		atlas:      atlas,
+		tileWidth:  tileWidth,
+		tileHeight: tileHeight,
+		columns:    columns,
+		rows:       rows,
+		autotiles:  make([]*ebiten.Image, len(autotiles)),
+		fixtures:   make(map[string]*ebiten.Image),
+		pixel:      ebiten.NewImage(1, 1),
 	}
 
+	ts.pixel.Fill(color.White)
+
 	// create the autotiles
 	for i, coords := range autotiles {
-		x := coords[0] * tileSize
-		y := coords[1] * tileSize
-		ts.autotiles[i] = ts.atlas.SubImage(image.Rectangle{
-			Min: image.Point{X: x, Y: y},
-			Max: image.Point{X: x + tileSize, Y: y + tileSize},
-		}).(*ebiten.Image)
+		ts.autotiles[i] = ts.tile(coords)
 	}
 
 	// create the fixtures
 	for name, coords := range fixtures {
-		x := coords[0] * tileSize
-		y := coords[1] * tileSize
-		ts.fixtures[name] = ts.atlas.SubImage(image.Rectangle{
-			Min: image.Point{X: x, Y: y},
-			Max: image.Point{X: x + tileSize, Y: y + tileSize},
-		}).(*ebiten.Image)
+		ts.fixtures[name] = ts.tile(coords)
 	}
 
 	slog.Info("loaded tileset", "name", ts.name, "autotiles", len(ts.autotiles), "fixtures", len(ts.fixtures))
@@ -73,17 +128,170 @@ func Load(name string,
 	return ts
 }
 
-func (ts *Tileset) Render(src *terrain.Terrain, dst *ebiten.Image, x int, y int, viewport image.Rectangle, scale int) {
-	for y := 0; y < src.Height; y++ {
-		for x := 0; x < src.Width; x++ {
-			// don't render tiles that are outside the viewport
-			if x < viewport.Min.X || x >= viewport.Max.X || y < viewport.Min.Y || y >= viewport.Max.Y {
-				continue
-			}
+// tile returns the tile at the given column and row of the atlas.
+func (ts *Tileset) tile(coords [2]int) *ebiten.Image {
+	x := coords[0] * ts.tileWidth
+	y := coords[1] * ts.tileHeight
+	return ts.atlas.SubImage(image.Rectangle{
+		Min: image.Point{X: x, Y: y},
+		Max: image.Point{X: x + ts.tileWidth, Y: y + ts.tileHeight},
+	}).(*ebiten.Image)
+}
+
+// AddTheme adds a theme to the tileset, which RenderThemed draws the tiles
+// with the given ID with. Adding a theme with the ID of one that has already
+// been added replaces it, and a theme with terrain.DefaultTheme's ID changes
+// how every tile without a theme of its own is drawn.
+func (ts *Tileset) AddTheme(id terrain.ThemeID, t Theme) {
+	if len(t.Autotiles) != 0 && len(t.Autotiles) != 16 {
+		slog.Error("theme autotiles must contain 16 entries", "name", ts.name, "theme", id, "autotiles", len(t.Autotiles))
+		t.Autotiles = nil
+	}
+
+	th := &theme{
+		fixtures:     make(map[string]*ebiten.Image),
+		rockFixtures: t.RockFixtures,
+	}
+
+	for _, coords := range t.Autotiles {
+		th.autotiles = append(th.autotiles, ts.tile(coords))
+	}
+
+	for name, replacement := range t.Fixtures {
+		fixture, ok := ts.fixtures[replacement]
+		if !ok {
+			slog.Error("theme uses a fixture that isn't in the tileset", "name", ts.name, "theme", id, "fixture", replacement)
+			continue
+		}
+		th.fixtures[name] = fixture
+	}
+
+	if ts.themes == nil {
+		ts.themes = make(map[terrain.ThemeID]*theme)
+	}
+	ts.themes[id] = th
+}
+
+// TileSize returns the width and height of a single tile, in pixels, before
+// any scaling.
+func (ts *Tileset) TileSize() (width, height int) {
+	return ts.tileWidth, ts.tileHeight
+}
+
+// source is anything the tileset can draw from: a terrain type for every
+// tile, whether the trap on a tile, if there is one, has been revealed, how
+// brightly lit each tile is, and which theme it is drawn with.
+type source interface {
+	terrain.Source
+	isRevealed(x, y int) bool
+	brightness(x, y int) float32
+	theme(x, y int) terrain.ThemeID
+}
+
+// terrainSource draws a terrain directly, with the revealed traps and the
+// themes in separate grids.
+type terrainSource struct {
+	*terrain.Terrain
+	revealed *grid.Grid[bool]
+	themes   *grid.Grid[terrain.ThemeID]
+}
+
+func (s terrainSource) isRevealed(x, y int) bool {
+	return s.revealed != nil && s.revealed.Get(x, y)
+}
+
+// Get returns the terrain type at the given position, with secret doors
+// passed off as stone, so that they look just like the wall around them.
+func (s terrainSource) Get(x, y int) terrain.Type {
+	t := s.Terrain.Get(x, y)
+	if t == terrain.SecretDoor {
+		return terrain.Stone
+	}
+	return t
+}
+
+// terrain has no lighting, so it's always fully lit
+func (s terrainSource) brightness(x, y int) float32 {
+	return 1
+}
+
+func (s terrainSource) theme(x, y int) terrain.ThemeID {
+	if s.themes == nil {
+		return terrain.DefaultTheme
+	}
+	return s.themes.Get(x, y)
+}
+
+// Render draws the tiles of src that fall inside viewport, which is in tile
+// coordinates. x and y are the screen position of the top left corner of tile
+// 0,0, after scaling, so a camera scrolled right by 10 pixels passes -10. Only
+// the tiles inside the viewport are visited, so the cost depends on the size
+// of the viewport rather than the size of the terrain. Stone that can't be
+// seen from anywhere open is left undrawn, unless RockFixtures is set.
+//
+// Traps are drawn as ordinary corridor unless they are marked in revealed,
+// in which case they are tinted red. revealed may be nil if no traps have
+// been revealed.
+//
+// scale can be fractional, for smooth zooming. Whole number scales are drawn
+// pixel perfect, with every pixel of the tileset becoming a square of screen
+// pixels. Fractional scales are filtered, so they look smooth rather than
+// blocky, and every tile is stretched to meet its neighbours so that there
+// are no gaps between them. A scale of zero or less is treated as 1.
+//
+// To draw a tilemap.Grid instead, use a Renderer.
+func (ts *Tileset) Render(src *terrain.Terrain, revealed *grid.Grid[bool], dst *ebiten.Image, x int, y int, viewport image.Rectangle, scale float64) {
+	ts.render(terrainSource{src, revealed, nil}, dst, x, y, viewport, scale)
+}
+
+// RenderThemed works like Render, but draws each tile with the theme that
+// themes gives it, such as the grid made by mapgen's AssignThemes. Tiles
+// whose theme hasn't been added with AddTheme are drawn as Render would draw
+// them. themes should be the same size as src.
+func (ts *Tileset) RenderThemed(src *terrain.Terrain, themes *grid.Grid[terrain.ThemeID], revealed *grid.Grid[bool], dst *ebiten.Image, x int, y int, viewport image.Rectangle, scale float64) {
+	ts.render(terrainSource{src, revealed, themes}, dst, x, y, viewport, scale)
+}
+
+func (ts *Tileset) render(src source, dst *ebiten.Image, x int, y int, viewport image.Rectangle, scale float64) {
+	width, height := src.Size()
+
+	if scale <= 0 {
+		scale = 1
+	}
+
+	filter := ebiten.FilterNearest
+	if scale != math.Trunc(scale) {
+		filter = ebiten.FilterLinear
+	}
+
+	// the size of a tile on the screen. This is what everything below uses,
+	// for both placing the tiles and working out which of them are on the
+	// screen, so that the two always agree.
+	cellWidth := float64(ts.tileWidth) * scale
+	cellHeight := float64(ts.tileHeight) * scale
+	offsetX, offsetY := float64(x), float64(y)
 
+	// clamp the viewport to the terrain, and to the tiles that actually land
+	// on dst, with a tile to spare for rounding. The wall checks below look
+	// at the neighbouring tiles, which may be outside the viewport, but they
+	// check against the bounds of the terrain so that's fine.
+	bounds := dst.Bounds()
+	minX := max(0, viewport.Min.X, int(math.Floor((float64(bounds.Min.X)-offsetX)/cellWidth))-1)
+	minY := max(0, viewport.Min.Y, int(math.Floor((float64(bounds.Min.Y)-offsetY)/cellHeight))-1)
+	maxX := min(width, viewport.Max.X, int(math.Ceil((float64(bounds.Max.X)-offsetX)/cellWidth))+1)
+	maxY := min(height, viewport.Max.Y, int(math.Ceil((float64(bounds.Max.Y)-offsetY)/cellHeight))+1)
+
+	for y := minY; y < maxY; y++ {
+		for x := minX; x < maxX; x++ {
 			tile := src.Get(x, y)
-			if tile == terrain.Stone && !ts.isReachable(src, x, y) {
-				continue
+			th := ts.themes[src.theme(x, y)]
+
+			// solid rock is only drawn if there's a fixture for it
+			var rock *ebiten.Image
+			if tile == terrain.Stone && !terrain.IsWall(src, x, y) {
+				if rock = ts.rock(x, y, th); rock == nil {
+					continue
+				}
 			}
 
 			// Given the specific tile tyle (e.g. Stone, Room, Corridor, Door), render
@@ -92,8 +300,9 @@ func (ts *Tileset) Render(src *terrain.Terrain, dst *ebiten.Image, x int, y int,
 			// We use a bitmask that represents the surrounding tiles, and use that to
 			// determine which tile to render.
 			//
-			// the bitmask is a 4 bit number, where each bit represents a tile in one of
-			// the cardinal directions. The bits are ordered like this:
+			// the bitmask is the cardinal part of terrain.WallMask8(), a 4 bit number
+			// where each bit represents a tile in one of the cardinal directions. The
+			// bits are ordered like this:
 			//
 			//  1
 			// 8 2
@@ -104,8 +313,8 @@ func (ts *Tileset) Render(src *terrain.Terrain, dst *ebiten.Image, x int, y int,
 			// that is "stone", a door is considered also a solid tile so the bitmask in
 			// that case would be 1 for that tile.
 			//
-			// The bitmask is calculated by iterating over the surrounding tiles, and
-			// setting the bit in the bitmask if the tile is solid.
+			// A bit is set if the tile in that direction is a wall: stone that
+			// borders something open. See terrain.IsWall().
 			//
 			// For example, if the tile is surrounded by solid tiles in the north and
 			// west, the bitmask would be 9 (1001).
@@ -127,82 +336,199 @@ func (ts *Tileset) Render(src *terrain.Terrain, dst *ebiten.Image, x int, y int,
 			// calculate the bitmask
 			var bitmask uint8 = 0
 			if tile == terrain.Stone {
-				// check north
-				if y > 0 && src.Get(x, y-1) == terrain.Stone && ts.isReachable(src, x, y-1) {
-					bitmask |= 1
-				}
-				// check east
-				if x < src.Width-1 && src.Get(x+1, y) == terrain.Stone && ts.isReachable(src, x+1, y) {
-					bitmask |= 2
-				}
-				// check south
-				if y < src.Height-1 && src.Get(x, y+1) == terrain.Stone && ts.isReachable(src, x, y+1) {
-					bitmask |= 4
-				}
-				// check west
-				if x > 0 && src.Get(x-1, y) == terrain.Stone && ts.isReachable(src, x-1, y) {
-					bitmask |= 8
-				}
+				bitmask = terrain.WallMask8(src, x, y) & terrain.CardinalMask
 			}
 
-			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Translate(float64(x*ts.tileSize), float64(y*ts.tileSize))
-			if scale != 1 {
-				op.GeoM.Scale(float64(scale), float64(scale))
+			// work out the edges of the tile from its position, rather than
+			// adding up the sizes of the tiles before it, and round them to
+			// whole pixels. At fractional scales this stretches some tiles a
+			// pixel wider than others, but every tile meets its neighbours.
+			left := math.Floor(float64(x) * cellWidth)
+			top := math.Floor(float64(y) * cellHeight)
+			right := math.Floor(float64(x+1) * cellWidth)
+			bottom := math.Floor(float64(y+1) * cellHeight)
+
+			op := &ebiten.DrawImageOptions{Filter: filter}
+			op.GeoM.Scale((right-left)/float64(ts.tileWidth), (bottom-top)/float64(ts.tileHeight))
+			op.GeoM.Translate(left+offsetX, top+offsetY)
+
+			if b := src.brightness(x, y); b < 1 {
+				op.ColorScale.Scale(b, b, b, 1)
+			}
+
+			if rock != nil {
+				dst.DrawImage(rock, op)
+				continue
 			}
 
-			switch tile {
-			case terrain.Stone:
-				dst.DrawImage(ts.autotiles[bitmask], op)
-			case terrain.Door:
-				dst.DrawImage(ts.fixtures["door_unlocked"], op)
-			case terrain.Room:
-				dst.DrawImage(ts.fixtures["floor_dots"], op)
-			case terrain.Corridor:
-				dst.DrawImage(ts.fixtures["floor_checker_1"], op)
+			ts.drawTile(dst, tile, bitmask, op, src.isRevealed(x, y), th)
+
+			if ts.Shadows && casts(tile) && terrain.WallMask8(src, x, y)&terrain.MaskNorth != 0 {
+				ts.drawShadow(dst, left+offsetX, top+offsetY, right-left, bottom-top)
 			}
 		}
 	}
 }
 
-func (ts *Tileset) isReachable(src *terrain.Terrain, x, y int) bool {
-	// scan every tile in all 8 directions around the given tile, and if any of them
-	// are not a stone tile, then the tile is reachable.
+// rock returns the fixture to draw the solid rock at the given tile with, or
+// nil if there isn't one. th is the tile's theme, which may be nil.
+func (ts *Tileset) rock(x, y int, th *theme) *ebiten.Image {
+	names := ts.RockFixtures
+	if th != nil && len(th.rockFixtures) != 0 {
+		names = th.rockFixtures
+	}
 
-	// check north
-	if y > 0 && src.Get(x, y-1) != terrain.Stone {
-		return true
+	// names that aren't fixtures are skipped, so the tile picks one of the
+	// ones that are
+	known := 0
+	for _, name := range names {
+		if ts.fixture(name, th) != nil {
+			known++
+		}
 	}
-	// check north east
-	if y > 0 && x < src.Width-1 && src.Get(x+1, y-1) != terrain.Stone {
-		return true
+	if known == 0 {
+		return nil
 	}
-	// check east
-	if x < src.Width-1 && src.Get(x+1, y) != terrain.Stone {
-		return true
+
+	pick := positionHash(x, y) % uint32(known)
+	for _, name := range names {
+		fixture := ts.fixture(name, th)
+		if fixture == nil {
+			continue
+		}
+		if pick == 0 {
+			return fixture
+		}
+		pick--
 	}
-	// check south east
-	if y < src.Height-1 && x < src.Width-1 && src.Get(x+1, y+1) != terrain.Stone {
-		return true
+	return nil
+}
+
+// positionHash mixes the coordinates of a tile into a number that looks
+// random, but is always the same for the same tile.
+func positionHash(x, y int) uint32 {
+	h := uint32(x)*0x8da6b343 ^ uint32(y)*0xd8163841
+	h ^= h >> 16
+	h *= 0x7feb352d
+	h ^= h >> 15
+	h *= 0x846ca68b
+	h ^= h >> 16
+	return h
+}
+
+// casts returns true if a shadow is drawn on a tile of the given type when
+// there's a wall to its north. Doors sit in the walls themselves, so they
+// don't get one.
+func casts(t terrain.Type) bool {
+	return t != terrain.Stone && t != terrain.Door
+}
+
+// drawShadow darkens the top edge of the tile at x, y, which is w by h pixels
+// on the screen. The shadow is two bands, the top quarter of the tile and a
+// lighter quarter below it, so that it fades out rather than stopping in a
+// hard line. The bands don't overlap, so no part of the tile is darkened
+// twice, and each tile only ever gets one shadow, from the wall straight
+// above it, so the corners of a room are no darker than its walls.
+//
+// The shadow is blended over the tile, so it darkens the tile by the same
+// fraction however brightly the tile is lit.
+func (ts *Tileset) drawShadow(dst *ebiten.Image, x, y, w, h float64) {
+	intensity := ts.ShadowIntensity
+	if intensity == 0 {
+		intensity = DefaultShadowIntensity
+	}
+
+	band := max(1, math.Floor(h/4))
+	for i, alpha := range []float32{intensity, intensity / 2} {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(w, band)
+		op.GeoM.Translate(x, y+float64(i)*band)
+
+		// the pixel is white, so scaling it all down gives black at the
+		// given alpha
+		op.ColorScale.Scale(0, 0, 0, alpha)
+		dst.DrawImage(ts.pixel, op)
+	}
+}
+
+// RenderTile draws a single tile of the given terrain type with its top left
+// corner at px, py, scaled up by scale, for things like a palette in an
+// editor or a cursor highlight. bitmask picks the wall autotile for Stone, in
+// the same format as Render uses; see terrain.WallMask8 and
+// terrain.CardinalMask. It is ignored for the other types. Traps are drawn as
+// revealed, since otherwise they'd look just like corridor.
+//
+// A scale of zero or less is treated as 1, and fractional scales are
+// filtered, the same as Render.
+func (ts *Tileset) RenderTile(dst *ebiten.Image, t terrain.Type, bitmask uint8, px, py int, scale float64) {
+	if scale <= 0 {
+		scale = 1
 	}
-	// check south
-	if y < src.Height-1 && src.Get(x, y+1) != terrain.Stone {
-		return true
+
+	filter := ebiten.FilterNearest
+	if scale != math.Trunc(scale) {
+		filter = ebiten.FilterLinear
 	}
-	// check south west
-	if y < src.Height-1 && x > 0 && src.Get(x-1, y+1) != terrain.Stone {
-		return true
+
+	op := &ebiten.DrawImageOptions{Filter: filter}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(float64(px), float64(py))
+
+	ts.drawTile(dst, t, bitmask, op, true, nil)
+}
+
+// drawTile draws the sprites for a tile with the given options, tinting
+// revealed traps red. th is the tile's theme, which may be nil.
+func (ts *Tileset) drawTile(dst *ebiten.Image, t terrain.Type, bitmask uint8, op *ebiten.DrawImageOptions, revealed bool, th *theme) {
+	if t == terrain.Trap && revealed {
+		op.ColorScale.Scale(1, 0.25, 0.25, 1)
 	}
-	// check west
-	if x > 0 && src.Get(x-1, y) != terrain.Stone {
-		return true
+
+	for _, sprite := range ts.sprites(t, bitmask, th) {
+		dst.DrawImage(sprite, op)
 	}
-	// check north west
-	if y > 0 && x > 0 && src.Get(x-1, y-1) != terrain.Stone {
-		return true
+}
+
+// sprites returns the sprites that are drawn, in order, for a tile of the
+// given terrain type. Stone, and secret doors which look just like it, pick
+// the autotile for the bitmask; everything else is drawn with fixtures. The
+// theme, if it isn't nil, can swap any of them for another.
+func (ts *Tileset) sprites(t terrain.Type, bitmask uint8, th *theme) []*ebiten.Image {
+	switch t {
+	case terrain.Stone, terrain.SecretDoor:
+		autotiles := ts.autotiles
+		if th != nil && th.autotiles != nil {
+			autotiles = th.autotiles
+		}
+		return []*ebiten.Image{autotiles[bitmask&terrain.CardinalMask]}
+	case terrain.Door:
+		return []*ebiten.Image{ts.fixture("door_unlocked", th)}
+	case terrain.Room:
+		return []*ebiten.Image{ts.fixture("floor_dots", th)}
+	case terrain.Corridor, terrain.Trap:
+		if ts.MergeFloors {
+			return []*ebiten.Image{ts.fixture("floor_dots", th)}
+		}
+		return []*ebiten.Image{ts.fixture("floor_checker_1", th)}
+	case terrain.Rubble:
+		// the rubble sprite is transparent, so it is drawn over the floor
+		return []*ebiten.Image{ts.fixture("floor_dots", th), ts.fixture("rubble", th)}
+	case terrain.Water:
+		return []*ebiten.Image{ts.fixture("water", th)}
 	}
 
-	return false
+	return nil
+}
+
+// fixture returns the fixture with the given name, or the one the theme
+// draws in its place. th may be nil.
+func (ts *Tileset) fixture(name string, th *theme) *ebiten.Image {
+	if th != nil {
+		if fixture, ok := th.fixtures[name]; ok {
+			return fixture
+		}
+	}
+	return ts.fixtures[name]
 }
 
 // all the bits in the bitmask from 0-15